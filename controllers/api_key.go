@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+const defaultAPIKeyValidDays = 90
+
+// CreateAPIKey - POST /api/api-keys
+// Issues an API key limited to the given permission scopes. The key acts on
+// behalf of the issuer and is only returned in this response.
+func (h *HandlerFunc) CreateAPIKey(c *gin.Context) {
+	// 1️ Current user
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	// 2️ Bind input JSON
+	var input models.CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if input.ExpiresInDays == 0 {
+		input.ExpiresInDays = defaultAPIKeyValidDays
+	}
+
+	// 3️ Scopes must be permissions the issuer holds
+	perms, err := h.RequestPermissions(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to load permissions: "+err.Error())
+		return
+	}
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !perms[scope] {
+			utils.RespondWithError(c, http.StatusBadRequest, "unknown scope or scope you do not hold: "+scope)
+			return
+		}
+		scopes = append(scopes, scope)
+	}
+
+	// 4️ Generate and store
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate API key")
+		return
+	}
+
+	var keyID uuid.UUID
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		keyID, err = h.Query.CreateAPIKey(tx, strings.TrimSpace(input.Name), prefix, hash, scopes, empID, input.ExpiresInDays)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to create API key: "+err.Error())
+		}
+
+		data := utils.NewCommon(constant.ComponentAPIKey, constant.ActionCreate, empID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// 5️ Response
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         "API key created, copy it now as it will not be shown again",
+		"id":              keyID,
+		"api_key":         key,
+		"prefix":          prefix,
+		"scopes":          scopes,
+		"expires_in_days": input.ExpiresInDays,
+	})
+}
+
+// GetAPIKeys - GET /api/api-keys
+func (h *HandlerFunc) GetAPIKeys(c *gin.Context) {
+	keys, err := h.Query.GetAllAPIKeys()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch API keys: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "API keys fetched",
+		"api_keys": keys,
+	})
+}
+
+// RevokeAPIKey - DELETE /api/api-keys/:id
+func (h *HandlerFunc) RevokeAPIKey(c *gin.Context) {
+	// 1️ Current user
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	// 2️ Parse key ID
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid API key ID")
+		return
+	}
+
+	// 3️ Revoke + log
+	revoked := false
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		revoked, err = h.Query.RevokeAPIKey(tx, keyID)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to revoke API key: "+err.Error())
+		}
+		if !revoked {
+			return nil
+		}
+
+		data := utils.NewCommon(constant.ComponentAPIKey, constant.ActionDelete, empID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !revoked {
+		utils.RespondWithError(c, http.StatusNotFound, "API key not found or already revoked")
+		return
+	}
+
+	// 4️ Response
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+		"id":      keyID,
+	})
+}
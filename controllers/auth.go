@@ -1,16 +1,28 @@
 package controllers
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
 )
 
+// invalidCredentialsMessage is returned for both unknown emails and wrong
+// passwords so the login endpoint does not reveal which accounts exist
+const invalidCredentialsMessage = "Invalid email or password"
+
 type EmployeeAuthData struct {
 	ID       string `db:"id"`
 	Email    string `db:"email"`
@@ -32,7 +44,7 @@ func (s *HandlerFunc) Login(c *gin.Context) {
 
 		// If token exists and is valid, user is already logged in
 		if tokenString != "" {
-			claims, err := utils.ValidateToken(tokenString, s.Env.SERACT_KEY)
+			claims, err := s.ParseAccessToken(tokenString)
 			if err == nil {
 				// Token is valid, user already logged in
 				userID, parseErr := uuid.Parse(claims.UserID)
@@ -63,39 +75,81 @@ func (s *HandlerFunc) Login(c *gin.Context) {
 		return
 	}
 
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+	clientIP := c.ClientIP()
+
+	// 1.5 Reject while the account or client IP is locked out
+	remaining, err := s.loginLockRemaining(email, clientIP)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to check login attempts")
+		return
+	}
+	if remaining > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		utils.RespondWithError(c, http.StatusTooManyRequests, "Too many failed login attempts. Please try again later")
+		return
+	}
+
 	// 2. Fetch employee + role name
 	emp, err := s.Query.GetEmployeeByEmail(input.Email)
 	if err != nil {
-		utils.RespondWithError(c, http.StatusUnauthorized, fmt.Sprintf("Login failed — email not found: %v", err.Error()))
+		// Burn the same time as a real password check and give the same answer
+		utils.CheckPassword(input.Password, dummyPasswordHash)
+		s.registerFailedLogin(c, email, clientIP, nil)
+		utils.RespondWithError(c, http.StatusUnauthorized, invalidCredentialsMessage)
 		return
 	}
 
 	// 3. Validate password
 	if !utils.CheckPassword(input.Password, emp.Password) {
 		log.Printf("Login failed — wrong password for email: %s", input.Email)
-		utils.RespondWithError(c, http.StatusUnauthorized, "Login failed — wrong password for email: "+input.Email)
+		empID, _ := uuid.Parse(emp.ID)
+		s.registerFailedLogin(c, email, clientIP, &empID)
+		utils.RespondWithError(c, http.StatusUnauthorized, invalidCredentialsMessage)
 		return
 	}
 
+	// Successful password check resets the account counter
+	if err := s.Query.ClearLoginThrottle(repositories.ThrottleKeyEmail, email); err != nil {
+		log.Printf("Failed to reset login attempts for %s: %v", email, err)
+	}
+
 	// 3.5 Check employee status
 	if emp.Status == "deactive" {
 		utils.RespondWithError(c, http.StatusForbidden, "Your account is deactivated. You cannot login")
 		return
 	}
 
-	// 4. Generate JWT with role name
-	token, err := utils.GenerateToken(emp.ID, emp.Role, s.Env.SERACT_KEY)
+	// 4. MFA challenge or tokens
+	s.completeLogin(c, emp)
+}
+
+// completeLogin finishes a login whose first factor (password or SSO) has
+// been verified: it answers with an MFA challenge or with our tokens
+func (s *HandlerFunc) completeLogin(c *gin.Context, emp repositories.EmployeeAuthData) {
+	// 1. Second factor: hand out a short-lived challenge token instead of the real JWT
+	if challenge, ok := s.mfaChallenge(c, emp.ID, emp.Role); !ok {
+		return
+	} else if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	// 2. Generate access + refresh tokens with role name
+	tokens, err := s.issueTokens(c, emp.ID, emp.Role)
 	if err != nil {
-		log.Printf("JWT generation error: %v", err)
+		log.Printf("Token generation error: %v", err)
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate authentication token")
 		return
 	}
 
-	// 5. Success Response
+	// 3. Success Response
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Login successful",
-		"token":   token,
+		"success":       true,
+		"message":       "Login successful",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":    emp.ID,
 			"email": emp.Email,
@@ -127,7 +181,7 @@ func (s *HandlerFunc) VerifyToken(c *gin.Context) {
 	}
 
 	// Validate token
-	claims, err := utils.ValidateToken(tokenString, s.Env.SERACT_KEY)
+	claims, err := s.ParseAccessToken(tokenString)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid or expired token")
 		return
@@ -195,7 +249,7 @@ func (s *HandlerFunc) CheckAuthStatus(c *gin.Context) {
 	}
 
 	// Validate token
-	claims, err := utils.ValidateToken(tokenString, s.Env.SERACT_KEY)
+	claims, err := s.ParseAccessToken(tokenString)
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"authenticated": false,
@@ -244,19 +298,245 @@ func (s *HandlerFunc) CheckAuthStatus(c *gin.Context) {
 	})
 }
 
+// Logout - POST /api/auth/logout
+// Ends the current session. For tokens issued before sessions existed it
+// revokes the supplied refresh token, or every refresh token of the user.
 func (s *HandlerFunc) Logout(c *gin.Context) {
-	userIDRaw, _ := c.Get("user_id")
-	userRoleRaw, _ := c.Get("role")
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid user ID")
+		return
+	}
+
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	_ = c.ShouldBindJSON(&input) // body is optional
 
-	expiredToken, err := utils.GenerateExpiredToken(userIDRaw.(string), userRoleRaw.(string), s.Env.SERACT_KEY)
+	if sessionID, parseErr := uuid.Parse(c.GetString("session_id")); parseErr == nil {
+		_, err = s.Query.RevokeSession(userID, sessionID)
+	} else if input.RefreshToken != "" {
+		err = s.Query.RevokeRefreshToken(userID, utils.HashToken(input.RefreshToken))
+	} else {
+		err = s.Query.RevokeEmployeeRefreshTokens(userID)
+	}
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate expired token")
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to revoke refresh token: "+err.Error())
+		return
+	}
+
+	if err := s.Query.RevokeAccessToken(c.GetString("jti"), userID, c.GetTime("token_exp")); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to revoke token: "+err.Error())
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Logged out successfully",
-		"token":   expiredToken,
 	})
 }
+
+// RefreshToken - POST /api/auth/refresh
+// Exchanges a refresh token for a new access token and a rotated refresh token.
+// Presenting an already rotated token is treated as theft and kills every session.
+func (s *HandlerFunc) RefreshToken(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "refresh_token is required")
+		return
+	}
+
+	stored, err := s.Query.GetRefreshTokenByHash(utils.HashToken(input.RefreshToken))
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to verify refresh token: "+err.Error())
+		return
+	}
+
+	// Reuse of a rotated token: revoke everything for this employee
+	if stored.RevokedAt != nil {
+		if err := s.Query.RevokeAllEmployeeTokens(stored.EmployeeID); err != nil {
+			log.Printf("Failed to revoke tokens after refresh token reuse: %v", err)
+		}
+		utils.RespondWithError(c, http.StatusUnauthorized, "Refresh token has been revoked")
+		return
+	}
+	if stored.Expired {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Refresh token has expired")
+		return
+	}
+	if stored.SessionRevoked {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Session has been signed out")
+		return
+	}
+
+	emp, err := s.Query.GetEmployeeByID(stored.EmployeeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if emp.Status != nil && *emp.Status == "deactive" {
+		utils.RespondWithError(c, http.StatusForbidden, "Account is deactivated")
+		return
+	}
+
+	var tokens TokenPair
+	err = common.ExecuteTransaction(c, s.Query.DB, func(tx *sqlx.Tx) error {
+		// Tokens issued before sessions existed get a session on first refresh
+		var sessionID uuid.UUID
+		if stored.SessionID != nil {
+			sessionID = *stored.SessionID
+		} else if sessionID, err = s.createSession(c, tx, stored.EmployeeID); err != nil {
+			return err
+		}
+
+		pair, newID, err := s.createTokenPair(tx, stored.EmployeeID, sessionID, emp.Role)
+		if err != nil {
+			return err
+		}
+		if err := s.Query.RotateRefreshToken(tx, stored.ID, newID); err != nil {
+			return err
+		}
+		tokens = pair
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Failed to refresh token: "+err.Error())
+		return
+	}
+
+	if stored.SessionID != nil {
+		if err := s.Query.TouchSession(*stored.SessionID, c.ClientIP()); err != nil {
+			log.Printf("Failed to update session activity: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"message":       "Token refreshed",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// TokenPair is the access/refresh token pair handed to clients
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // access token lifetime in seconds
+}
+
+// issueTokens starts a new session for the requesting device and creates
+// its access token and persisted refresh token
+func (s *HandlerFunc) issueTokens(c *gin.Context, empIDStr, role string) (TokenPair, error) {
+	empID, err := uuid.Parse(empIDStr)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	var tokens TokenPair
+	err = common.ExecuteTransaction(c, s.Query.DB, func(tx *sqlx.Tx) error {
+		sessionID, err := s.createSession(c, tx, empID)
+		if err != nil {
+			return err
+		}
+		pair, _, err := s.createTokenPair(tx, empID, sessionID, role)
+		tokens = pair
+		return err
+	})
+	return tokens, err
+}
+
+// createSession records the device the user is signing in from
+func (s *HandlerFunc) createSession(c *gin.Context, tx *sqlx.Tx, empID uuid.UUID) (uuid.UUID, error) {
+	userAgent := c.Request.UserAgent()
+	return s.Query.CreateSession(tx, empID, utils.DeviceName(userAgent), c.ClientIP(), userAgent)
+}
+
+// createTokenPair signs an access token and stores a refresh token inside tx.
+// It returns the ID of the stored refresh token so callers can link rotations.
+func (s *HandlerFunc) createTokenPair(tx *sqlx.Tx, empID, sessionID uuid.UUID, role string) (TokenPair, uuid.UUID, error) {
+	accessToken, err := utils.GenerateToken(empID.String(), role, sessionID.String(), s.Keys, s.Env.ACCESS_TOKEN_TTL)
+	if err != nil {
+		return TokenPair{}, uuid.Nil, err
+	}
+
+	refreshToken, refreshHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return TokenPair{}, uuid.Nil, err
+	}
+
+	refreshID, err := s.Query.CreateRefreshToken(tx, empID, sessionID, refreshHash, s.Env.REFRESH_TOKEN_TTL)
+	if err != nil {
+		return TokenPair{}, uuid.Nil, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.Env.ACCESS_TOKEN_TTL.Seconds()),
+	}, refreshID, nil
+}
+
+// ParseAccessToken validates the JWT signature and expiry and rejects tokens
+// that were revoked by logout, password change or deactivation
+func (s *HandlerFunc) ParseAccessToken(tokenString string) (*utils.CustomClaims, error) {
+	return s.ParseToken(tokenString, "")
+}
+
+// ParseToken is ParseAccessToken for tokens restricted to one of the given
+// purposes ("" being a normal access token)
+func (s *HandlerFunc) ParseToken(tokenString string, purposes ...string) (*utils.CustomClaims, error) {
+	claims, err := utils.ValidateToken(tokenString, s.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(purposes, claims.Purpose) {
+		return nil, fmt.Errorf("token cannot be used here")
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	revoked, err := s.Query.IsAccessTokenRevoked(claims.ID, claims.SessionID, userID, claims.IssuedAt.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, fmt.Errorf("token has been revoked")
+	}
+
+	return claims, nil
+}
+
+// LoadPrincipal returns the employee's current role and status from the
+// database, served from a short-lived cache
+func (s *HandlerFunc) LoadPrincipal(empID uuid.UUID) (repositories.EmployeePrincipal, error) {
+	if p, ok := s.Principals.Get(empID); ok {
+		return p, nil
+	}
+
+	p, err := s.Query.GetEmployeePrincipal(empID)
+	if err != nil {
+		return p, err
+	}
+	s.Principals.Set(empID, p)
+	return p, nil
+}
+
+// JWKS - GET /.well-known/jwks.json
+// Publishes the public keys (RS256/EdDSA) other services use to verify our
+// tokens. HMAC keys are never listed.
+func (s *HandlerFunc) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, s.Keys.JWKS())
+}
@@ -1,7 +1,14 @@
 package controllers
 
 import (
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/cache"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/config"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/fieldcrypt"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/keyring"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/ratelimit"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/sso"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/storage"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
 )
 
@@ -9,12 +16,40 @@ import (
 type HandlerFunc struct {
 	Env   *config.ENV
 	Query *repositories.Repository
+
+	// Keys signs and verifies our JWTs
+	Keys *keyring.Keyring
+
+	// FieldCrypt encrypts sensitive profile fields at rest
+	FieldCrypt *fieldcrypt.Cipher
+
+	// Storage keeps uploaded employee documents, nil while none is configured
+	Storage storage.Storage
+
+	// Principals caches each employee's current role and status for AuthMiddleware
+	Principals *cache.TTLCache[uuid.UUID, repositories.EmployeePrincipal]
+
+	// Permissions caches the permission codes granted to each role
+	Permissions *cache.TTLCache[string, map[string]bool]
+
+	// RateLimiter holds the token buckets used by middleware.RateLimit
+	RateLimiter ratelimit.Store
+
+	// SSO is the OpenID Connect client for the company identity provider
+	SSO *sso.Client
 }
 
 // NewHandler initializes and returns a HandlerFunc
-func NewHandler(env *config.ENV, query *repositories.Repository) *HandlerFunc {
+func NewHandler(env *config.ENV, query *repositories.Repository, keys *keyring.Keyring, fieldCrypt *fieldcrypt.Cipher, store storage.Storage) *HandlerFunc {
 	return &HandlerFunc{
-		Env:   env,
-		Query: query,
+		Env:         env,
+		Query:       query,
+		Keys:        keys,
+		FieldCrypt:  fieldCrypt,
+		Storage:     store,
+		Principals:  cache.New[uuid.UUID, repositories.EmployeePrincipal](env.PRINCIPAL_CACHE_TTL),
+		Permissions: cache.New[string, map[string]bool](env.PRINCIPAL_CACHE_TTL),
+		RateLimiter: ratelimit.NewMemoryStore(),
+		SSO:         sso.New(env.OIDC_ISSUER, env.OIDC_CLIENT_ID, env.OIDC_CLIENT_SECRET, env.OIDC_REDIRECT_URL, env.OIDC_SCOPES),
 	}
 }
@@ -0,0 +1,451 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// departmentFilter reads the optional ?department_id= of the employee, leave
+// and payslip listings
+func departmentFilter(c *gin.Context) (*uuid.UUID, error) {
+	s := c.Query("department_id")
+	if s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, errors.New("invalid department ID")
+	}
+	return &id, nil
+}
+
+// optionalUUID parses an optional ID of the input, nil or empty is no ID
+func optionalUUID(s *string) *uuid.UUID {
+	if s == nil || *s == "" {
+		return nil
+	}
+	id := uuid.MustParse(*s) // validated by the input's uuid rule
+	return &id
+}
+
+// bindDepartment binds and validates the department input, checks the name is
+// not taken by another department and that the head is an active employee
+func (h *HandlerFunc) bindDepartment(c *gin.Context, excludeID *uuid.UUID) (input models.DepartmentInput, parentID, headID *uuid.UUID, ok bool) {
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	input.Description = strings.TrimSpace(input.Description)
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	parentID, headID = optionalUUID(input.ParentID), optionalUUID(input.HeadID)
+
+	exists, err := h.Query.DepartmentNameExists(input.Name, excludeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to check department name: "+err.Error())
+		return
+	}
+	if exists {
+		utils.RespondWithError(c, http.StatusConflict, "a department with this name already exists")
+		return
+	}
+
+	if headID != nil {
+		status, err := h.Query.GetEmployeeStatus(*headID)
+		if err == sql.ErrNoRows {
+			utils.RespondWithError(c, http.StatusNotFound, "department head not found")
+			return
+		} else if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to check department head: "+err.Error())
+			return
+		}
+		if status != "active" {
+			utils.RespondWithError(c, http.StatusBadRequest, "department head must be an active employee")
+			return
+		}
+	}
+	return input, parentID, headID, true
+}
+
+// checkDepartmentParent checks the parent exists and, when moving deptID,
+// that it is not deptID or one of its sub-departments
+func (h *HandlerFunc) checkDepartmentParent(c *gin.Context, tx *sqlx.Tx, deptID *uuid.UUID, parentID *uuid.UUID) error {
+	if parentID == nil {
+		return nil
+	}
+	exists, err := h.Query.DepartmentExists(tx, *parentID)
+	if err != nil {
+		return utils.CustomErr(c, http.StatusInternalServerError, "failed to check parent department: "+err.Error())
+	}
+	if !exists {
+		return utils.CustomErr(c, http.StatusNotFound, "parent department not found")
+	}
+	if deptID == nil {
+		return nil
+	}
+	below, err := h.Query.IsDepartmentBelow(tx, *deptID, *parentID)
+	if err != nil {
+		return utils.CustomErr(c, http.StatusInternalServerError, "failed to check parent department: "+err.Error())
+	}
+	if below {
+		return utils.CustomErr(c, http.StatusConflict, "a department cannot be placed under itself or one of its sub-departments")
+	}
+	return nil
+}
+
+// ListDepartments - GET /api/departments
+// All authenticated users can view departments
+func (h *HandlerFunc) ListDepartments(c *gin.Context) {
+	departments, err := h.Query.ListDepartments()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch departments: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "departments fetched successfully",
+		"total":       len(departments),
+		"departments": departments,
+	})
+}
+
+// GetDepartment - GET /api/departments/:id
+// The department with the departments directly below it
+func (h *HandlerFunc) GetDepartment(c *gin.Context) {
+	deptID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid department ID")
+		return
+	}
+
+	department, err := h.Query.GetDepartment(deptID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "department not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch department: "+err.Error())
+		return
+	}
+	subDepartments, err := h.Query.GetSubDepartments(deptID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch sub-departments: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "department fetched successfully",
+		"department":      department,
+		"sub_departments": subDepartments,
+	})
+}
+
+// CreateDepartment - POST /api/departments
+// Requires department.manage
+func (h *HandlerFunc) CreateDepartment(c *gin.Context) {
+	// 1️ Current user and input
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	input, parentID, headID, ok := h.bindDepartment(c, nil)
+	if !ok {
+		return
+	}
+
+	// 2️ Create under the parent
+	var deptID uuid.UUID
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.LockDepartmentTree(tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to lock departments: "+err.Error())
+		}
+		if err := h.checkDepartmentParent(c, tx, nil, parentID); err != nil {
+			return err
+		}
+		var err error
+		if deptID, err = h.Query.CreateDepartment(tx, input.Name, input.Description, parentID, headID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to create department: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentDepartment, constant.ActionCreate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "department created successfully",
+		"department_id": deptID,
+	})
+}
+
+// UpdateDepartment - PUT /api/departments/:id
+// Replaces name, description, parent and head. Requires department.manage
+func (h *HandlerFunc) UpdateDepartment(c *gin.Context) {
+	// 1️ Current user, department and input
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	deptID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid department ID")
+		return
+	}
+	input, parentID, headID, ok := h.bindDepartment(c, &deptID)
+	if !ok {
+		return
+	}
+
+	// 2️ Move and update, rejecting cycles
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.LockDepartmentTree(tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to lock departments: "+err.Error())
+		}
+		if err := h.checkDepartmentParent(c, tx, &deptID, parentID); err != nil {
+			return err
+		}
+		found, err := h.Query.UpdateDepartment(tx, deptID, input.Name, input.Description, parentID, headID)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update department: "+err.Error())
+		}
+		if !found {
+			return utils.CustomErr(c, http.StatusNotFound, "department not found")
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentDepartment, constant.ActionUpdate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "department updated successfully",
+		"department_id": deptID,
+	})
+}
+
+// DeleteDepartment - DELETE /api/departments/:id
+// Sub-departments move up to the deleted department's parent and its members
+// are left without a department. Requires department.manage
+func (h *HandlerFunc) DeleteDepartment(c *gin.Context) {
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	deptID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid department ID")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.LockDepartmentTree(tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to lock departments: "+err.Error())
+		}
+		found, err := h.Query.DeleteDepartment(tx, deptID)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to delete department: "+err.Error())
+		}
+		if !found {
+			return utils.CustomErr(c, http.StatusNotFound, "department not found")
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentDepartment, constant.ActionDelete, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "department deleted successfully",
+		"department_id": deptID,
+	})
+}
+
+// GetDepartmentMembers - GET /api/departments/:id/members
+// Members of the department and its sub-departments (?status=active|deactive).
+// Salaries are only shown with payroll.view_all. Requires department.manage or
+// employee.view_all
+func (h *HandlerFunc) GetDepartmentMembers(c *gin.Context) {
+	deptID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid department ID")
+		return
+	}
+	if _, err := h.Query.GetDepartment(deptID); err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "department not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch department: "+err.Error())
+		return
+	}
+
+	result, err := h.Query.ListEmployees(models.EmployeeListFilter{
+		DepartmentID: &deptID,
+		Status:       c.Query("status"),
+		Sort:         "name",
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch members: "+err.Error())
+		return
+	}
+	if !h.HasPermission(c, constant.PermPayrollViewAll) {
+		for i := range result.Employees {
+			result.Employees[i].Salary = nil
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "department members fetched successfully",
+		"total":   result.Total,
+		"members": result.Employees,
+	})
+}
+
+// GetDepartmentReport - GET /api/departments/report?year=2026&month=3
+// Headcount, joiners, leavers, leave and payroll per department for the year,
+// or one month of it. Payroll totals are only shown with payroll.view_all.
+// Requires department.manage
+func (h *HandlerFunc) GetDepartmentReport(c *gin.Context) {
+	// 1️ Period
+	year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(time.Now().Year())))
+	if err != nil || year < 2000 || year > 2100 {
+		utils.RespondWithError(c, http.StatusBadRequest, "year must be between 2000 and 2100")
+		return
+	}
+	var month *int
+	if s := c.Query("month"); s != "" {
+		m, err := strconv.Atoi(s)
+		if err != nil || m < 1 || m > 12 {
+			utils.RespondWithError(c, http.StatusBadRequest, "month must be between 1 and 12")
+			return
+		}
+		month = &m
+	}
+
+	// 2️ Figures per department
+	report, err := h.Query.GetDepartmentReport(year, month)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to build department report: "+err.Error())
+		return
+	}
+	if !h.HasPermission(c, constant.PermPayrollViewAll) {
+		for i := range report {
+			report[i].PayrollNet, report[i].PayrollDeductions = nil, nil
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "department report fetched successfully",
+		"year":        year,
+		"month":       month,
+		"departments": report,
+	})
+}
+
+// UpdateEmployeeDepartment - PATCH /api/employee/:id/department
+// Moves the employee to a department, null removes them from theirs.
+// Requires department.manage
+func (h *HandlerFunc) UpdateEmployeeDepartment(c *gin.Context) {
+	// 1️ Current user and employee
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	empID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+	targetEmp, err := h.Query.GetEmployeeByID(empID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// 2️ Only employee.manage_superadmin can modify SUPERADMIN
+	if targetEmp.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to modify SUPERADMIN users")
+		return
+	}
+
+	// 3️ Bind input JSON
+	var input struct {
+		DepartmentID *string `json:"department_id" validate:"omitempty,uuid"` // null removes the department
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	departmentID := optionalUUID(input.DepartmentID)
+
+	// 4️ Update membership
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if departmentID != nil {
+			exists, err := h.Query.DepartmentExists(tx, *departmentID)
+			if err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError, "failed to check department: "+err.Error())
+			}
+			if !exists {
+				return utils.CustomErr(c, http.StatusNotFound, "department not found")
+			}
+		}
+		if err := h.Query.UpdateEmployeeDepartment(tx, empID, departmentID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update department: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentDepartment, constant.ActionUpdate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	// 5️ Response
+	message := "employee department updated successfully"
+	if departmentID == nil {
+		message = "employee removed from department successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":       message,
+		"employee_id":   empID,
+		"department_id": departmentID,
+	})
+}
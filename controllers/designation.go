@@ -14,30 +14,23 @@ type DesignationInput struct {
 }
 
 // CreateDesignation - POST /api/designations
-// Only ADMIN, SUPERADMIN, and HR can create designations
+// Requires designation.manage
 func (h *HandlerFunc) CreateDesignation(c *gin.Context) {
-	// 1️ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can create designations")
-		return
-	}
-
-	// 2️ Bind input JSON
+	// 1️ Bind input JSON
 	var input DesignationInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
 		return
 	}
 
-	// 3️ Create designation
+	// 2️ Create designation
 	designationID, err := h.Query.CreateDesignation(input.DesignationName, input.Description)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to create designation: "+err.Error())
 		return
 	}
 
-	// 4️ Response
+	// 3️ Response
 	c.JSON(http.StatusCreated, gin.H{
 		"message":        "designation created successfully",
 		"designation_id": designationID,
@@ -87,16 +80,9 @@ func (h *HandlerFunc) GetDesignationByID(c *gin.Context) {
 }
 
 // UpdateDesignation - PATCH /api/designations/:id
-// Only ADMIN, SUPERADMIN, and HR can update designations
+// Requires designation.manage
 func (h *HandlerFunc) UpdateDesignation(c *gin.Context) {
-	// 1️ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can update designations")
-		return
-	}
-
-	// 2️ Parse designation ID
+	// 1️ Parse designation ID
 	designationIDStr := c.Param("id")
 	designationID, err := uuid.Parse(designationIDStr)
 	if err != nil {
@@ -104,21 +90,21 @@ func (h *HandlerFunc) UpdateDesignation(c *gin.Context) {
 		return
 	}
 
-	// 3️ Bind input JSON
+	// 2️ Bind input JSON
 	var input DesignationInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
 		return
 	}
 
-	// 4️ Update designation
+	// 3️ Update designation
 	err = h.Query.UpdateDesignation(designationID, input.DesignationName, input.Description)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to update designation: "+err.Error())
 		return
 	}
 
-	// 5️ Response
+	// 4️ Response
 	c.JSON(http.StatusOK, gin.H{
 		"message":        "designation updated successfully",
 		"designation_id": designationID,
@@ -126,16 +112,9 @@ func (h *HandlerFunc) UpdateDesignation(c *gin.Context) {
 }
 
 // DeleteDesignation - DELETE /api/designations/:id
-// Only ADMIN, SUPERADMIN, and HR can delete designations
+// Requires designation.manage
 func (h *HandlerFunc) DeleteDesignation(c *gin.Context) {
-	// 1️ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can delete designations")
-		return
-	}
-
-	// 2️ Parse designation ID
+	// 1️ Parse designation ID
 	designationIDStr := c.Param("id")
 	designationID, err := uuid.Parse(designationIDStr)
 	if err != nil {
@@ -143,14 +122,14 @@ func (h *HandlerFunc) DeleteDesignation(c *gin.Context) {
 		return
 	}
 
-	// 3️ Delete designation (will set employee designation_id to NULL due to ON DELETE SET NULL)
+	// 2️ Delete designation (will set employee designation_id to NULL due to ON DELETE SET NULL)
 	err = h.Query.DeleteDesignation(designationID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete designation: "+err.Error())
 		return
 	}
 
-	// 4️ Response
+	// 3️ Response
 	c.JSON(http.StatusOK, gin.H{
 		"message": "designation deleted successfully. Employee designation_id set to NULL.",
 	})
@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/storage"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// documentCategories must match the CHECK constraint of tbl_employee_document
+var documentCategories = []string{"offer_letter", "contract", "nda", "id_proof", "certificate", "other"}
+
+// Office files are ZIP archives, so they are recognised by sniffing a ZIP
+// and trusting the extension
+var officeTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// sniffDocumentType detects the file type from its content and returns ""
+// for types that cannot be uploaded (HTML, scripts, executables, ...)
+func sniffDocumentType(head []byte, filename string) string {
+	detected := http.DetectContentType(head)
+	switch detected {
+	case "application/pdf", "image/png", "image/jpeg", "image/webp":
+		return detected
+	case "application/zip":
+		return officeTypes[strings.ToLower(filepath.Ext(filename))]
+	}
+	return ""
+}
+
+// cleanFileName keeps the base name without control characters
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	if name == "" || name == "." || name == "/" {
+		name = "document"
+	}
+	return name
+}
+
+// documentAccess checks that document storage is configured, the employee
+// exists and the caller may act on their documents: their own, or any with
+// the given permission
+func (h *HandlerFunc) documentAccess(c *gin.Context, perm string) (currentUserID, empID uuid.UUID, ok bool) {
+	if h.Storage == nil {
+		utils.RespondWithError(c, http.StatusServiceUnavailable, storage.ErrDisabled.Error())
+		return
+	}
+
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	empID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	role, err := h.Query.GetEmployeeCurrentRole(empID.String())
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if empID != currentUserID {
+		if !h.HasPermission(c, perm) {
+			utils.RespondWithError(c, http.StatusForbidden, "not permitted to access documents of this employee")
+			return
+		}
+		if perm == constant.PermDocumentManage && role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+			utils.RespondWithError(c, http.StatusForbidden, "not permitted to modify SUPERADMIN users")
+			return
+		}
+	}
+	return currentUserID, empID, true
+}
+
+// UploadEmployeeDocument - POST /api/employee/:id/documents
+// Multipart form with "file" and "category". Employees can upload their own
+// documents; document.manage is needed for others.
+func (h *HandlerFunc) UploadEmployeeDocument(c *gin.Context) {
+	// 1️ Access
+	currentUserID, empID, ok := h.documentAccess(c, constant.PermDocumentManage)
+	if !ok {
+		return
+	}
+
+	// 2️ Category and file; cap the body before the multipart form is parsed
+	maxSize := int64(h.Env.DOCUMENT_MAX_SIZE_MB) << 20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20) // room for the multipart overhead
+
+	// Parse before reading any field, so an oversize body answers 413
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.RespondWithError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("file must not be larger than %d MB", h.Env.DOCUMENT_MAX_SIZE_MB))
+			return
+		}
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid multipart form: "+err.Error())
+		return
+	}
+
+	category := c.PostForm("category")
+	if !slices.Contains(documentCategories, category) {
+		utils.RespondWithError(c, http.StatusBadRequest, "category must be one of: "+strings.Join(documentCategories, ", "))
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "file is required")
+		return
+	}
+	if file.Size > maxSize {
+		utils.RespondWithError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("file must not be larger than %d MB", h.Env.DOCUMENT_MAX_SIZE_MB))
+		return
+	}
+	if file.Size == 0 {
+		utils.RespondWithError(c, http.StatusBadRequest, "file is empty")
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "failed to read file")
+		return
+	}
+	defer f.Close()
+
+	// 3️ Detect the type from the content, not the client's Content-Type
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		utils.RespondWithError(c, http.StatusBadRequest, "failed to read file")
+		return
+	}
+	head = head[:n]
+	fileName := cleanFileName(file.Filename)
+	contentType := sniffDocumentType(head, fileName)
+	if contentType == "" {
+		utils.RespondWithError(c, http.StatusUnsupportedMediaType, "file must be a PDF, PNG, JPEG, WebP, DOCX or XLSX")
+		return
+	}
+
+	// 4️ Store the content, hashing it on the way
+	doc := models.EmployeeDocument{
+		ID:          uuid.New(),
+		EmployeeID:  empID,
+		Category:    category,
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   file.Size,
+		UploadedBy:  &currentUserID,
+	}
+	doc.StorageKey = fmt.Sprintf("employees/%s/documents/%s", empID, doc.ID)
+
+	hash := sha256.New()
+	content := io.TeeReader(io.MultiReader(bytes.NewReader(head), f), hash)
+	if err := h.Storage.Put(c, doc.StorageKey, content, file.Size, contentType); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to store document: "+err.Error())
+		return
+	}
+	doc.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	// 5️ Record it
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.CreateEmployeeDocument(tx, &doc); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to save document: "+err.Error())
+		}
+		data := utils.NewCommon(constant.ComponentDocument, constant.ActionCreate, currentUserID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if delErr := h.Storage.Delete(c, doc.StorageKey); delErr != nil {
+			fmt.Printf("Failed to remove stored document %s: %v\n", doc.StorageKey, delErr)
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "document uploaded successfully",
+		"document": doc,
+	})
+}
+
+// GetEmployeeDocuments - GET /api/employee/:id/documents?category=id_proof
+func (h *HandlerFunc) GetEmployeeDocuments(c *gin.Context) {
+	_, empID, ok := h.documentAccess(c, constant.PermDocumentViewAll)
+	if !ok {
+		return
+	}
+
+	category := c.Query("category")
+	if category != "" && !slices.Contains(documentCategories, category) {
+		utils.RespondWithError(c, http.StatusBadRequest, "category must be one of: "+strings.Join(documentCategories, ", "))
+		return
+	}
+
+	docs, err := h.Query.GetEmployeeDocuments(empID, category)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch documents: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "documents fetched",
+		"documents": docs,
+	})
+}
+
+// DownloadEmployeeDocument - GET /api/employee/:id/documents/:docId
+func (h *HandlerFunc) DownloadEmployeeDocument(c *gin.Context) {
+	_, empID, ok := h.documentAccess(c, constant.PermDocumentViewAll)
+	if !ok {
+		return
+	}
+	docID, err := uuid.Parse(c.Param("docId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	doc, err := h.Query.GetEmployeeDocument(empID, docID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "document not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	content, err := h.Storage.Get(c, doc.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		utils.RespondWithError(c, http.StatusNotFound, "document file is missing")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to read document: "+err.Error())
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, doc.SizeBytes, doc.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   strconv.Quote(doc.SHA256),
+	})
+}
+
+// DeleteEmployeeDocument - DELETE /api/employee/:id/documents/:docId
+func (h *HandlerFunc) DeleteEmployeeDocument(c *gin.Context) {
+	// Deleting needs document.manage, even for your own documents
+	if !h.HasPermission(c, constant.PermDocumentManage) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to delete documents")
+		return
+	}
+	currentUserID, empID, ok := h.documentAccess(c, constant.PermDocumentManage)
+	if !ok {
+		return
+	}
+	docID, err := uuid.Parse(c.Param("docId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid document ID")
+		return
+	}
+
+	doc, err := h.Query.GetEmployeeDocument(empID, docID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "document not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.DeleteEmployeeDocument(tx, doc.ID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to delete document: "+err.Error())
+		}
+		data := utils.NewCommon(constant.ComponentDocument, constant.ActionDelete, currentUserID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// The row is gone, a leftover file is only wasted space
+	if err := h.Storage.Delete(c, doc.StorageKey); err != nil {
+		fmt.Printf("Failed to remove stored document %s: %v\n", doc.StorageKey, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "document deleted successfully",
+		"id":      doc.ID,
+	})
+}
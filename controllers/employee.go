@@ -1,15 +1,21 @@
 package controllers
 
 import (
+	"database/sql"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
 )
 
 type UpdateRoleInput struct {
@@ -19,37 +25,134 @@ type UpdateManagerInput struct {
 	ManagerID string `json:"manager_id" validate:"required"` // UUID of new manager
 }
 
+const (
+	defaultEmployeePageSize = 50
+	maxEmployeePageSize     = 200
+)
+
+// targetEmployee parses the employee of the :id route and checks they exist.
+// With manage set, changing a SUPERADMIN needs employee.manage_superadmin.
+func (h *HandlerFunc) targetEmployee(c *gin.Context, manage bool) (currentUserID uuid.UUID, emp *models.EmployeeInput, ok bool) {
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	empID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	emp, err = h.Query.GetEmployeeByID(empID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if manage && emp.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to modify SUPERADMIN users")
+		return
+	}
+	return currentUserID, emp, true
+}
+
 // GetEmployees - GET /api/employees
-// Query params: ?role=EMPLOYEE&designation=Senior Developer
+// Query params: ?role=EMPLOYEE&designation=Senior Developer&status=active&q=john
+// &sort=-joining_date&page=1&page_size=50, or &cursor=<next_cursor> instead of page
 func (h *HandlerFunc) GetEmployee(c *gin.Context) {
-	role, _ := c.Get("role")
-	r := role.(string)
+	// 1️ Filters and sorting
+	filter, err := employeeListFilter(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if r != "SUPERADMIN" && r != "ADMIN" && r != "HR" {
-		utils.RespondWithError(c, http.StatusUnauthorized, "not permitted")
+	// 2️ Page: by number, or after a cursor from the previous response
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		utils.RespondWithError(c, http.StatusBadRequest, "page must be a positive integer")
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultEmployeePageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxEmployeePageSize {
+		utils.RespondWithError(c, http.StatusBadRequest, fmt.Sprintf("page_size must be between 1 and %d", maxEmployeePageSize))
 		return
 	}
+	filter.Limit = pageSize
 
-	// Get filter parameters from query string
-	roleFilter := c.Query("role")               // e.g., ?role=EMPLOYEE
-	designationFilter := c.Query("designation") // e.g., ?designation=Senior Developer
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := utils.DecodeCursor(raw)
+		if err != nil || cursor.Sort != filter.Sort || !repositories.ValidEmployeeSortValue(filter.Sort, cursor.Value) {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		filter.AfterValue = &cursor.Value
+		filter.AfterID = &cursor.ID
+	} else {
+		filter.Offset = (page - 1) * pageSize
+	}
 
-	employees, err := h.Query.GetAllEmployees(roleFilter, designationFilter)
+	// 3️ Fetch
+	result, err := h.Query.ListEmployees(filter)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	var nextCursor string
+	if result.HasMore {
+		last := result.Employees[len(result.Employees)-1]
+		nextCursor = utils.EncodeCursor(utils.Cursor{Sort: filter.Sort, Value: result.LastSortValue, ID: *last.ID})
+	}
+
 	c.JSON(200, gin.H{
 		"message":   "Employees fetched",
-		"employees": employees,
+		"employees": result.Employees,
 		"filters": gin.H{
-			"role":        roleFilter,
-			"designation": designationFilter,
+			"role":            filter.Role,
+			"designation":     filter.Designation,
+			"department_id":   filter.DepartmentID,
+			"employment_type": filter.EmploymentType,
+			"status":          filter.Status,
+			"q":               filter.Search,
+			"sort":            filter.Sort,
+		},
+		"pagination": gin.H{
+			"page":        page,
+			"page_size":   pageSize,
+			"total":       result.Total,
+			"total_pages": (result.Total + pageSize - 1) / pageSize,
+			"has_more":    result.HasMore,
+			"next_cursor": nextCursor,
 		},
 	})
 }
 
+// employeeListFilter reads the filter and sort query params shared by the
+// employee list and export
+func employeeListFilter(c *gin.Context) (models.EmployeeListFilter, error) {
+	filter := models.EmployeeListFilter{
+		Role:        c.Query("role"),        // e.g., ?role=EMPLOYEE
+		Designation: c.Query("designation"), // e.g., ?designation=Senior Developer
+		Status:      c.Query("status"),      // e.g., ?status=active
+		Search:      strings.TrimSpace(c.Query("q")),
+		Sort:        c.DefaultQuery("sort", "name"),
+	}
+	filter.EmploymentType = strings.ToUpper(c.Query("employment_type")) // e.g., ?employment_type=CONTRACT
+	if !repositories.IsEmployeeSortField(filter.Sort) {
+		return filter, fmt.Errorf("invalid sort %q, use name, email, role, status, joining_date or created_at (prefix - for descending)", filter.Sort)
+	}
+	departmentID, err := departmentFilter(c) // e.g., ?department_id=<uuid>, sub-departments included
+	if err != nil {
+		return filter, err
+	}
+	filter.DepartmentID = departmentID
+	return filter, nil
+}
+
 // GetEmployeeById - GET /api/employee/:id
 // Simple endpoint - just fetch and return employee data
 func (h *HandlerFunc) GetEmployeeById(c *gin.Context) {
@@ -79,26 +182,23 @@ func (h *HandlerFunc) GetEmployeeById(c *gin.Context) {
 }
 
 func (h *HandlerFunc) CreateEmployee(c *gin.Context) {
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusUnauthorized, "not permitted")
-		return
-	}
-
 	var input models.EmployeeInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// HR and ADMIN cannot create SUPERADMIN users
-	if (role == "ADMIN" || role == "HR") && input.Role == "SUPERADMIN" {
-		utils.RespondWithError(c, 403, "HR and ADMIN cannot create SUPERADMIN users")
+	// Only employee.manage_superadmin can create SUPERADMIN users
+	if input.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, 403, "not permitted to create SUPERADMIN users")
 		return
 	}
 
-	if !strings.HasSuffix(input.Email, "@zenithive.com") {
-		utils.RespondWithError(c, 400, "email must end with @zenithive.com")
+	if msg, err := h.checkEmailDomain(input.Email); err != nil {
+		utils.RespondWithError(c, 500, "failed to load allowed email domains: "+err.Error())
+		return
+	} else if msg != "" {
+		utils.RespondWithError(c, 400, msg)
 		return
 	}
 
@@ -134,20 +234,65 @@ func (h *HandlerFunc) CreateEmployee(c *gin.Context) {
 		return
 	}
 
+	// EMPLOYMENT TYPE, PERMANENT IF NOT PROVIDED; probation status only changes by decision
+	if input.ProbationStatus != nil {
+		utils.RespondWithError(c, 400, "probation_status cannot be set, record a probation decision instead")
+		return
+	}
+	employmentType := defaultEmploymentType
+	if input.EmploymentType != nil {
+		employmentType = strings.ToUpper(strings.TrimSpace(*input.EmploymentType))
+	}
+	if _, err := h.Query.GetEmploymentType(h.Query.DB, employmentType); err == sql.ErrNoRows {
+		utils.RespondWithError(c, 400, "unknown employment type "+employmentType)
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, 500, "failed to check employment type: "+err.Error())
+		return
+	}
+	if input.ProbationEndDate != nil && input.JoiningDate != nil && input.ProbationEndDate.Before(*input.JoiningDate) {
+		utils.RespondWithError(c, 400, "probation_end_date must not be before the joining date")
+		return
+	}
+
 	// SET DEFAULT SALARY TO 0 IF NOT PROVIDED
 	if input.Salary == nil {
 		zeroSalary := 0.0
 		input.Salary = &zeroSalary
 	}
 
-	// INSERT
-	err = h.Query.InsertEmployee(
-		input.FullName, input.Email,
-		roleID, hash,
-		input.Salary, input.JoiningDate,
-	)
+	// INSERT, with the opening salary revision, the probation of their employment
+	// type and the onboarding checklist for their role
+	var createdBy *uuid.UUID
+	if id, err := uuid.Parse(c.GetString("user_id")); err == nil {
+		createdBy = &id
+	}
+	var empID uuid.UUID
+	var onboardingTasks int64
+	var probationEnd *time.Time
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		var err error
+		empID, err = h.Query.InsertEmployee(tx,
+			input.FullName, input.Email,
+			roleID, hash,
+			input.Salary, input.JoiningDate,
+		)
+		if err != nil {
+			return utils.CustomErr(c, 500, "failed to create employee")
+		}
+		if err := h.saveOpeningSalary(tx, empID, input.Salary, input.JoiningDate, createdBy); err != nil {
+			return utils.CustomErr(c, 500, "failed to record salary: "+err.Error())
+		}
+		if probationEnd, err = h.startEmployment(tx, empID, employmentType, input.JoiningDate, input.ProbationEndDate); err != nil {
+			return utils.CustomErr(c, 500, "failed to start probation: "+err.Error())
+		}
+		if onboardingTasks, err = h.Query.InstantiateOnboarding(tx, empID, nil); err != nil {
+			return utils.CustomErr(c, 500, "failed to create onboarding checklist: "+err.Error())
+		}
+		return nil
+	})
 	if err != nil {
-		utils.RespondWithError(c, 500, "failed to create employee")
+		utils.RespondWithTxError(c, err)
 		return
 	}
 
@@ -159,22 +304,20 @@ func (h *HandlerFunc) CreateEmployee(c *gin.Context) {
 	}()
 
 	c.JSON(201, gin.H{
-		"message":  "employee created successfully",
-		"password": generatedPassword, // Return generated password in response
+		"message":            "employee created successfully",
+		"employee_id":        empID,
+		"password":           generatedPassword, // Return generated password in response
+		"employment_type":    employmentType,
+		"probation_end_date": probationEnd,
+		"onboarding_tasks":   onboardingTasks,
 	})
 }
 func (h *HandlerFunc) UpdateEmployeeRole(c *gin.Context) {
 	// ---------------------------
-	// 1️ Check permission
+	// 1️ Current user
 	// ---------------------------
-	role := c.GetString("role")
 	currentUserID, _ := uuid.Parse(c.GetString("user_id"))
 
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, 401, "not permitted")
-		return
-	}
-
 	// ---------------------------
 	// 2️ Parse Employee ID
 	// ---------------------------
@@ -186,10 +329,10 @@ func (h *HandlerFunc) UpdateEmployeeRole(c *gin.Context) {
 	}
 
 	// ---------------------------
-	// 2.5️ ADMIN and HR cannot change their own role
+	// 2.5️ Only employee.manage_superadmin can change their own role
 	// ---------------------------
-	if (role == "ADMIN" || role == "HR") && currentUserID == empID {
-		utils.RespondWithError(c, 403, "ADMIN and HR cannot change their own role. Only SUPERADMIN can change roles.")
+	if currentUserID == empID && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, 403, "you cannot change your own role")
 		return
 	}
 
@@ -212,16 +355,16 @@ func (h *HandlerFunc) UpdateEmployeeRole(c *gin.Context) {
 	}
 
 	// ---------------------------
-	// 4.5️ HR and ADMIN cannot edit SUPERADMIN
+	// 4.5️ Only employee.manage_superadmin can edit SUPERADMIN
 	// ---------------------------
-	if (role == "ADMIN" || role == "HR") && currentRole == "SUPERADMIN" {
-		utils.RespondWithError(c, 403, "HR and ADMIN cannot modify SUPERADMIN users")
+	if currentRole == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, 403, "not permitted to modify SUPERADMIN users")
 		return
 	}
 
-	// HR and ADMIN cannot promote to SUPERADMIN
-	if (role == "ADMIN" || role == "HR") && input.Role == "SUPERADMIN" {
-		utils.RespondWithError(c, 403, "HR and ADMIN cannot promote users to SUPERADMIN")
+	// Only employee.manage_superadmin can promote to SUPERADMIN
+	if input.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, 403, "not permitted to promote users to SUPERADMIN")
 		return
 	}
 
@@ -250,6 +393,7 @@ func (h *HandlerFunc) UpdateEmployeeRole(c *gin.Context) {
 		utils.RespondWithError(c, 500, "failed to update role: "+err.Error())
 		return
 	}
+	h.Principals.Invalidate(empID)
 
 	// ---------------------------
 	// 8️ Response
@@ -272,15 +416,6 @@ func (h *HandlerFunc) DeleteEmployeeStatus(c *gin.Context) {
 		return
 	}
 
-	// Role check
-	role, _ := c.Get("role")
-	r := role.(string)
-
-	if r != "SUPERADMIN" && r != "ADMIN" && r != "HR" {
-		utils.RespondWithError(c, 401, "not permitted")
-		return
-	}
-
 	// Check if target employee is SUPERADMIN
 	targetEmp, err := h.Query.GetEmployeeByID(empID)
 	if err != nil {
@@ -288,53 +423,85 @@ func (h *HandlerFunc) DeleteEmployeeStatus(c *gin.Context) {
 		return
 	}
 
-	// HR and ADMIN cannot deactivate SUPERADMIN
-	if (r == "ADMIN" || r == "HR") && targetEmp.Role == "SUPERADMIN" {
-		utils.RespondWithError(c, 403, "HR and ADMIN cannot modify SUPERADMIN users")
+	// Only employee.manage_superadmin can deactivate SUPERADMIN
+	if targetEmp.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, 403, "not permitted to modify SUPERADMIN users")
 		return
 	}
 
 	// Toggle using same method name
-	newStatus, err := h.Query.DeleteEmployeeStatus(empID)
+	currentUserID, _ := uuid.Parse(c.GetString("user_id"))
+	var newStatus string
+	var newManagerID *uuid.UUID
+	reassigned := []uuid.UUID{}
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.LockReportingLines(tx); err != nil {
+			return utils.CustomErr(c, 500, "failed to lock reporting lines: "+err.Error())
+		}
+		status, err := h.Query.DeleteEmployeeStatus(tx, empID)
+		if err != nil {
+			return utils.CustomErr(c, 500, err.Error())
+		}
+		newStatus = status
+		// Direct reports of a deactivated employee move to the nearest active
+		// MANAGER above them, or are left without a manager
+		if newStatus == "deactive" {
+			if newManagerID, err = h.Query.GetReplacementManager(tx, empID); err != nil {
+				return utils.CustomErr(c, 500, "failed to fetch chain of command: "+err.Error())
+			}
+			if reassigned, err = h.Query.ReassignDirectReports(tx, empID, newManagerID); err != nil {
+				return utils.CustomErr(c, 500, "failed to reassign direct reports: "+err.Error())
+			}
+		}
+		data := utils.NewCommon(constant.ComponentEmployee, constant.ActionUpdate, currentUserID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
 	if err != nil {
 		utils.RespondWithError(c, 500, err.Error())
 		return
 	}
+	h.Principals.Invalidate(empID)
+
+	// Deactivated employees lose every active session immediately
+	if newStatus == "deactive" {
+		if err := h.Query.RevokeAllEmployeeTokens(empID); err != nil {
+			utils.RespondWithError(c, 500, "failed to revoke sessions: "+err.Error())
+			return
+		}
+	}
 
 	c.JSON(200, gin.H{
-		"message":    "Employee status updated successfully",
-		"new_status": newStatus,
+		"message":            "Employee status updated successfully",
+		"new_status":         newStatus,
+		"reassigned_reports": reassigned,
+		"new_manager_id":     newManagerID,
 	})
 }
 func (h *HandlerFunc) UpdateEmployeeManager(c *gin.Context) {
-	// 1️ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, 401, "not permitted")
-		return
-	}
-
-	// 2️ Parse Employee ID
+	// 1️ Parse Employee ID
 	empID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		utils.RespondWithError(c, 400, "invalid employee ID")
 		return
 	}
 
-	// 2.5️ Check if target employee is SUPERADMIN
+	// 1.5️ Check if target employee is SUPERADMIN
 	targetEmp, err := h.Query.GetEmployeeByID(empID)
 	if err != nil {
 		utils.RespondWithError(c, 404, "employee not found")
 		return
 	}
 
-	// HR and ADMIN cannot assign manager to SUPERADMIN
-	if (role == "ADMIN" || role == "HR") && targetEmp.Role == "SUPERADMIN" {
-		utils.RespondWithError(c, 403, "HR and ADMIN cannot modify SUPERADMIN users")
+	// Only employee.manage_superadmin can assign manager to SUPERADMIN
+	if targetEmp.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, 403, "not permitted to modify SUPERADMIN users")
 		return
 	}
 
-	// 3️ Parse Manager ID
+	// 2️ Parse Manager ID
 	var input UpdateManagerInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.RespondWithError(c, 400, "invalid input: "+err.Error())
@@ -346,20 +513,20 @@ func (h *HandlerFunc) UpdateEmployeeManager(c *gin.Context) {
 		return
 	}
 
-	// 4️ Self assignment check
+	// 3️ Self assignment check
 	if empID == managerID {
 		utils.RespondWithError(c, 400, "cannot assign employee as their own manager")
 		return
 	}
 
-	// 4.5️ Prevent manager from assigning themselves to others
+	// 3.5️ Prevent manager from assigning themselves to others
 	currentUserID, _ := uuid.Parse(c.GetString("user_id"))
-	if currentUserID == managerID && role != "SUPERADMIN" {
-		utils.RespondWithError(c, 403, "you cannot assign yourself as a manager to others. Only SUPERADMIN can do this.")
+	if currentUserID == managerID && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, 403, "you cannot assign yourself as a manager to others")
 		return
 	}
 
-	// 5️ Check if employee already has a manager
+	// 4️ Check if employee already has a manager
 	// var existingManager uuid.UUID
 	// err = h.Query.DB.Get(&existingManager, "SELECT manager_id FROM Tbl_Employee WHERE id=$1", empID)
 	// if err != nil {
@@ -372,31 +539,48 @@ func (h *HandlerFunc) UpdateEmployeeManager(c *gin.Context) {
 	// 	return
 	// }
 
-	// 6️ Validate Manager exists, active and role = MANAGER
-	var mgrRole, mgrStatus string
-	err = h.Query.DB.Get(&mgrRole, "SELECT r.type FROM Tbl_Employee e JOIN Tbl_Role r ON e.role_id = r.id WHERE e.id=$1", managerID)
-	if err != nil {
-		utils.RespondWithError(c, 404, "manager not found")
-		return
-	}
-	err = h.Query.DB.Get(&mgrStatus, "SELECT status FROM Tbl_Employee WHERE id=$1", managerID)
-	if err != nil || mgrStatus != "active" {
-		utils.RespondWithError(c, 403, "manager is deactivated")
-		return
-	}
-	if mgrRole != "MANAGER" {
-		utils.RespondWithError(c, 400, "assigned employee is not a manager")
-		return
-	}
-
-	// 7️ Update manager
-	err = h.Query.UpdateManager(empID, managerID)
+	// 5️ Update manager, unless the employee is above the new manager: that
+	// would close a reporting cycle
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.LockReportingLines(tx); err != nil {
+			return utils.CustomErr(c, 500, "failed to lock reporting lines: "+err.Error())
+		}
+		// Manager exists, active and role = MANAGER, checked under the lock so
+		// a concurrent deactivation or demotion is seen
+		mgrRole, mgrStatus, err := h.Query.GetManagerStatus(tx, managerID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, 404, "manager not found")
+		} else if err != nil {
+			return utils.CustomErr(c, 500, "failed to fetch manager: "+err.Error())
+		}
+		if mgrStatus != "active" {
+			return utils.CustomErr(c, 403, "manager is deactivated")
+		}
+		if mgrRole != "MANAGER" {
+			return utils.CustomErr(c, 400, "assigned employee is not a manager")
+		}
+		cycle, err := h.Query.IsInChainOfCommand(tx, empID, managerID)
+		if err != nil {
+			return utils.CustomErr(c, 500, "failed to check reporting line: "+err.Error())
+		}
+		if cycle {
+			return utils.CustomErr(c, 409, "manager reports to this employee, the assignment would create a reporting cycle")
+		}
+		if err := h.Query.UpdateManager(tx, empID, managerID); err != nil {
+			return utils.CustomErr(c, 500, "failed to update manager: "+err.Error())
+		}
+		data := utils.NewCommon(constant.ComponentEmployee, constant.ActionUpdate, currentUserID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
 	if err != nil {
-		utils.RespondWithError(c, 500, "failed to update manager: "+err.Error())
+		utils.RespondWithTxError(c, err)
 		return
 	}
 
-	// 8️ Success response
+	// 6️ Success response
 	c.JSON(200, gin.H{
 		"message":     "manager updated successfully",
 		"employee_id": empID,
@@ -406,11 +590,10 @@ func (h *HandlerFunc) UpdateEmployeeManager(c *gin.Context) {
 
 // UpdateEmployeeInfo - PATCH /api/employee/:id
 // Anyone can update their own name
-// Only SUPERADMIN and ADMIN can update email and salary
+// Only roles with employee.update can update email and salary
 func (h *HandlerFunc) UpdateEmployeeInfo(c *gin.Context) {
 	// 1️⃣ Get current user info
 	currentUserID, _ := uuid.Parse(c.GetString("user_id"))
-	role := c.GetString("role")
 
 	// 2️⃣ Parse Employee ID
 	empIDStr := c.Param("id")
@@ -427,9 +610,9 @@ func (h *HandlerFunc) UpdateEmployeeInfo(c *gin.Context) {
 		return
 	}
 
-	// 3.5️⃣ HR and ADMIN cannot edit SUPERADMIN
-	if (role == "ADMIN" || role == "HR") && existingEmp.Role == "SUPERADMIN" {
-		utils.RespondWithError(c, 403, "HR and ADMIN cannot modify SUPERADMIN users")
+	// 3.5️⃣ Only employee.manage_superadmin can edit SUPERADMIN
+	if existingEmp.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, 403, "not permitted to modify SUPERADMIN users")
 		return
 	}
 
@@ -447,12 +630,12 @@ func (h *HandlerFunc) UpdateEmployeeInfo(c *gin.Context) {
 	}
 
 	// 5️⃣ Permission checks
-	isAdmin := role == "SUPERADMIN" || role == "ADMIN"
+	isAdmin := h.HasPermission(c, constant.PermEmployeeUpdate)
 	isSelf := currentUserID == empID
 
 	// Check if trying to update email, salary, joining_date, or ending_date
 	if (input.Email != nil || input.Salary != nil || input.JoiningDate != nil || input.EndingDate != nil) && !isAdmin {
-		utils.RespondWithError(c, 403, "only SUPERADMIN and ADMIN can update email, salary, joining date, and ending date")
+		utils.RespondWithError(c, 403, "not permitted to update email, salary, joining date, and ending date")
 		return
 	}
 
@@ -465,8 +648,11 @@ func (h *HandlerFunc) UpdateEmployeeInfo(c *gin.Context) {
 	// 6️⃣ Validate and update email if provided
 	var finalEmail string
 	if input.Email != nil {
-		if !strings.HasSuffix(*input.Email, "@zenithive.com") {
-			utils.RespondWithError(c, 400, "email must end with @zenithive.com")
+		if msg, err := h.checkEmailDomain(*input.Email); err != nil {
+			utils.RespondWithError(c, 500, "failed to load allowed email domains: "+err.Error())
+			return
+		} else if msg != "" {
+			utils.RespondWithError(c, 400, msg)
 			return
 		}
 
@@ -508,10 +694,46 @@ func (h *HandlerFunc) UpdateEmployeeInfo(c *gin.Context) {
 		finalEndingDate = input.EndingDate
 	}
 
-	// 8️⃣ Update employee info
-	err = h.Query.UpdateEmployeeInfo(empID, finalName, finalEmail, finalSalary, finalJoiningDate, finalEndingDate)
+	// 8️⃣ Update employee info; an ending date starts the offboarding (or moves
+	// its date), pending onboarding tasks follow a new joining date and a new
+	// salary is recorded as a revision in force from today
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if input.Salary != nil {
+			// Same lock as salary revisions and payroll finalization
+			if err := h.Query.LockSalaryHistory(tx, empID); err != nil {
+				return utils.CustomErr(c, 500, "failed to lock salary history: "+err.Error())
+			}
+		}
+		err := h.Query.UpdateEmployeeInfo(tx, empID, finalName, finalEmail, finalSalary, finalJoiningDate, finalEndingDate)
+		if err != nil {
+			return utils.CustomErr(c, 500, "failed to update employee: "+err.Error())
+		}
+		if input.EndingDate != nil {
+			if _, _, err := h.scheduleOffboarding(tx, empID, dateOnly(*input.EndingDate), nil, currentUserID); err != nil {
+				return utils.CustomErr(c, 500, "failed to start offboarding: "+err.Error())
+			}
+		}
+		if input.JoiningDate != nil {
+			if err := h.Query.RescheduleOnboardingTasks(tx, empID, dateOnly(*input.JoiningDate)); err != nil {
+				return utils.CustomErr(c, 500, "failed to reschedule onboarding tasks: "+err.Error())
+			}
+		}
+		if input.Salary != nil {
+			revisions, err := h.Query.GetSalaryRevisions(tx, empID)
+			if err != nil {
+				return utils.CustomErr(c, 500, "failed to fetch salary history: "+err.Error())
+			}
+			if current := currentSalaryRevision(revisions); current == nil || current.Salary != *input.Salary {
+				_, err := h.Query.SaveSalaryRevision(tx, empID, *input.Salary, today(), "Salary updated", &currentUserID)
+				if err != nil {
+					return utils.CustomErr(c, 500, "failed to record salary revision: "+err.Error())
+				}
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		utils.RespondWithError(c, 500, "failed to update employee: "+err.Error())
+		utils.RespondWithTxError(c, err)
 		return
 	}
 
@@ -522,19 +744,10 @@ func (h *HandlerFunc) UpdateEmployeeInfo(c *gin.Context) {
 	})
 }
 
-// GetEmployeeReports - GET /api/employees/:id/reports
-func (s *HandlerFunc) GetEmployeeReports(c *gin.Context) {
-	c.JSON(200, gin.H{"message": "Get employee reports"})
-}
-
 // UpdateEmployeePassword - PATCH /api/employee/:id/password
 func (h *HandlerFunc) UpdateEmployeePassword(c *gin.Context) {
-	// 1️ Permission check - Only SUPERADMIN, ADMIN, and HR
+	// 1️ Current role (permission is enforced by the route: employee.password)
 	role := c.GetString("role")
-	// if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-	// 	utils.RespondWithError(c, 401, "not permitted to update password")
-	// 	return
-	// }
 
 	// 2️ Parse Employee ID
 	empIDStr := c.Param("id")
@@ -566,9 +779,9 @@ func (h *HandlerFunc) UpdateEmployeePassword(c *gin.Context) {
 		return
 	}
 
-	// 5.5️ HR and ADMIN cannot change SUPERADMIN password
-	if (role == "ADMIN" || role == "HR") && existingEmp.Role == "SUPERADMIN" {
-		utils.RespondWithError(c, 403, "HR and ADMIN cannot modify SUPERADMIN users")
+	// 5.5️ Only employee.manage_superadmin can change SUPERADMIN password
+	if existingEmp.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, 403, "not permitted to modify SUPERADMIN users")
 		return
 	}
 
@@ -586,6 +799,12 @@ func (h *HandlerFunc) UpdateEmployeePassword(c *gin.Context) {
 		return
 	}
 
+	// 7.5️ Sign the employee out everywhere
+	if err := h.Query.RevokeAllEmployeeTokens(empID); err != nil {
+		utils.RespondWithError(c, 500, "failed to revoke sessions: "+err.Error())
+		return
+	}
+
 	// 8️ Send notification email to employee with new password
 	go func() {
 		var empDetails struct {
@@ -633,22 +852,15 @@ func (h *HandlerFunc) UpdateEmployeePassword(c *gin.Context) {
 func (h *HandlerFunc) GetMyTeam(c *gin.Context) {
 	// 1️⃣ Get current user info
 	currentUserID, _ := uuid.Parse(c.GetString("user_id"))
-	role := c.GetString("role")
 
-	// 2️⃣ Permission check - Only MANAGER can use this endpoint
-	if role != "MANAGER" {
-		utils.RespondWithError(c, 403, "only managers can access team member list")
-		return
-	}
-
-	// 3️⃣ Fetch team members
+	// 2️⃣ Fetch team members
 	employees, err := h.Query.GetEmployeesByManagerID(currentUserID)
 	if err != nil {
 		utils.RespondWithError(c, 500, "failed to fetch team members: "+err.Error())
 		return
 	}
 
-	// 4️⃣ Response
+	// 3️⃣ Response
 	c.JSON(200, gin.H{
 		"message":      "team members fetched successfully",
 		"manager_id":   currentUserID,
@@ -658,16 +870,9 @@ func (h *HandlerFunc) GetMyTeam(c *gin.Context) {
 }
 
 // UpdateEmployeeDesignation - PATCH /api/employee/:id/designation
-// Only ADMIN, SUPERADMIN, and HR can assign/update employee designation
+// Requires employee.designation
 func (h *HandlerFunc) UpdateEmployeeDesignation(c *gin.Context) {
-	// 1️⃣ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can assign designations")
-		return
-	}
-
-	// 2️⃣ Parse Employee ID
+	// 1️⃣ Parse Employee ID
 	empIDStr := c.Param("id")
 	empID, err := uuid.Parse(empIDStr)
 	if err != nil {
@@ -675,20 +880,20 @@ func (h *HandlerFunc) UpdateEmployeeDesignation(c *gin.Context) {
 		return
 	}
 
-	// 3️⃣ Check if employee exists
+	// 2️⃣ Check if employee exists
 	targetEmp, err := h.Query.GetEmployeeByID(empID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
 		return
 	}
 
-	// 4️⃣ HR and ADMIN cannot modify SUPERADMIN
-	if (role == "ADMIN" || role == "HR") && targetEmp.Role == "SUPERADMIN" {
-		utils.RespondWithError(c, http.StatusForbidden, "HR and ADMIN cannot modify SUPERADMIN users")
+	// 3️⃣ Only employee.manage_superadmin can modify SUPERADMIN
+	if targetEmp.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to modify SUPERADMIN users")
 		return
 	}
 
-	// 5️⃣ Bind input JSON
+	// 4️⃣ Bind input JSON
 	var input struct {
 		DesignationID *string `json:"designation_id"` // Can be null to remove designation
 	}
@@ -697,7 +902,7 @@ func (h *HandlerFunc) UpdateEmployeeDesignation(c *gin.Context) {
 		return
 	}
 
-	// 6️⃣ Parse and validate designation ID if provided
+	// 5️⃣ Parse and validate designation ID if provided
 	var designationID *uuid.UUID
 	if input.DesignationID != nil && *input.DesignationID != "" {
 		parsedID, err := uuid.Parse(*input.DesignationID)
@@ -715,14 +920,14 @@ func (h *HandlerFunc) UpdateEmployeeDesignation(c *gin.Context) {
 		designationID = &parsedID
 	}
 
-	// 7️⃣ Update employee designation
+	// 6️⃣ Update employee designation
 	err = h.Query.UpdateEmployeeDesignation(empID, designationID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to update designation: "+err.Error())
 		return
 	}
 
-	// 8️⃣ Response
+	// 7️⃣ Response
 	message := "employee designation updated successfully"
 	if designationID == nil {
 		message = "employee designation removed successfully"
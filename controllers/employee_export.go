@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// ExportEmployees - GET /api/employee/export
+// Query params: ?format=csv|xlsx&include_salary=true plus the filter and sort
+// params of GET /api/employee. Streams the employee directory as a file.
+// Salary is only included on request and needs payroll.view_all.
+func (h *HandlerFunc) ExportEmployees(c *gin.Context) {
+	// 1️ Options
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	if format != "csv" && format != "xlsx" {
+		utils.RespondWithError(c, http.StatusBadRequest, "format must be csv or xlsx")
+		return
+	}
+	filter, err := employeeListFilter(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	includeSalary, _ := strconv.ParseBool(c.DefaultQuery("include_salary", "false"))
+	if includeSalary && !h.HasPermission(c, constant.PermPayrollViewAll) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to export salaries")
+		return
+	}
+
+	// 2️ Fetch with the same filters as GET /api/employee, without paging
+	result, err := h.Query.ListEmployees(filter)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// 3️ Stream the file
+	header := []any{"Full Name", "Email", "Role", "Status", "Designation", "Department", "Employment Type", "Manager", "Joining Date", "Ending Date"}
+	if includeSalary {
+		header = append(header, "Salary")
+	}
+
+	w, err := utils.NewSpreadsheetWriter(c.Writer, format, "Employees")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to create export: "+err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("employees-%s.%s", time.Now().Format("2006-01-02"), format)
+	c.Header("Content-Type", utils.SpreadsheetContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	if err := w.WriteRow(header...); err != nil {
+		fmt.Printf("Failed to export employees: %v\n", err)
+		return
+	}
+	for _, emp := range result.Employees {
+		row := []any{
+			emp.FullName, emp.Email, emp.Role, emp.Status,
+			emp.DesignationName, emp.DepartmentName, emp.EmploymentType, emp.ManagerName,
+			emp.JoiningDate, emp.EndingDate,
+		}
+		if includeSalary {
+			row = append(row, emp.Salary)
+		}
+		if err := w.WriteRow(row...); err != nil {
+			// Headers are already sent, the client sees a truncated file
+			fmt.Printf("Failed to export employees: %v\n", err)
+			return
+		}
+	}
+	if err := w.Close(); err != nil {
+		fmt.Printf("Failed to export employees: %v\n", err)
+	}
+}
@@ -0,0 +1,402 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+const (
+	maxImportFileSize = 5 << 20 // 5 MB
+	maxImportRows     = 500
+)
+
+// importColumns are the recognised header names; the first three are required
+var importColumns = []string{"full_name", "email", "role", "manager_email", "designation", "salary", "joining_date",
+	"employment_type", "probation_end_date"}
+
+// ImportEmployees - POST /api/employee/import?dry_run=true
+// Creates employees from an uploaded .csv or .xlsx file (form field "file").
+// Every row is validated first; nothing is imported unless all rows are valid.
+// With dry_run=true only the validation report is returned.
+func (h *HandlerFunc) ImportEmployees(c *gin.Context) {
+	// 1️ Current user
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+
+	// 2️ Read the uploaded file
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "file is required")
+		return
+	}
+	if file.Size > maxImportFileSize {
+		utils.RespondWithError(c, http.StatusBadRequest, "file must not be larger than 5 MB")
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "failed to read file")
+		return
+	}
+	defer f.Close()
+
+	records, err := utils.ReadSpreadsheet(file.Filename, f)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 3️ Parse rows
+	rows, err := parseImportRows(records)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 4️ Validate every row against the database
+	valid, err := h.validateImportRows(c, rows)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to validate rows: "+err.Error())
+		return
+	}
+
+	if dryRun || !valid {
+		status := http.StatusOK
+		message := "dry run, nothing was imported"
+		if !valid && !dryRun {
+			status = http.StatusUnprocessableEntity
+			message = "file has invalid rows, nothing was imported"
+		}
+		c.JSON(status, gin.H{
+			"message": message,
+			"valid":   valid,
+			"total":   len(rows),
+			"rows":    rows,
+		})
+		return
+	}
+
+	// 5️ Generate passwords before the transaction (hashing is slow)
+	passwords := make([]string, len(rows))
+	hashes := make([]string, len(rows))
+	for i := range rows {
+		if passwords[i], err = utils.GenerateSecurePassword(); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate secure password")
+			return
+		}
+		if hashes[i], err = utils.HashPassword(passwords[i]); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to hash password")
+			return
+		}
+	}
+
+	// 6️ Insert all rows in one transaction
+	created := make([]gin.H, 0, len(rows))
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		for i, row := range rows {
+			id, err := h.Query.InsertImportedEmployee(tx, row, hashes[i])
+			if err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError,
+					fmt.Sprintf("failed to create employee on row %d: %s", row.Row, err.Error()))
+			}
+			if err := h.saveOpeningSalary(tx, id, row.Salary, row.JoiningDate, &currentUserID); err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError,
+					fmt.Sprintf("failed to record salary on row %d: %s", row.Row, err.Error()))
+			}
+			probationEnd, err := h.startEmployment(tx, id, row.EmploymentType, row.JoiningDate, row.ProbationEndDate)
+			if err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError,
+					fmt.Sprintf("failed to start probation on row %d: %s", row.Row, err.Error()))
+			}
+			tasks, err := h.Query.InstantiateOnboarding(tx, id, nil)
+			if err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError,
+					fmt.Sprintf("failed to create onboarding checklist on row %d: %s", row.Row, err.Error()))
+			}
+			created = append(created, gin.H{
+				"row":                row.Row,
+				"id":                 id,
+				"email":              row.Email,
+				"password":           passwords[i],
+				"probation_end_date": probationEnd,
+				"onboarding_tasks":   tasks,
+			})
+		}
+
+		data := utils.NewCommon(constant.ComponentEmployee, constant.ActionImport, currentUserID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// 7️ Welcome emails only once everything is committed
+	go func() {
+		for i, row := range rows {
+			if err := utils.SendEmployeeCreationEmail(row.Email, row.FullName, passwords[i]); err != nil {
+				fmt.Printf("Failed to send welcome email to %s: %v\n", row.Email, err)
+			}
+		}
+	}()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   fmt.Sprintf("%d employees imported successfully", len(created)),
+		"total":     len(created),
+		"employees": created,
+	})
+}
+
+// parseImportRows maps the records to rows by header name. Format problems of
+// single cells are reported on the row, problems with the file itself as error.
+func parseImportRows(records [][]string) ([]models.EmployeeImportRow, error) {
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	// Header: column name -> index
+	index := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.ReplaceAll(name, " ", "_")
+		index[name] = i
+	}
+	for _, name := range importColumns[:3] {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing column %q, expected columns: %s", name, strings.Join(importColumns, ", "))
+		}
+	}
+
+	rows := []models.EmployeeImportRow{}
+	for i, record := range records[1:] {
+		cell := func(name string) string {
+			col, ok := index[name]
+			if !ok || col >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[col])
+		}
+
+		// Skip blank lines
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row := models.EmployeeImportRow{
+			Row:          i + 2,
+			FullName:     cell("full_name"),
+			Email:        cell("email"),
+			Role:         strings.ToUpper(cell("role")),
+			ManagerEmail: cell("manager_email"),
+			Designation:  cell("designation"),
+		}
+		row.EmploymentType = strings.ToUpper(cell("employment_type"))
+
+		if row.FullName == "" {
+			row.Errors = append(row.Errors, "full_name is required")
+		}
+		if row.Email == "" {
+			row.Errors = append(row.Errors, "email is required")
+		} else if !utils.IsValidEmail(row.Email) {
+			row.Errors = append(row.Errors, "email is invalid")
+		}
+		if row.Role == "" {
+			row.Errors = append(row.Errors, "role is required")
+		}
+
+		// Salary defaults to 0 as in CreateEmployee
+		salary := 0.0
+		if value := cell("salary"); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed < 0 {
+				row.Errors = append(row.Errors, "salary must be a non-negative number")
+			}
+			salary = parsed
+		}
+		row.Salary = &salary
+
+		if value := cell("joining_date"); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				row.Errors = append(row.Errors, "joining_date must be YYYY-MM-DD")
+			} else {
+				row.JoiningDate = &date
+			}
+		}
+
+		if value := cell("probation_end_date"); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			switch {
+			case err != nil:
+				row.Errors = append(row.Errors, "probation_end_date must be YYYY-MM-DD")
+			case row.JoiningDate != nil && date.Before(*row.JoiningDate):
+				row.Errors = append(row.Errors, "probation_end_date must not be before the joining date")
+			default:
+				row.ProbationEndDate = &date
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("file has no employee rows")
+	}
+	if len(rows) > maxImportRows {
+		return nil, fmt.Errorf("file has %d rows, at most %d can be imported at once", len(rows), maxImportRows)
+	}
+	return rows, nil
+}
+
+// validateImportRows resolves role, manager and designation of every row and
+// records the problems on the row. It reports whether all rows are valid.
+func (h *HandlerFunc) validateImportRows(c *gin.Context, rows []models.EmployeeImportRow) (bool, error) {
+	domains, err := h.Query.GetAllowedEmailDomains()
+	if err != nil {
+		return false, err
+	}
+	canManageSuperadmin := h.HasPermission(c, constant.PermEmployeeManageSuperadmin)
+	canSetManager := h.HasPermission(c, constant.PermEmployeeManager)
+	canSetDesignation := h.HasPermission(c, constant.PermEmployeeDesignation)
+
+	// Lookups repeat a lot across rows
+	roleIDs := map[string]string{}
+	managers := map[string]uuid.UUID{}
+	designations := map[string]uuid.UUID{}
+	employmentTypes := map[string]bool{}
+	seenEmails := map[string]int{}
+
+	valid := true
+	for i := range rows {
+		row := &rows[i]
+
+		// Email: allowed domain, unique in the file and in the database
+		if row.Email != "" {
+			if msg := emailDomainMessage(domains, row.Email); msg != "" {
+				row.Errors = append(row.Errors, msg)
+			}
+			key := strings.ToLower(row.Email)
+			if first, dup := seenEmails[key]; dup {
+				row.Errors = append(row.Errors, fmt.Sprintf("email is duplicated on row %d", first))
+			} else {
+				seenEmails[key] = row.Row
+				exists, err := h.Query.CheckEmailExists(row.Email)
+				if err != nil {
+					return false, err
+				}
+				if exists {
+					row.Errors = append(row.Errors, "email already exists")
+				}
+			}
+		}
+
+		// Role
+		if row.Role != "" {
+			if row.Role == "SUPERADMIN" && !canManageSuperadmin {
+				row.Errors = append(row.Errors, "not permitted to create SUPERADMIN users")
+			}
+			roleID, ok := roleIDs[row.Role]
+			if !ok {
+				roleID, err = h.Query.GetRoleID(row.Role)
+				if err != nil && err != sql.ErrNoRows {
+					return false, err
+				}
+				roleIDs[row.Role] = roleID
+			}
+			if roleID == "" {
+				row.Errors = append(row.Errors, "role not found")
+			}
+			row.RoleID = roleID
+		}
+
+		// Manager: an existing, active MANAGER
+		if row.ManagerEmail != "" {
+			key := strings.ToLower(row.ManagerEmail)
+			managerID, ok := managers[key]
+			if !ok {
+				mgr, err := h.Query.GetEmployeeByEmail(row.ManagerEmail)
+				switch {
+				case err == sql.ErrNoRows:
+					row.Errors = append(row.Errors, "manager not found")
+				case err != nil:
+					return false, err
+				case mgr.Status != "active":
+					row.Errors = append(row.Errors, "manager is deactivated")
+				case mgr.Role != "MANAGER":
+					row.Errors = append(row.Errors, "manager_email is not a manager")
+				default:
+					managerID = uuid.MustParse(mgr.ID)
+					managers[key] = managerID
+				}
+			}
+			if !canSetManager {
+				row.Errors = append(row.Errors, "not permitted to assign a manager")
+			}
+			if managerID != uuid.Nil {
+				row.ManagerID = &managerID
+			}
+		}
+
+		// Designation by name
+		if row.Designation != "" {
+			key := strings.ToLower(row.Designation)
+			designationID, ok := designations[key]
+			if !ok {
+				designationID, err = h.Query.GetDesignationIDByName(row.Designation)
+				if err != nil && err != sql.ErrNoRows {
+					return false, err
+				}
+				designations[key] = designationID
+			}
+			if designationID == uuid.Nil {
+				row.Errors = append(row.Errors, "designation not found")
+			} else {
+				row.DesignationID = &designationID
+			}
+			if !canSetDesignation {
+				row.Errors = append(row.Errors, "not permitted to assign a designation")
+			}
+		}
+
+		// Employment type by code
+		if row.EmploymentType != "" {
+			known, ok := employmentTypes[row.EmploymentType]
+			if !ok {
+				_, err := h.Query.GetEmploymentType(h.Query.DB, row.EmploymentType)
+				if err != nil && err != sql.ErrNoRows {
+					return false, err
+				}
+				known = err == nil
+				employmentTypes[row.EmploymentType] = known
+			}
+			if !known {
+				row.Errors = append(row.Errors, "employment type not found")
+			}
+		}
+
+		if len(row.Errors) > 0 {
+			valid = false
+		}
+	}
+	return valid, nil
+}
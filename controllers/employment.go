@@ -0,0 +1,565 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// defaultEmploymentType is the type of employees created without one
+const defaultEmploymentType = "PERMANENT"
+
+var employmentTypeCode = regexp.MustCompile(`^[A-Z][A-Z_]*$`)
+
+// dateOnly drops the time of day, keeping the calendar date in UTC
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// startEmployment sets the new hire's employment type (kept when code is
+// empty) and starts their probation: until probationEnd, or the type's
+// probation_months after joining. Returns the probation end, nil without one.
+func (h *HandlerFunc) startEmployment(tx *sqlx.Tx, empID uuid.UUID, code string, joiningDate, probationEnd *time.Time) (*time.Time, error) {
+	if code != "" {
+		if err := h.Query.SetEmployeeEmploymentType(tx, empID, code); err != nil {
+			return nil, err
+		}
+	}
+	if probationEnd == nil {
+		months, err := h.Query.GetEmployeeProbationMonths(tx, empID)
+		if err != nil || months == 0 {
+			return nil, err
+		}
+		start := today()
+		if joiningDate != nil {
+			start = dateOnly(*joiningDate)
+		}
+		end := start.AddDate(0, months, 0)
+		probationEnd = &end
+	}
+	end := dateOnly(*probationEnd)
+	return &end, h.Query.SetProbation(tx, empID, "ON_PROBATION", &end)
+}
+
+// bindEmploymentType binds and validates an employment type, defaulting the
+// payroll switches to on. A non-empty code replaces the one of the body.
+func bindEmploymentType(c *gin.Context, code string) (input models.EmploymentTypeInput, includeInPayroll, deductAbsences bool, ok bool) {
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if code != "" {
+		input.Code = code
+	}
+	input.Code = strings.ToUpper(strings.TrimSpace(input.Code))
+	input.Name = strings.TrimSpace(input.Name)
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	if !employmentTypeCode.MatchString(input.Code) {
+		utils.RespondWithError(c, http.StatusBadRequest, "code must be upper case letters and underscores, e.g. PART_TIME")
+		return
+	}
+	includeInPayroll = input.IncludeInPayroll == nil || *input.IncludeInPayroll
+	deductAbsences = input.DeductAbsences == nil || *input.DeductAbsences
+	return input, includeInPayroll, deductAbsences, true
+}
+
+// ListEmploymentTypes - GET /api/employment-types
+// All authenticated users can view employment types
+func (h *HandlerFunc) ListEmploymentTypes(c *gin.Context) {
+	types, err := h.Query.ListEmploymentTypes()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch employment types: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "employment types fetched successfully",
+		"employment_types": types,
+	})
+}
+
+// CreateEmploymentType - POST /api/employment-types
+// Requires employment.manage
+func (h *HandlerFunc) CreateEmploymentType(c *gin.Context) {
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	input, includeInPayroll, deductAbsences, ok := bindEmploymentType(c, "")
+	if !ok {
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if _, err := h.Query.GetEmploymentType(tx, input.Code); err == nil {
+			return utils.CustomErr(c, http.StatusConflict, "an employment type with this code already exists")
+		} else if err != sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to check employment type: "+err.Error())
+		}
+		if err := h.Query.CreateEmploymentType(tx, input, includeInPayroll, deductAbsences); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to create employment type: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentEmployment, constant.ActionCreate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "employment type created successfully",
+		"code":    input.Code,
+	})
+}
+
+// UpdateEmploymentType - PUT /api/employment-types/:code
+// Changes the name, payroll switches and default probation; the code stays.
+// Requires employment.manage
+func (h *HandlerFunc) UpdateEmploymentType(c *gin.Context) {
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	code := strings.ToUpper(c.Param("code"))
+	input, includeInPayroll, deductAbsences, ok := bindEmploymentType(c, code)
+	if !ok {
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		found, err := h.Query.UpdateEmploymentType(tx, code, input, includeInPayroll, deductAbsences)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update employment type: "+err.Error())
+		}
+		if !found {
+			return utils.CustomErr(c, http.StatusNotFound, "employment type not found")
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentEmployment, constant.ActionUpdate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "employment type updated successfully",
+		"code":    code,
+	})
+}
+
+// DeleteEmploymentType - DELETE /api/employment-types/:code
+// Only types no employee has can be deleted. Requires employment.manage
+func (h *HandlerFunc) DeleteEmploymentType(c *gin.Context) {
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	code := strings.ToUpper(c.Param("code"))
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		used, err := h.Query.EmploymentTypeInUse(tx, code)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to check employment type: "+err.Error())
+		}
+		if used {
+			return utils.CustomErr(c, http.StatusConflict, "employment type is assigned to employees")
+		}
+		found, err := h.Query.DeleteEmploymentType(tx, code)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to delete employment type: "+err.Error())
+		}
+		if !found {
+			return utils.CustomErr(c, http.StatusNotFound, "employment type not found")
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentEmployment, constant.ActionDelete, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "employment type deleted successfully",
+		"code":    code,
+	})
+}
+
+// UpdateEmployeeEmployment - PATCH /api/employee/:id/employment
+// Changes the employment type and, with probation_end_date, starts or moves
+// the probation. Requires employment.manage
+func (h *HandlerFunc) UpdateEmployeeEmployment(c *gin.Context) {
+	// 1️ Target and input
+	currentUserID, emp, ok := h.targetEmployee(c, true)
+	if !ok {
+		return
+	}
+	var input models.UpdateEmploymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	input.EmploymentType = strings.ToUpper(strings.TrimSpace(input.EmploymentType))
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	var probationEnd *time.Time
+	if input.ProbationEndDate != nil {
+		end, _ := time.Parse("2006-01-02", *input.ProbationEndDate)
+		if emp.JoiningDate != nil && end.Before(dateOnly(*emp.JoiningDate)) {
+			utils.RespondWithError(c, http.StatusBadRequest, "probation_end_date must not be before the joining date")
+			return
+		}
+		probationEnd = &end
+	}
+
+	// 2️ Change the type and probation
+	var probation EmployeeProbationResponse
+	err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if _, err := h.Query.GetEmploymentType(tx, input.EmploymentType); err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusBadRequest, "unknown employment type "+input.EmploymentType)
+		} else if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to check employment type: "+err.Error())
+		}
+		if err := h.Query.SetEmployeeEmploymentType(tx, *emp.ID, input.EmploymentType); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update employment type: "+err.Error())
+		}
+		if probationEnd != nil {
+			if err := h.Query.SetProbation(tx, *emp.ID, "ON_PROBATION", probationEnd); err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError, "failed to update probation: "+err.Error())
+			}
+		}
+		p, err := h.Query.GetEmployeeProbation(tx, *emp.ID)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch probation: "+err.Error())
+		}
+		probation = EmployeeProbationResponse(p)
+		if err := common.AddLog(utils.NewCommon(constant.ComponentEmployment, constant.ActionUpdate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "employment updated successfully",
+		"employee_id": emp.ID,
+		"employment":  probation,
+	})
+}
+
+// EmployeeProbationResponse is the employment state of an employee
+type EmployeeProbationResponse struct {
+	EmploymentType   string     `json:"employment_type"`
+	ProbationEndDate *time.Time `json:"probation_end_date"`
+	ProbationStatus  *string    `json:"probation_status"` // nil when hired without probation
+}
+
+// GetProbation - GET /api/employee/:id/probation
+// Employment type, probation and its decisions (Self/Manager/employment.manage)
+func (h *HandlerFunc) GetProbation(c *gin.Context) {
+	currentUserID, emp, ok := h.targetEmployee(c, false)
+	if !ok {
+		return
+	}
+	isManager := emp.ManagerID != nil && *emp.ManagerID == currentUserID
+	if *emp.ID != currentUserID && !isManager && !h.HasPermission(c, constant.PermEmploymentManage) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to view the probation of this employee")
+		return
+	}
+
+	decisions, err := h.Query.GetProbationDecisions(*emp.ID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch probation decisions: "+err.Error())
+		return
+	}
+	employmentType := defaultEmploymentType
+	if emp.EmploymentType != nil {
+		employmentType = *emp.EmploymentType
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "probation fetched successfully",
+		"employee_id": emp.ID,
+		"employment": EmployeeProbationResponse{
+			EmploymentType:   employmentType,
+			ProbationEndDate: emp.ProbationEndDate,
+			ProbationStatus:  emp.ProbationStatus,
+		},
+		"decisions": decisions,
+	})
+}
+
+// DecideProbation - POST /api/employee/:id/probation/decision
+// Confirms the employee, extends the probation to new_end_date or records
+// that it was not passed. A termination does not end the employment; start
+// the exit with POST /api/employee/:id/offboarding. Requires employment.manage
+func (h *HandlerFunc) DecideProbation(c *gin.Context) {
+	// 1️ Target and input
+	currentUserID, emp, ok := h.targetEmployee(c, true)
+	if !ok {
+		return
+	}
+	var input models.ProbationDecisionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	input.Decision = strings.ToUpper(strings.TrimSpace(input.Decision))
+	input.Note = strings.TrimSpace(input.Note)
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+
+	// 2️ Apply the decision to the current probation and record it
+	decision := models.ProbationDecision{
+		EmployeeID: *emp.ID,
+		Decision:   input.Decision,
+		Note:       input.Note,
+		DecidedBy:  &currentUserID,
+	}
+	err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		p, err := h.Query.GetEmployeeProbation(tx, *emp.ID)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch probation: "+err.Error())
+		}
+		if p.ProbationStatus == nil || *p.ProbationStatus != "ON_PROBATION" {
+			return utils.CustomErr(c, http.StatusConflict, "employee is not on probation")
+		}
+		decision.PreviousEndDate = p.ProbationEndDate
+
+		status, endDate, action := input.Decision, p.ProbationEndDate, constant.ActionApproval
+		switch input.Decision {
+		case "EXTENDED":
+			newEnd, _ := time.Parse("2006-01-02", *input.NewEndDate)
+			if p.ProbationEndDate != nil && !newEnd.After(*p.ProbationEndDate) {
+				return utils.CustomErr(c, http.StatusBadRequest, "new_end_date must be after the current probation end date")
+			}
+			status, endDate, action = "ON_PROBATION", &newEnd, constant.ActionUpdate
+			decision.NewEndDate = &newEnd
+		case "TERMINATED":
+			action = constant.ActionRejection
+		}
+
+		if err := h.Query.SetProbation(tx, *emp.ID, status, endDate); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update probation: "+err.Error())
+		}
+		if err := h.Query.CreateProbationDecision(tx, &decision); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to record decision: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentEmployment, action, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "probation decision recorded successfully",
+		"decision": decision,
+	})
+}
+
+// GetProbationsDue - GET /api/employee/probation/due?within_days=30
+// Active employees whose probation ends within the given days (default 30)
+// or has ended without a decision. Requires employment.manage
+func (h *HandlerFunc) GetProbationsDue(c *gin.Context) {
+	withinDays, err := strconv.Atoi(c.DefaultQuery("within_days", "30"))
+	if err != nil || withinDays < 0 || withinDays > 365 {
+		utils.RespondWithError(c, http.StatusBadRequest, "within_days must be between 0 and 365")
+		return
+	}
+
+	due, err := h.Query.GetProbationsDue(withinDays)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch probations: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "probations fetched successfully",
+		"within_days": withinDays,
+		"total":       len(due),
+		"probations":  due,
+	})
+}
+
+// StartProbationReminderJob emails the manager and admins once per probation
+// end date when it is at most reminderDays away, checking every interval.
+// Reminders nobody received are retried on the next check.
+func (h *HandlerFunc) StartProbationReminderJob(interval time.Duration, reminderDays int) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			h.sendProbationReminders(reminderDays)
+			<-ticker.C
+		}
+	}()
+}
+
+func (h *HandlerFunc) sendProbationReminders(reminderDays int) {
+	due, err := h.Query.ClaimProbationReminders(reminderDays)
+	if err != nil {
+		fmt.Printf("Failed to fetch probations ending soon: %v\n", err)
+		return
+	}
+	for _, p := range due {
+		recipients, err := h.Query.GetAdminAndEmployeeEmail(p.EmployeeID)
+		if err == nil {
+			err = utils.SendProbationReminderEmail(recipients, p.FullName, p.EmploymentType,
+				p.ProbationEndDate.Format("2006-01-02"), p.DaysLeft)
+		}
+		if err != nil {
+			// Not delivered: release the claim so the next run tries again
+			fmt.Printf("Failed to send probation reminder for %s: %v\n", p.FullName, err)
+			if err := h.Query.ReleaseProbationReminder(p.EmployeeID, p.ProbationEndDate); err != nil {
+				fmt.Printf("Failed to release probation reminder for %s: %v\n", p.FullName, err)
+			}
+		}
+	}
+}
+
+// GetLeaveTypeRules - GET /api/leaves/policy/:id/rules
+// Entitlements of the leave type per employment type; types without a rule
+// get the default entitlement
+func (h *HandlerFunc) GetLeaveTypeRules(c *gin.Context) {
+	leaveTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid leave type ID")
+		return
+	}
+	leaveType, err := h.Query.GetLeaveTypeById(leaveTypeID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "leave type not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch leave type: "+err.Error())
+		return
+	}
+
+	rules, err := h.Query.GetLeaveTypeRules(leaveTypeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch leave rules: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "leave rules fetched successfully",
+		"leave_type_id":       leaveTypeID,
+		"default_entitlement": leaveType.DefaultEntitlement,
+		"rules":               rules,
+	})
+}
+
+// UpdateLeaveTypeRules - PUT /api/leaves/admin-update/policy/:id/rules
+// Replaces the leave type's rules per employment type. Balances already
+// opened this year keep their entitlement. Requires leave.policy.update
+func (h *HandlerFunc) UpdateLeaveTypeRules(c *gin.Context) {
+	// 1️ Current user, leave type and input
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	leaveTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid leave type ID")
+		return
+	}
+	var input struct {
+		Rules []models.LeaveTypeRuleInput `json:"rules" validate:"max=50,dive"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	rules := make([]models.LeaveTypeRule, 0, len(input.Rules))
+	seen := map[string]bool{}
+	for _, r := range input.Rules {
+		code := strings.ToUpper(strings.TrimSpace(r.EmploymentType))
+		if seen[code] {
+			utils.RespondWithError(c, http.StatusBadRequest, "more than one rule for employment type "+code)
+			return
+		}
+		seen[code] = true
+		rules = append(rules, models.LeaveTypeRule{
+			LeaveTypeID:        leaveTypeID,
+			EmploymentType:     code,
+			Entitlement:        *r.Entitlement,
+			AllowedOnProbation: r.AllowedOnProbation == nil || *r.AllowedOnProbation,
+		})
+	}
+
+	// 2️ Replace the rules
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if _, err := h.Query.GetLeaveTypeByIdTx(tx, leaveTypeID); err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "leave type not found")
+		} else if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch leave type: "+err.Error())
+		}
+		for _, r := range rules {
+			if _, err := h.Query.GetEmploymentType(tx, r.EmploymentType); err == sql.ErrNoRows {
+				return utils.CustomErr(c, http.StatusBadRequest, "unknown employment type "+r.EmploymentType)
+			} else if err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError, "failed to check employment type: "+err.Error())
+			}
+		}
+		if err := h.Query.ReplaceLeaveTypeRules(tx, leaveTypeID, rules); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update leave rules: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentLeaveType, constant.ActionUpdate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "leave rules updated successfully",
+		"leave_type_id": leaveTypeID,
+		"rules":         rules,
+	})
+}
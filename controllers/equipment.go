@@ -17,14 +17,7 @@ import (
 // Create Equipment Category
 // ======================
 func (h *HandlerFunc) CreateCategory(c *gin.Context) {
-	// 1️ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can create categories")
-		return
-	}
-
-	// 2️ Get Employee ID for logging
+	// 1️ Get Employee ID for logging
 	empIDRaw, ok := c.Get("user_id")
 	if !ok {
 		utils.RespondWithError(c, http.StatusUnauthorized, "employee ID missing")
@@ -41,7 +34,7 @@ func (h *HandlerFunc) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	// 3️ Bind JSON and validate
+	// 2️ Bind JSON and validate
 	var req models.EquipmentCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
@@ -52,7 +45,7 @@ func (h *HandlerFunc) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	// 4️ Execute transaction
+	// 3️ Execute transaction
 	if err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
 		if err := h.Query.CreateCategory(tx, req); err != nil {
 			return utils.CustomErr(c, http.StatusInternalServerError, "failed to create category: "+err.Error())
@@ -76,14 +69,7 @@ func (h *HandlerFunc) CreateCategory(c *gin.Context) {
 // Get All Equipment Categories
 // ======================
 func (h *HandlerFunc) GetAllCategory(c *gin.Context) {
-	// 1️ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can view categories")
-		return
-	}
-
-	// 2️ Fetch categories
+	// 1️ Fetch categories
 	data, err := h.Query.GetAllCategory()
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get categories: "+err.Error())
@@ -100,14 +86,7 @@ func (h *HandlerFunc) GetAllCategory(c *gin.Context) {
 // Delete Equipment Category
 // ======================
 func (h *HandlerFunc) DeleteCategory(c *gin.Context) {
-	// 1️ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can delete categories")
-		return
-	}
-
-	// 2️ Get Category ID from URL param
+	// 1️ Get Category ID from URL param
 	idStr := c.Param("id")
 	categoryID, err := uuid.Parse(idStr)
 	if err != nil {
@@ -115,7 +94,7 @@ func (h *HandlerFunc) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	// 3️ Get Employee ID for logging
+	// 2️ Get Employee ID for logging
 	empIDRaw, ok := c.Get("user_id")
 	if !ok {
 		utils.RespondWithError(c, http.StatusUnauthorized, "employee ID missing")
@@ -132,7 +111,7 @@ func (h *HandlerFunc) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	// 4️ Execute deletion in transaction
+	// 3️ Execute deletion in transaction
 	if err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
 		if err := h.Query.DeleteCategory(tx, categoryID); err != nil {
 			return utils.CustomErr(c, http.StatusInternalServerError, "failed to delete category: "+err.Error())
@@ -153,14 +132,7 @@ func (h *HandlerFunc) DeleteCategory(c *gin.Context) {
 }
 
 func (h *HandlerFunc) UpdateCategory(c *gin.Context) {
-	// 1️ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can update categories")
-		return
-	}
-
-	// 2️ Get category ID from URL param
+	// 1️ Get category ID from URL param
 	idStr := c.Param("id")
 	categoryID, err := uuid.Parse(idStr)
 	if err != nil {
@@ -168,7 +140,7 @@ func (h *HandlerFunc) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	// 3️ Get employee ID for logging
+	// 2️ Get employee ID for logging
 	empIDRaw, ok := c.Get("user_id")
 	if !ok {
 		utils.RespondWithError(c, http.StatusUnauthorized, "employee ID missing")
@@ -185,7 +157,7 @@ func (h *HandlerFunc) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	// 4️ Bind JSON + validate
+	// 3️ Bind JSON + validate
 	var req models.EquipmentCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
@@ -196,7 +168,7 @@ func (h *HandlerFunc) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	// 5️ Execute transaction
+	// 4️ Execute transaction
 	if err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
 		if err := h.Query.UpdateCategory(tx, categoryID, req); err != nil {
 			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update category: "+err.Error())
@@ -213,7 +185,7 @@ func (h *HandlerFunc) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	// 6️ Success response
+	// 5️ Success response
 	c.JSON(http.StatusOK, gin.H{
 		"message": "category updated successfully",
 	})
@@ -222,13 +194,6 @@ func (h *HandlerFunc) UpdateCategory(c *gin.Context) {
 // CreateEquipment
 
 func (h *HandlerFunc) CreateEquipment(c *gin.Context) {
-	// Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can create equipment")
-		return
-	}
-
 	// Get employee ID for logging
 	empIDRaw, ok := c.Get("user_id")
 	if !ok {
@@ -280,20 +245,13 @@ func (h *HandlerFunc) CreateEquipment(c *gin.Context) {
 }
 
 // GetEquipmentByCategory fetches all equipment for a specific category.
+// Requires equipment.view
 // Steps:
-// 1. Check permissions (SUPERADMIN, ADMIN only)
-// 2. Get category ID from query params
-// 3. Call repository to fetch equipment by category
-// 4. Return response
+// 1. Get category ID from query params
+// 2. Call repository to fetch equipment by category
+// 3. Return response
 func (h *HandlerFunc) GetEquipmentByCategory(c *gin.Context) {
-	// 1️ Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN and SUPERADMIN can view equipment")
-		return
-	}
-
-	// 2️ Get category ID from  parameter
+	// 1️ Get category ID from  parameter
 	categoryIDStr := c.Query("id")
 	if categoryIDStr == "" {
 		utils.RespondWithError(c, http.StatusBadRequest, "category_id query parameter is required")
@@ -306,19 +264,19 @@ func (h *HandlerFunc) GetEquipmentByCategory(c *gin.Context) {
 		return
 	}
 
-	// 3️ Fetch equipment by category from repository
+	// 2️ Fetch equipment by category from repository
 	data, err := h.Query.GetEquipmentByCategory(categoryID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get equipment: "+err.Error())
 		return
 	}
 
-	// 4️ Ensure we return empty array instead of null when no data
+	// 3️ Ensure we return empty array instead of null when no data
 	if data == nil {
 		data = []models.EquipmentRes{}
 	}
 
-	// 5️ Return success response
+	// 4️ Return success response
 	c.JSON(http.StatusOK, gin.H{
 		"message":   "success",
 		"equipment": data,
@@ -326,14 +284,8 @@ func (h *HandlerFunc) GetEquipmentByCategory(c *gin.Context) {
 }
 
 // GetAllEquipment fetches all equipment.
-// Permission: SUPERADMIN, ADMIN only
+// Permission: equipment.view
 func (h *HandlerFunc) GetAllEquipment(c *gin.Context) {
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN and SUPERADMIN can view equipment")
-		return
-	}
-
 	data, err := h.Query.GetAllEquipment()
 	fmt.Println("data", data)
 	if err != nil {
@@ -353,13 +305,6 @@ func (h *HandlerFunc) GetAllEquipment(c *gin.Context) {
 }
 
 func (h *HandlerFunc) UpdateEquipment(c *gin.Context) {
-	// Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can update equipment")
-		return
-	}
-
 	// Get equipment ID from URL
 	idStr := c.Param("id")
 	equipmentID, err := uuid.Parse(idStr)
@@ -419,13 +364,6 @@ func (h *HandlerFunc) UpdateEquipment(c *gin.Context) {
 }
 
 func (h *HandlerFunc) DeleteEquipment(c *gin.Context) {
-	// Permission check
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN, SUPERADMIN, and HR can delete equipment")
-		return
-	}
-
 	// Get equipment ID from URL
 	idStr := c.Param("id")
 	equipmentID, err := uuid.Parse(idStr)
@@ -474,11 +412,6 @@ func (h *HandlerFunc) DeleteEquipment(c *gin.Context) {
 }
 
 func (h *HandlerFunc) AssignEquipment(c *gin.Context) {
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "access denied")
-		return
-	}
 	empIDRaw, ok := c.Get("user_id")
 	if !ok {
 		utils.RespondWithError(c, http.StatusUnauthorized, "employee ID missing")
@@ -527,11 +460,6 @@ func (h *HandlerFunc) AssignEquipment(c *gin.Context) {
 }
 
 func (h *HandlerFunc) GetAllAssignedEquipment(c *gin.Context) {
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "access denied")
-		return
-	}
 	data, err := h.Query.GetAllAssignedEquipment()
 	fmt.Println("data", data)
 	if err != nil {
@@ -555,12 +483,6 @@ func (h *HandlerFunc) GetAssignedEquipmentByEmployee(c *gin.Context) {
 
 // RemoveEquipment removes/returns equipment from an employee
 func (h *HandlerFunc) RemoveEquipment(c *gin.Context) {
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "access denied")
-		return
-	}
-
 	// Get employee ID for logging
 	empIDRaw, ok := c.Get("user_id")
 	if !ok {
@@ -612,12 +534,6 @@ func (h *HandlerFunc) RemoveEquipment(c *gin.Context) {
 
 // UpdateAssignment handles both quantity updates and reassignments
 func (h *HandlerFunc) UpdateAssignment(c *gin.Context) {
-	role := c.GetString("role")
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, http.StatusForbidden, "access denied")
-		return
-	}
-
 	// Get employee ID for logging
 	empIDRaw, ok := c.Get("user_id")
 	if !ok {
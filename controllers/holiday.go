@@ -14,11 +14,6 @@ import (
 
 // AddHoliday handles adding a new holiday
 func (s *HandlerFunc) AddHoliday(c *gin.Context) {
-	role, _ := c.Get("role")
-	if role.(string) != "SUPERADMIN" {
-		utils.RespondWithError(c, http.StatusUnauthorized, "not permitted")
-		return
-	}
 	empIDRaw, ok := c.Get("user_id")
 	if !ok {
 		utils.RespondWithError(c, http.StatusUnauthorized, "Employee ID missing")
@@ -107,11 +102,6 @@ func (s *HandlerFunc) GetHolidays(c *gin.Context) {
 
 // DeleteHoliday removes a holiday
 func (s *HandlerFunc) DeleteHoliday(c *gin.Context) {
-	role, _ := c.Get("role")
-	if role.(string) != "SUPERADMIN" {
-		utils.RespondWithError(c, http.StatusUnauthorized, "not permitted")
-		return
-	}
 
 	id := c.Param("id")
 	if id == "" {
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// impersonationTokenTTL is short on purpose, there is no refresh token
+const impersonationTokenTTL = 30 * time.Minute
+
+// StartImpersonation - POST /api/auth/impersonate
+// Issues a token that acts as another employee. It is read-only unless
+// allow_writes is set, and every request made with it is logged with both IDs.
+func (h *HandlerFunc) StartImpersonation(c *gin.Context) {
+	// 1️ Real actor
+	actorID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	// 2️ Bind input JSON
+	var input models.ImpersonateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if input.EmployeeID == actorID {
+		utils.RespondWithError(c, http.StatusBadRequest, "cannot impersonate yourself")
+		return
+	}
+
+	// 3️ Target must be an active, non-SUPERADMIN employee
+	emp, err := h.Query.GetEmployeeByID(input.EmployeeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+	if emp.Role == "SUPERADMIN" {
+		utils.RespondWithError(c, http.StatusForbidden, "SUPERADMIN users cannot be impersonated")
+		return
+	}
+	if emp.Status != nil && *emp.Status == "deactive" {
+		utils.RespondWithError(c, http.StatusBadRequest, "cannot impersonate a deactivated employee")
+		return
+	}
+
+	// 4️ Sign the token
+	readOnly := !input.AllowWrites
+	token, err := utils.GenerateImpersonationToken(input.EmployeeID.String(), emp.Role, actorID.String(), readOnly, h.Keys, impersonationTokenTTL)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate impersonation token")
+		return
+	}
+
+	// 5️ Log against the employee, with the actor as impersonator
+	c.Set(common.ContextImpersonatorID, actorID.String())
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		data := utils.NewCommon(constant.ComponentImpersonation, constant.ActionImpersonateStart, input.EmployeeID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// 6️ Response
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "impersonation started",
+		"token":           token,
+		"expires_in":      int(impersonationTokenTTL.Seconds()),
+		"read_only":       readOnly,
+		"impersonator_id": actorID,
+		"user": gin.H{
+			"id":    emp.ID,
+			"email": emp.Email,
+			"role":  emp.Role,
+		},
+	})
+}
+
+// StopImpersonation - POST /api/auth/impersonate/stop
+// Revokes the impersonation token used to call it.
+func (h *HandlerFunc) StopImpersonation(c *gin.Context) {
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	if err := h.Query.RevokeAccessToken(c.GetString("jti"), empID, c.GetTime("token_exp")); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to revoke token: "+err.Error())
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		data := utils.NewCommon(constant.ComponentImpersonation, constant.ActionImpersonateStop, empID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "impersonation stopped",
+	})
+}
@@ -123,11 +123,20 @@ func (h *HandlerFunc) ApplyLeave(c *gin.Context) {
 			return utils.CustomErr(c, 500, "Failed to fetch leave type: "+err.Error())
 		}
 
+		// Policy of the employee's employment type
+		entitlement, err := h.Query.GetLeaveEntitlement(tx, employeeID, input.LeaveTypeID)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch leave entitlement: "+err.Error())
+		}
+		if !entitlement.Allowed {
+			return utils.CustomErr(c, 400, leaveType.Name+" leave is not available during probation")
+		}
+
 		// Leave Balance
 		balance, err := h.Query.GetLeaveBalance(tx, employeeID, input.LeaveTypeID)
 		if err == sql.ErrNoRows {
-			balance = float64(leaveType.DefaultEntitlement)
-			if err := h.Query.CreateLeaveBalance(tx, employeeID, input.LeaveTypeID, leaveType.DefaultEntitlement); err != nil {
+			balance = float64(entitlement.Entitlement)
+			if err := h.Query.CreateLeaveBalance(tx, employeeID, input.LeaveTypeID, entitlement.Entitlement); err != nil {
 				return utils.CustomErr(c, 500, "Failed to create leave balance: "+err.Error())
 			}
 		} else if err != nil {
@@ -175,9 +184,9 @@ func (h *HandlerFunc) ApplyLeave(c *gin.Context) {
 		return nil // IMPORTANT FIX
 	})
 
-	// If transaction returned an error, stop (CustomErr already responded)
+	// If transaction returned an error, answer with its status
 	if err != nil {
-		utils.RespondWithError(c, 500, "Failed to update settings: "+err.Error())
+		utils.RespondWithTxError(c, err)
 		return
 	}
 
@@ -237,16 +246,6 @@ func (s *HandlerFunc) AdminAddLeavePolicy(c *gin.Context) {
 		utils.RespondWithError(c, http.StatusInternalServerError, "Invalid employee UUID")
 		return
 	}
-	roleValue, exists := c.Get("role")
-	if !exists {
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get role")
-		return
-	}
-	userRole := roleValue.(string)
-	if userRole != "SUPERADMIN" {
-		utils.RespondWithError(c, http.StatusUnauthorized, "not permitted to assign manager")
-		return
-	}
 	var input models.LeaveTypeInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -321,13 +320,8 @@ func (s *HandlerFunc) GetAllLeavePolicies(c *gin.Context) {
 // 1. MANAGER rejects → Status: MANAGER_REJECTED (pending final rejection)
 // 2. ADMIN/SUPERADMIN finalizes → Status: REJECTED (final rejection)
 func (s *HandlerFunc) ActionLeave(c *gin.Context) {
-	roleRaw, _ := c.Get("role")
-	role := roleRaw.(string)
-
-	if role == "EMPLOYEE" {
-		utils.RespondWithError(c, 403, "Employees cannot approve leaves")
-		return
-	}
+	// leave.approve acts as final approver, leave.approve_team only on direct reports
+	isFinalApprover := s.HasPermission(c, constant.PermLeaveApprove)
 
 	approverIDRaw, _ := c.Get("user_id")
 	approverID, _ := uuid.Parse(approverIDRaw.(string))
@@ -352,7 +346,7 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 		return
 	}
 
-	tx, err := s.Query.DB.Beginx()
+	tx, err := common.BeginTx(c, s.Query.DB)
 	if err != nil {
 		utils.RespondWithError(c, 500, "Failed to start transaction")
 		return
@@ -372,8 +366,8 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 		return
 	}
 
-	//  MANAGER validation
-	if role == "MANAGER" {
+	//  MANAGER (team approver) validation
+	if !isFinalApprover {
 		// Check manager permission setting
 		exists, err := s.Query.ChackManagerPermission()
 		if err != nil {
@@ -405,8 +399,8 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 		}
 	}
 
-	//  ADMIN/SUPERADMIN validation
-	if role == "ADMIN" || role == "SUPERADMIN" {
+	//  ADMIN/SUPERADMIN (final approver) validation
+	if isFinalApprover {
 		// Admin can act on Pending, MANAGER_APPROVED, or MANAGER_REJECTED leaves
 		if leave.Status != "Pending" && leave.Status != "MANAGER_APPROVED" && leave.Status != "MANAGER_REJECTED" {
 			utils.RespondWithError(c, 400, fmt.Sprintf("Cannot process leave with status: %s", leave.Status))
@@ -419,7 +413,7 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 	// ========================================
 	if body.Action == "REJECT" {
 		// MANAGER REJECTION (First Level)
-		if role == "MANAGER" {
+		if !isFinalApprover {
 			_, err = tx.Exec(`UPDATE Tbl_Leave SET status='MANAGER_REJECTED', approved_by=$2, updated_at=NOW() WHERE id=$1`, leaveID, approverID)
 			if err != nil {
 				utils.RespondWithError(c, 500, "Failed to reject leave: "+err.Error())
@@ -442,7 +436,6 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 			if err != nil {
 				fmt.Printf("Failed to get admin and employee emails for notification: %v\n", err)
 			}
-			recipients = append(recipients)
 
 			tx.Commit()
 
@@ -470,7 +463,7 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 		}
 
 		// ADMIN/SUPERADMIN FINAL REJECTION (Second Level)
-		if role == "ADMIN" || role == "SUPERADMIN" {
+		if isFinalApprover {
 			_, err = tx.Exec(`UPDATE Tbl_Leave SET status='REJECTED', approved_by=$2, updated_at=NOW() WHERE id=$1`, leaveID, approverID)
 			if err != nil {
 				utils.RespondWithError(c, 500, "Failed to finalize leave rejection: "+err.Error())
@@ -494,7 +487,6 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 			if err != nil {
 				fmt.Printf("Failed to get admin and employee emails for notification: %v\n", err)
 			}
-			recipients = append(recipients)
 
 			tx.Commit()
 
@@ -550,7 +542,7 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 	}
 
 	// MANAGER APPROVAL (First Level)
-	if role == "MANAGER" {
+	if !isFinalApprover {
 		_, err = tx.Exec(`UPDATE Tbl_Leave SET status='MANAGER_APPROVED', approved_by=$2, updated_at=NOW() WHERE id=$1`, leaveID, approverID)
 		if err != nil {
 			utils.RespondWithError(c, 500, "Failed to approve leave: "+err.Error())
@@ -600,7 +592,7 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 	}
 
 	// ADMIN/SUPERADMIN FINAL APPROVAL (Second Level)
-	if role == "ADMIN" || role == "SUPERADMIN" {
+	if isFinalApprover {
 		// Update status to APPROVED
 		_, err = tx.Exec(`UPDATE Tbl_Leave SET status='APPROVED', approved_by=$2, updated_at=NOW() WHERE id=$1`, leaveID, approverID)
 		if err != nil {
@@ -680,21 +672,24 @@ func (h *HandlerFunc) GetAllLeaves(c *gin.Context) {
 		utils.RespondWithError(c, http.StatusBadRequest, "Invalid user ID format: "+err.Error())
 		return
 	}
-	// 2️ Execute query based on role
+	// 1.5️ Optional department filter, sub-departments included
+	departmentID, err := departmentFilter(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	// 2️ Execute query based on permissions
 	var result []models.LeaveResponse
-	switch role {
-	case constant.ROLE_EMPLOYEE:
-		// Employees can only see their own leaves
-		result, err = h.Query.GetAllEmployeeLeave(userID)
-	case constant.ROLE_MANAGER:
-		// Manager can see: their own leaves + their team members' leaves
-		result, err = h.Query.GetAllleavebaseonassignManager(userID)
-	case constant.ROLE_ADMIN, constant.ROLE_HR, constant.ROLE_SUPER_ADMIN:
-		result, err = h.Query.GetAllLeave()
+	switch {
+	case h.HasPermission(c, constant.PermLeaveViewAll):
 		// HR, Admin and SuperAdmin can see all leaves
+		result, err = h.Query.GetAllLeave(departmentID)
+	case h.HasPermission(c, constant.PermLeaveViewTeam):
+		// Manager can see: their own leaves + their team members' leaves
+		result, err = h.Query.GetAllleavebaseonassignManager(userID, departmentID)
 	default:
-		utils.RespondWithError(c, http.StatusForbidden, "Invalid role: "+role)
-		return
+		// Employees can only see their own leaves
+		result, err = h.Query.GetAllEmployeeLeave(userID)
 	}
 	// 3️ Handle query errors
 	if err != nil {
@@ -723,9 +718,6 @@ func (h *HandlerFunc) CancelLeave(c *gin.Context) {
 	userIDRaw, _ := c.Get("user_id")
 	userID, _ := uuid.Parse(userIDRaw.(string))
 
-	roleRaw, _ := c.Get("role")
-	role := roleRaw.(string)
-
 	// Parse leave ID from URL
 	leaveID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -734,7 +726,7 @@ func (h *HandlerFunc) CancelLeave(c *gin.Context) {
 	}
 
 	// Start transaction
-	tx, err := h.Query.DB.Beginx()
+	tx, err := common.BeginTx(c, h.Query.DB)
 	if err != nil {
 		utils.RespondWithError(c, 500, "Failed to start transaction")
 		return
@@ -749,8 +741,8 @@ func (h *HandlerFunc) CancelLeave(c *gin.Context) {
 		return
 	}
 
-	// Permission check - employees can only cancel their own leaves
-	if role == "EMPLOYEE" && leave.EmployeeID != userID {
+	// Permission check - without leave.cancel_any only own leaves can be cancelled
+	if leave.EmployeeID != userID && !h.HasPermission(c, constant.PermLeaveCancelAny) {
 		utils.RespondWithError(c, 403, "You can only cancel your own leave applications")
 		return
 	}
@@ -804,14 +796,11 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 	currentUserIDRaw, _ := c.Get("user_id")
 	currentUserID, _ := uuid.Parse(currentUserIDRaw.(string))
 
-	// 2️⃣ Permission check - Only Admin, SUPERADMIN, and Manager can withdraw
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "MANAGER" {
-		utils.RespondWithError(c, 403, "only SUPERADMIN, ADMIN, and MANAGER can withdraw approved leaves")
-		return
-	}
+	// 2️⃣ leave.withdraw finalizes, leave.withdraw_team only requests withdrawal for direct reports
+	isFinalWithdrawer := h.HasPermission(c, constant.PermLeaveWithdraw)
 
 	// 2️⃣A Check if MANAGER has permission to withdraw leaves
-	if role == "MANAGER" {
+	if !isFinalWithdrawer {
 		hasPermission, err := h.Query.ChackManagerPermission()
 		if err != nil {
 			utils.RespondWithError(c, http.StatusInternalServerError, "failed to check manager permission")
@@ -838,7 +827,7 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 	c.ShouldBindJSON(&input)
 
 	// 5️⃣ Start transaction
-	tx, err := h.Query.DB.Beginx()
+	tx, err := common.BeginTx(c, h.Query.DB)
 	if err != nil {
 		utils.RespondWithError(c, 500, "failed to start transaction")
 		return
@@ -869,7 +858,7 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 	}
 
 	// 8️⃣ MANAGER validation
-	if role == "MANAGER" {
+	if !isFinalWithdrawer {
 		// Verify reporting relationship
 		var managerID uuid.UUID
 		err := tx.Get(&managerID, "SELECT manager_id FROM Tbl_Employee WHERE id=$1", leave.EmployeeID)
@@ -890,7 +879,7 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 	}
 
 	// 9️⃣ ADMIN/SUPERADMIN validation
-	if role == "ADMIN" || role == "SUPERADMIN" {
+	if isFinalWithdrawer {
 		// Admin can act on APPROVED or WITHDRAWAL_PENDING leaves
 		if leave.Status != "APPROVED" && leave.Status != "WITHDRAWAL_PENDING" {
 			utils.RespondWithError(c, 400, fmt.Sprintf("cannot withdraw leave with status: %s", leave.Status))
@@ -901,7 +890,7 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 	// ========================================
 	// MANAGER WITHDRAWAL REQUEST (First Level)
 	// ========================================
-	if role == "MANAGER" {
+	if !isFinalWithdrawer {
 		withdrawalReason := input.Reason
 		if withdrawalReason == "" {
 			withdrawalReason = "Withdrawal requested by Manager"
@@ -970,7 +959,7 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 	// ========================================
 	// ADMIN/SUPERADMIN FINAL WITHDRAWAL (Second Level)
 	// ========================================
-	if role == "ADMIN" || role == "SUPERADMIN" {
+	if isFinalWithdrawer {
 		withdrawalReason := input.Reason
 		if withdrawalReason == "" {
 			withdrawalReason = fmt.Sprintf("Withdrawn by %s", role)
@@ -1092,13 +1081,7 @@ func (h *HandlerFunc) GetManagerLeaveHistory(c *gin.Context) {
 		return
 	}
 
-	// 2️⃣ Permission check - Only MANAGER can use this endpoint
-	if role != "MANAGER" {
-		utils.RespondWithError(c, http.StatusForbidden, "Only managers can access team leave history")
-		return
-	}
-
-	// 3️⃣ Query to get team members' leave history
+	// 2️⃣ Query to get team members' leave history
 	query := `
 		SELECT 
 			l.id,
@@ -1121,7 +1104,7 @@ func (h *HandlerFunc) GetManagerLeaveHistory(c *gin.Context) {
 		ORDER BY l.created_at DESC
 	`
 
-	// 4️⃣ Execute query with proper error handling
+	// 3️⃣ Execute query with proper error handling
 	var result []models.LeaveResponse
 	err = h.Query.DB.Select(&result, query, currentUserID)
 	if err != nil {
@@ -1133,12 +1116,12 @@ func (h *HandlerFunc) GetManagerLeaveHistory(c *gin.Context) {
 		return
 	}
 
-	// 5️⃣ Handle empty result
+	// 4️⃣ Handle empty result
 	if result == nil {
 		result = []models.LeaveResponse{}
 	}
 
-	// 6️⃣ Response with metadata
+	// 5️⃣ Response with metadata
 	c.JSON(http.StatusOK, gin.H{
 		"message":      "Team leave history fetched successfully",
 		"manager_id":   currentUserID,
@@ -1153,7 +1136,6 @@ func (h *HandlerFunc) GetLeaveByID(c *gin.Context) {
 	// Get user info from middleware
 	userIDRaw, _ := c.Get("user_id")
 	userID, _ := uuid.Parse(userIDRaw.(string))
-	role := c.GetString("role")
 
 	// Parse leave ID from URL
 	leaveID, err := uuid.Parse(c.Param("id"))
@@ -1204,14 +1186,11 @@ func (h *HandlerFunc) GetLeaveByID(c *gin.Context) {
 		return
 	}
 
-	// Role-based access control
-	switch role {
-	case "EMPLOYEE":
-		if leaveEmployeeID != userID {
-			utils.RespondWithError(c, 403, "You can only view your own leave applications")
-			return
-		}
-	case "MANAGER":
+	// Permission-based access control
+	switch {
+	case h.HasPermission(c, constant.PermLeaveViewAll):
+		// HR, Admin and SuperAdmin can see all leaves - no additional check needed
+	case h.HasPermission(c, constant.PermLeaveViewTeam):
 		// Manager can see their own leaves + their team members' leaves
 		var managerID uuid.UUID
 		err = h.Query.DB.Get(&managerID, "SELECT COALESCE(manager_id, '00000000-0000-0000-0000-000000000000') FROM Tbl_Employee WHERE id = $1", leaveEmployeeID)
@@ -1223,11 +1202,11 @@ func (h *HandlerFunc) GetLeaveByID(c *gin.Context) {
 			utils.RespondWithError(c, 403, "You can only view leaves of your team members or your own leaves")
 			return
 		}
-	case "HR", "ADMIN", "SUPERADMIN":
-		// HR, Admin and SuperAdmin can see all leaves - no additional check needed
 	default:
-		utils.RespondWithError(c, 403, "Invalid role")
-		return
+		if leaveEmployeeID != userID {
+			utils.RespondWithError(c, 403, "You can only view your own leave applications")
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -1241,7 +1220,7 @@ func (h *HandlerFunc) GetLeaveByID(c *gin.Context) {
 // SuperAdmin & Admin can view all leave timing variants
 func (h *HandlerFunc) GetLeaveTiming(c *gin.Context) {
 
-	// 2 Fetch from DB
+	// 1 Fetch from DB
 	data, err := h.Query.GetLeaveTiming()
 	if err != nil {
 		fmt.Printf("GetLeaveTiming DB Error: %v\n", err)
@@ -1254,7 +1233,7 @@ func (h *HandlerFunc) GetLeaveTiming(c *gin.Context) {
 		data = []models.LeaveTimingResponse{}
 	}
 
-	// 3 Response
+	// 2 Response
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Leave timing fetched successfully",
 		"total":   len(data),
@@ -1265,27 +1244,20 @@ func (h *HandlerFunc) GetLeaveTiming(c *gin.Context) {
 // GetLeaveTimingByID - GET /api/leave-timing/:id
 func (h *HandlerFunc) GetLeaveTimingByID(c *gin.Context) {
 
-	// 1️ Role validation
-	role := c.GetString("role")
-	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN {
-		utils.RespondWithError(c, http.StatusForbidden, "Access denied")
-		return
-	}
-
-	// 2️ Bind URI
+	// 1️ Bind URI
 	var req models.GetLeaveTimingByIDReq
 	if err := c.ShouldBindUri(&req); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// 3️ Validate
+	// 2️ Validate
 	if err := models.Validate.Struct(req); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// 4️ Fetch data
+	// 3️ Fetch data
 	data, err := h.Query.GetLeaveTimingByID(req.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -1296,7 +1268,7 @@ func (h *HandlerFunc) GetLeaveTimingByID(c *gin.Context) {
 		return
 	}
 
-	// 5️ Response
+	// 4️ Response
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Leave timing fetched successfully",
 		"data":    data,
@@ -1305,14 +1277,7 @@ func (h *HandlerFunc) GetLeaveTimingByID(c *gin.Context) {
 
 func (h *HandlerFunc) UpdateLeaveTiming(c *gin.Context) {
 
-	// 1️ Role validation
-	role := c.GetString("role")
-	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN {
-		utils.RespondWithError(c, http.StatusForbidden, "Access denied")
-		return
-	}
-
-	// 2️ Bind URI + Body
+	// 1️ Bind URI + Body
 	var req models.UpdateLeaveTimingReq
 
 	if err := c.ShouldBindUri(&req); err != nil {
@@ -1325,14 +1290,14 @@ func (h *HandlerFunc) UpdateLeaveTiming(c *gin.Context) {
 		return
 	}
 
-	// 3️ Validate
+	// 2️ Validate
 	if err := models.Validate.Struct(req); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
-		// 4️ Update DB
+		// 3️ Update DB
 
 		err := h.Query.UpdateLeaveTiming(tx, req.ID, req.Timing)
 		if err != nil {
@@ -1351,7 +1316,7 @@ func (h *HandlerFunc) UpdateLeaveTiming(c *gin.Context) {
 		return
 	}
 
-	// 5️ Response
+	// 4️ Response
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Leave timing updated successfully",
 	})
@@ -1379,18 +1344,6 @@ func (h *HandlerFunc) UpdateLeavePolicy(c *gin.Context) {
 		return
 	}
 
-	// Role validation
-	roleValue, exists := c.Get("role")
-	if !exists {
-		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get role")
-		return
-	}
-	userRole := roleValue.(string)
-	if userRole != "SUPERADMIN" && userRole != "ADMIN" && userRole != "HR" {
-		utils.RespondWithError(c, http.StatusUnauthorized, "Not permitted to update leave policy")
-		return
-	}
-
 	// Parse leave type ID from URL
 	leaveTypeIDStr := c.Param("id")
 	leaveTypeID, err := strconv.Atoi(leaveTypeIDStr)
@@ -1481,18 +1434,6 @@ func (h *HandlerFunc) DeleteLeavePolicy(c *gin.Context) {
 		return
 	}
 
-	// Role validation
-	roleValue, exists := c.Get("role")
-	if !exists {
-		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get role")
-		return
-	}
-	userRole := roleValue.(string)
-	if userRole != "SUPERADMIN" && userRole != "ADMIN" && userRole != "HR" {
-		utils.RespondWithError(c, http.StatusUnauthorized, "Not permitted to delete leave policy")
-		return
-	}
-
 	// Parse leave type ID from URL
 	leaveTypeIDStr := c.Param("id")
 	leaveTypeID, err := strconv.Atoi(leaveTypeIDStr)
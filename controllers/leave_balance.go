@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
 )
 
 // GetLeaveBalances - GET /api/employees/:id/leave-balances
@@ -21,13 +22,11 @@ func (s *HandlerFunc) GetLeaveBalances(c *gin.Context) {
 		return
 	}
 
-	// 2. Role check (employees can only view their own balances)
-	roleRaw, _ := c.Get("role")
-	role := roleRaw.(string)
+	// 2. Permission check (without leave_balance.view_all only own balances)
 	userIDRaw, _ := c.Get("user_id")
 	userID, _ := uuid.Parse(userIDRaw.(string))
 
-	if role == "EMPLOYEE" && userID != employeeID {
+	if userID != employeeID && !s.HasPermission(c, constant.PermLeaveBalanceView) {
 		utils.RespondWithError(c, http.StatusForbidden, "Employees can only view their own balances")
 		return
 	}
@@ -46,11 +45,15 @@ func (s *HandlerFunc) GetLeaveBalances(c *gin.Context) {
 	SELECT 
 		lt.name AS leave_type,
 		COALESCE(b.used, 0) AS used,
-		lt.default_entitlement AS total,
-		COALESCE(b.closing, lt.default_entitlement) AS available
+		COALESCE(r.entitlement, lt.default_entitlement) AS total,
+		COALESCE(b.closing, r.entitlement, lt.default_entitlement) AS available
 	FROM Tbl_Leave_Type lt
 	LEFT JOIN Tbl_Leave_balance b 
 		ON lt.id = b.leave_type_id AND b.employee_id = $1
+	-- Entitlement of the employee's employment type, if the leave type has one
+	LEFT JOIN Tbl_Employee e ON e.id = $1
+	LEFT JOIN tbl_leave_type_rule r
+		ON r.leave_type_id = lt.id AND r.employment_type = e.employment_type
 	ORDER BY lt.id
 `
 
@@ -71,15 +74,7 @@ func (s *HandlerFunc) GetLeaveBalances(c *gin.Context) {
 // AdjustLeaveBalance - POST /api/leave-balances/adjust
 // AdjustLeaveBalance - POST /api/leave-balances/:id/adjust
 func (s *HandlerFunc) AdjustLeaveBalance(c *gin.Context) {
-	// 1️ Role check - Only ADMIN/HR allowed
-	roleRaw, _ := c.Get("role")
-	role := roleRaw.(string)
-	if role != "ADMIN" && role != "SUPERADMIN" && role != "HR" {
-		utils.RespondWithError(c, 403, "Not authorized to adjust leave balances")
-		return
-	}
-
-	// 2️ Get employee ID from params
+	// 1️ Get employee ID from params
 	employeeIDParam := c.Param("id")
 	employeeID, err := uuid.Parse(employeeIDParam)
 	if err != nil {
@@ -87,7 +82,7 @@ func (s *HandlerFunc) AdjustLeaveBalance(c *gin.Context) {
 		return
 	}
 
-	// 3️ Parse JSON input
+	// 2️ Parse JSON input
 	var input struct {
 		LeaveTypeID int     `json:"leave_type_id" validate:"required"`
 		Quantity    float64 `json:"quantity" validate:"required"` // +ve or -ve
@@ -100,7 +95,7 @@ func (s *HandlerFunc) AdjustLeaveBalance(c *gin.Context) {
 
 	currentYear := time.Now().Year()
 
-	// 4️ Start transaction
+	// 3️ Start transaction
 	tx, err := s.Query.DB.Beginx()
 	if err != nil {
 		utils.RespondWithError(c, 500, "Failed to start transaction")
@@ -108,7 +103,7 @@ func (s *HandlerFunc) AdjustLeaveBalance(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// 5️ Fetch or create leave balance
+	// 4️ Fetch or create leave balance
 	var balance struct {
 		ID          uuid.UUID `db:"id"`
 		Opening     float64   `db:"opening"`
@@ -139,15 +134,15 @@ func (s *HandlerFunc) AdjustLeaveBalance(c *gin.Context) {
     `, employeeID, input.LeaveTypeID, currentYear)
 
 	if err == sql.ErrNoRows {
-		// 5A: Fetch default entitlement
-		var defaultEntitlement float64
-		err = tx.Get(&defaultEntitlement, `SELECT default_entitlement FROM Tbl_Leave_Type WHERE id=$1`, input.LeaveTypeID)
+		// 4A: Fetch the entitlement of the employee's employment type
+		entitlement, err := s.Query.GetLeaveEntitlement(tx, employeeID, input.LeaveTypeID)
 		if err != nil {
 			utils.RespondWithError(c, 500, "Failed to fetch leave type: "+err.Error())
 			return
 		}
+		defaultEntitlement := float64(entitlement.Entitlement)
 
-		// 5B: Create balance row
+		// 4B: Create balance row
 		err = tx.QueryRow(`
             INSERT INTO Tbl_Leave_balance
             (employee_id, leave_type_id, year, opening, accrued, used, adjusted, closing, created_at, updated_at)
@@ -166,7 +161,7 @@ func (s *HandlerFunc) AdjustLeaveBalance(c *gin.Context) {
 		return
 	}
 
-	// 6️ Apply adjustment
+	// 5️ Apply adjustment
 	newAdjusted := balance.Adjusted + input.Quantity
 	newClosing := balance.Opening + balance.Accrued - balance.Used + newAdjusted
 
@@ -181,7 +176,7 @@ func (s *HandlerFunc) AdjustLeaveBalance(c *gin.Context) {
 		return
 	}
 
-	// 7️ Insert into adjustment log
+	// 6️ Insert into adjustment log
 	_, err = tx.Exec(`
         INSERT INTO Tbl_Leave_adjustment
         (employee_id, leave_type_id, quantity, reason, created_by, created_at, year)
@@ -193,7 +188,7 @@ func (s *HandlerFunc) AdjustLeaveBalance(c *gin.Context) {
 		return
 	}
 
-	// 8️ Commit
+	// 7️ Commit
 	if err := tx.Commit(); err != nil {
 		utils.RespondWithError(c, 500, "Transaction commit failed")
 		return
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+const (
+	maxFailedLoginsPerAccount = 5  // failures before an account is locked
+	maxFailedLoginsPerIP      = 20 // higher because offices share one public IP
+	baseLockoutDuration       = time.Minute
+	maxLockoutDuration        = time.Hour
+	failedLoginResetAfter     = 24 * time.Hour // idle time after which the counter starts over
+)
+
+// dummyPasswordHash is compared against when the email is unknown so that
+// both failure paths take the same time
+const dummyPasswordHash = "$2a$10$s/61X9A/FAZhz8i8fmvm8eKnDhEbMeRcJ14vHO/rjyU0iNwSL3ic2"
+
+// lockoutDuration doubles the lock for every failure past the threshold
+func lockoutDuration(failed, threshold int) time.Duration {
+	if failed < threshold {
+		return 0
+	}
+	d := baseLockoutDuration
+	for i := threshold; i < failed && d < maxLockoutDuration; i++ {
+		d *= 2
+	}
+	if d > maxLockoutDuration {
+		d = maxLockoutDuration
+	}
+	return d
+}
+
+// loginLockRemaining returns the longest remaining lock of the email and client IP.
+// The IP is c.ClientIP(), which only follows X-Forwarded-For from
+// TRUSTED_PROXIES, so clients cannot pick a fresh IP per attempt.
+func (h *HandlerFunc) loginLockRemaining(email, ip string) (time.Duration, error) {
+	emailLock, err := h.Query.GetLoginLockRemaining(repositories.ThrottleKeyEmail, email)
+	if err != nil {
+		return 0, err
+	}
+	ipLock, err := h.Query.GetLoginLockRemaining(repositories.ThrottleKeyIP, ip)
+	if err != nil {
+		return 0, err
+	}
+	if ipLock > emailLock {
+		return ipLock, nil
+	}
+	return emailLock, nil
+}
+
+// registerFailedLogin counts a failure for the email and IP and applies
+// lockouts. Account lockouts of known employees are written to tbl_log.
+func (h *HandlerFunc) registerFailedLogin(c *gin.Context, email, ip string, empID *uuid.UUID) {
+	emailCount, err := h.Query.RecordFailedLogin(repositories.ThrottleKeyEmail, email, ip, failedLoginResetAfter)
+	if err != nil {
+		fmt.Printf("Failed to record failed login for %s: %v\n", email, err)
+	} else if d := lockoutDuration(emailCount, maxFailedLoginsPerAccount); d > 0 {
+		if err := h.Query.LockLogin(repositories.ThrottleKeyEmail, email, d); err != nil {
+			fmt.Printf("Failed to lock account %s: %v\n", email, err)
+		}
+		if empID != nil {
+			err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+				data := utils.NewCommon(constant.ComponentAuth, constant.ActionLockout, *empID)
+				return common.AddLog(data, tx)
+			})
+			if err != nil {
+				fmt.Printf("Failed to log lockout for %s: %v\n", email, err)
+			}
+		}
+	}
+
+	ipCount, err := h.Query.RecordFailedLogin(repositories.ThrottleKeyIP, ip, ip, failedLoginResetAfter)
+	if err != nil {
+		fmt.Printf("Failed to record failed login for IP %s: %v\n", ip, err)
+	} else if d := lockoutDuration(ipCount, maxFailedLoginsPerIP); d > 0 {
+		if err := h.Query.LockLogin(repositories.ThrottleKeyIP, ip, d); err != nil {
+			fmt.Printf("Failed to lock IP %s: %v\n", ip, err)
+		}
+	}
+}
+
+// UnlockEmployeeLogin - PATCH /api/employee/:id/unlock
+// Clears the failed login counter and lockout of an employee account, and the
+// lockout of the client IP the account's latest failed login came from
+func (h *HandlerFunc) UnlockEmployeeLogin(c *gin.Context) {
+	adminID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "invalid employee UUID")
+		return
+	}
+
+	empID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	emp, err := h.Query.GetEmployeeByID(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+
+	if emp.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to modify SUPERADMIN users")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.ClearAccountLoginThrottle(tx, strings.ToLower(emp.Email)); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to unlock account: "+err.Error())
+		}
+		data := utils.NewCommon(constant.ComponentAuth, constant.ActionUnlock, adminID).WithTarget(empID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "account unlocked successfully",
+		"employee_id": empID,
+	})
+}
@@ -6,20 +6,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
 )
 
-// GetLogs - only for super_admin to get logs filtered by days
+// GetLogs - get logs filtered by days and, with ?target_id=, by the employee
+// the action was taken on (requires log.view)
 func (h *HandlerFunc) GetLogs(c *gin.Context) {
-	// Check if user is SUPERADMIN
-	role, exists := c.Get("role")
-	if !exists || role != "SUPERADMIN" {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Access denied. Only SUPERADMIN can view logs",
-		})
-		return
-	}
-
 	// Get days parameter from query (default to 7 days if not provided or empty)
 	daysParam := c.Query("days")
 	days := 7 // Default value
@@ -36,6 +29,19 @@ func (h *HandlerFunc) GetLogs(c *gin.Context) {
 		days = parsedDays
 	}
 
+	// Optional target employee
+	var targetID *uuid.UUID
+	if value := c.Query("target_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid target_id parameter. Must be an employee ID",
+			})
+			return
+		}
+		targetID = &id
+	}
+
 	// Calculate the date threshold
 	dateThreshold := time.Now().AddDate(0, 0, -days)
 
@@ -46,14 +52,21 @@ func (h *HandlerFunc) GetLogs(c *gin.Context) {
 			e.full_name as user_name,
 			l.action,
 			l.component,
-			l.created_at
+			k.name as api_key,
+			i.full_name as impersonated_by,
+			l.created_at,
+			l.target_id,
+			t.full_name as target_name
 		FROM tbl_log l
 		JOIN Tbl_Employee e ON l.from_user_id = e.id
-		WHERE l.created_at >= $1
+		LEFT JOIN tbl_api_key k ON l.api_key_id = k.id
+		LEFT JOIN Tbl_Employee i ON l.impersonator_id = i.id
+		LEFT JOIN Tbl_Employee t ON l.target_id = t.id
+		WHERE l.created_at >= $1 AND ($2::uuid IS NULL OR l.target_id = $2)
 		ORDER BY l.created_at DESC
 	`
 
-	rows, err := h.Query.DB.Query(query, dateThreshold)
+	rows, err := h.Query.DB.Query(query, dateThreshold, targetID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to fetch logs",
@@ -70,7 +83,11 @@ func (h *HandlerFunc) GetLogs(c *gin.Context) {
 			&log.UserName,
 			&log.Action,
 			&log.Component,
+			&log.APIKey,
+			&log.ImpersonatedBy,
 			&log.CreatedAt,
+			&log.TargetID,
+			&log.TargetName,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -0,0 +1,537 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/fieldcrypt"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+const (
+	mfaIssuer         = "Zenithive"
+	mfaTokenTTL       = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// mfaChallenge decides whether Login must stop after the password check.
+// It returns the response to send instead of the real tokens (nil when no
+// second factor applies) and false if it already responded with an error.
+func (h *HandlerFunc) mfaChallenge(c *gin.Context, empIDStr, role string) (gin.H, bool) {
+	empID, err := uuid.Parse(empIDStr)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Invalid employee ID")
+		return nil, false
+	}
+
+	mfa, err := h.Query.GetEmployeeMFA(empID)
+	if err != nil && err != sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to check two-factor settings")
+		return nil, false
+	}
+
+	purpose := ""
+	if err == nil && mfa.Enabled {
+		purpose = utils.TokenPurposeMFA
+	} else {
+		requiredRoles, err := h.Query.GetMFARequiredRoles()
+		if err != nil && err != sql.ErrNoRows {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to check two-factor settings")
+			return nil, false
+		}
+		if slices.Contains(requiredRoles, role) {
+			purpose = utils.TokenPurposeMFAEnroll
+		}
+	}
+	if purpose == "" {
+		return nil, true
+	}
+
+	token, err := utils.GeneratePurposeToken(empIDStr, role, purpose, h.Keys, mfaTokenTTL)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate authentication token")
+		return nil, false
+	}
+
+	if purpose == utils.TokenPurposeMFA {
+		return gin.H{
+			"success":      true,
+			"message":      "Enter the code from your authenticator app",
+			"mfa_required": true,
+			"mfa_token":    token,
+			"expires_in":   int(mfaTokenTTL.Seconds()),
+		}, true
+	}
+	return gin.H{
+		"success":                 true,
+		"message":                 "Two-factor authentication is required for your role. Please set it up to continue",
+		"mfa_enrollment_required": true,
+		"mfa_token":               token,
+		"expires_in":              int(mfaTokenTTL.Seconds()),
+	}, true
+}
+
+// mfaCryptContext binds an encrypted TOTP secret to its employee
+func mfaCryptContext(empID uuid.UUID) string {
+	return "tbl_employee_mfa:" + empID.String() + ":secret"
+}
+
+// mfaSecret decrypts the stored TOTP secret
+func (h *HandlerFunc) mfaSecret(mfa repositories.EmployeeMFA) (string, error) {
+	return h.FieldCrypt.Decrypt(mfa.Secret, mfaCryptContext(mfa.EmployeeID))
+}
+
+// checkMFACode accepts a current TOTP code (each time step only once) or an
+// unused recovery code
+func (h *HandlerFunc) checkMFACode(empID uuid.UUID, secret, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if step, ok := utils.ValidateTOTP(secret, code, time.Now()); ok {
+		return h.Query.UseTOTPStep(empID, step)
+	}
+
+	recovery := utils.NormalizeRecoveryCode(code)
+	if recovery == "" {
+		return false, nil
+	}
+	return h.Query.UseRecoveryCode(empID, utils.HashToken(recovery))
+}
+
+// VerifyMFA - POST /api/auth/mfa/verify
+// Second login step: exchanges the MFA challenge token and a code for the real tokens
+func (h *HandlerFunc) VerifyMFA(c *gin.Context) {
+	var input models.MFAVerifyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "mfa_token and code are required")
+		return
+	}
+
+	claims, err := h.ParseToken(input.MFAToken, utils.TokenPurposeMFA)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid or expired MFA token, please login again")
+		return
+	}
+	empID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid user ID")
+		return
+	}
+
+	emp, err := h.Query.GetEmployeeByID(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+	if emp.Status != nil && *emp.Status == "deactive" {
+		utils.RespondWithError(c, http.StatusForbidden, "Your account is deactivated. You cannot login")
+		return
+	}
+
+	// Wrong codes count towards the same lockout as wrong passwords
+	email := strings.ToLower(emp.Email)
+	clientIP := c.ClientIP()
+	remaining, err := h.loginLockRemaining(email, clientIP)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to check login attempts")
+		return
+	}
+	if remaining > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		utils.RespondWithError(c, http.StatusTooManyRequests, "Too many failed login attempts. Please try again later")
+		return
+	}
+
+	mfa, err := h.Query.GetEmployeeMFA(empID)
+	if err != nil || !mfa.Enabled {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Two-factor authentication is not enabled, please login again")
+		return
+	}
+
+	secret, err := h.mfaSecret(mfa)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to verify code")
+		return
+	}
+	ok, err := h.checkMFACode(empID, secret, input.Code)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to verify code")
+		return
+	}
+	if !ok {
+		h.registerFailedLogin(c, email, clientIP, &empID)
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid verification code")
+		return
+	}
+
+	if err := h.Query.ClearLoginThrottle(repositories.ThrottleKeyEmail, email); err != nil {
+		fmt.Printf("Failed to reset login attempts for %s: %v\n", email, err)
+	}
+
+	// The challenge token is single use
+	if err := h.Query.RevokeAccessToken(claims.ID, empID, claims.ExpiresAt.Time); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to verify code")
+		return
+	}
+
+	tokens, err := h.issueTokens(c, empID.String(), emp.Role)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate authentication token")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"message":       "Login successful",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user": gin.H{
+			"id":    emp.ID,
+			"email": emp.Email,
+			"role":  emp.Role,
+		},
+	})
+}
+
+// SetupMFA - POST /api/auth/mfa/setup
+// Generates a new TOTP secret. It is only active after EnableMFA confirms a code.
+func (h *HandlerFunc) SetupMFA(c *gin.Context) {
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	current, err := h.Query.GetEmployeeMFA(empID)
+	if err != nil && err != sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to load two-factor settings: "+err.Error())
+		return
+	}
+	if err == nil && current.Enabled {
+		utils.RespondWithError(c, http.StatusConflict, "two-factor authentication is already enabled")
+		return
+	}
+	if !h.FieldCrypt.Enabled() {
+		utils.RespondWithError(c, http.StatusServiceUnavailable, fieldcrypt.ErrDisabled.Error())
+		return
+	}
+
+	emp, err := h.Query.GetEmployeeByID(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate secret")
+		return
+	}
+
+	encrypted, err := h.FieldCrypt.Encrypt(secret, mfaCryptContext(empID))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to encrypt secret")
+		return
+	}
+	if err := h.Query.SavePendingMFA(empID, encrypted); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to save secret: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": utils.TOTPProvisioningURI(mfaIssuer, emp.Email, secret),
+		"message":     "scan the QR code with your authenticator app and confirm with a code",
+	})
+}
+
+// EnableMFA - POST /api/auth/mfa/enable
+// Confirms the pending secret with a code and returns one-time recovery codes.
+// When called with an enrollment token the real tokens are issued as well.
+func (h *HandlerFunc) EnableMFA(c *gin.Context) {
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	var input models.MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "code is required")
+		return
+	}
+
+	mfa, err := h.Query.GetEmployeeMFA(empID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusBadRequest, "call /api/auth/mfa/setup first")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to load two-factor settings: "+err.Error())
+		return
+	}
+	if mfa.Enabled {
+		utils.RespondWithError(c, http.StatusConflict, "two-factor authentication is already enabled")
+		return
+	}
+
+	secret, err := h.mfaSecret(mfa)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to verify code")
+		return
+	}
+	step, ok := utils.ValidateTOTP(secret, strings.TrimSpace(input.Code), time.Now())
+	if ok {
+		ok, err = h.Query.UseTOTPStep(empID, step)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to verify code")
+			return
+		}
+	}
+	if !ok {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid verification code")
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate recovery codes")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.EnableMFA(tx, empID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to enable two-factor authentication: "+err.Error())
+		}
+		if err := h.Query.ReplaceRecoveryCodes(tx, empID, hashes); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to store recovery codes: "+err.Error())
+		}
+		data := utils.NewCommon(constant.ComponentAuth, constant.ActionMFAEnable, empID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := gin.H{
+		"success":        true,
+		"message":        "two-factor authentication enabled, store the recovery codes somewhere safe",
+		"recovery_codes": codes,
+	}
+
+	// Enrollment during login: finish the login now
+	if c.GetString("token_purpose") == utils.TokenPurposeMFAEnroll {
+		if err := h.Query.RevokeAccessToken(c.GetString("jti"), empID, c.GetTime("token_exp")); err != nil {
+			fmt.Printf("Failed to revoke enrollment token for %s: %v\n", empID, err)
+		}
+		tokens, err := h.issueTokens(c, empID.String(), c.GetString("role"))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "two-factor enabled but failed to issue token, please login again")
+			return
+		}
+		response["token"] = tokens.AccessToken
+		response["refresh_token"] = tokens.RefreshToken
+		response["expires_in"] = tokens.ExpiresIn
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DisableMFA - POST /api/auth/mfa/disable
+// Turns off two-factor authentication after confirming a code. Not allowed
+// when the user's role is required to use MFA.
+func (h *HandlerFunc) DisableMFA(c *gin.Context) {
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	var input models.MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "code is required")
+		return
+	}
+
+	requiredRoles, err := h.Query.GetMFARequiredRoles()
+	if err != nil && err != sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to load company settings: "+err.Error())
+		return
+	}
+	if slices.Contains(requiredRoles, c.GetString("role")) {
+		utils.RespondWithError(c, http.StatusForbidden, "two-factor authentication is mandatory for your role")
+		return
+	}
+
+	mfa, err := h.Query.GetEmployeeMFA(empID)
+	if err != nil || !mfa.Enabled {
+		utils.RespondWithError(c, http.StatusBadRequest, "two-factor authentication is not enabled")
+		return
+	}
+
+	secret, err := h.mfaSecret(mfa)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to verify code")
+		return
+	}
+	ok, err := h.checkMFACode(empID, secret, input.Code)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to verify code")
+		return
+	}
+	if !ok {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid verification code")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.DeleteEmployeeMFA(tx, empID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to disable two-factor authentication: "+err.Error())
+		}
+		data := utils.NewCommon(constant.ComponentAuth, constant.ActionMFADisable, empID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes - POST /api/auth/mfa/recovery-codes
+// Replaces all recovery codes after confirming a current TOTP code
+func (h *HandlerFunc) RegenerateRecoveryCodes(c *gin.Context) {
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	var input models.MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "code is required")
+		return
+	}
+
+	mfa, err := h.Query.GetEmployeeMFA(empID)
+	if err != nil || !mfa.Enabled {
+		utils.RespondWithError(c, http.StatusBadRequest, "two-factor authentication is not enabled")
+		return
+	}
+
+	secret, err := h.mfaSecret(mfa)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to verify code")
+		return
+	}
+	ok, err := h.checkMFACode(empID, secret, input.Code)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to verify code")
+		return
+	}
+	if !ok {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid verification code")
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate recovery codes")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.ReplaceRecoveryCodes(tx, empID, hashes); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to store recovery codes: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":        true,
+		"recovery_codes": codes,
+	})
+}
+
+// ResetEmployeeMFA - DELETE /api/employee/:id/mfa
+// Removes an employee's MFA enrollment (lost device). If the role
+// requires MFA the employee is asked to enroll again at next login.
+func (h *HandlerFunc) ResetEmployeeMFA(c *gin.Context) {
+	adminID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "invalid employee UUID")
+		return
+	}
+
+	empID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	if _, err := h.Query.GetEmployeeMFA(empID); err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "two-factor authentication is not set up for this employee")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.DeleteEmployeeMFA(tx, empID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to reset two-factor authentication: "+err.Error())
+		}
+		data := utils.NewCommon(constant.ComponentAuth, constant.ActionMFADisable, adminID).WithTarget(empID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "two-factor authentication reset successfully",
+		"employee_id": empID,
+	})
+}
+
+// newRecoveryCodes returns plaintext codes for the user and their hashes for storage
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(utils.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
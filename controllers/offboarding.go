@@ -0,0 +1,417 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// Items resolved through their own endpoints cannot be marked DONE by hand
+var autoOffboardingItems = map[string]string{
+	"equipment_return":   "it is done once the equipment is returned",
+	"leave_cancellation": "it is done once the leave is cancelled, rejected or withdrawn",
+}
+
+// scheduleOffboarding starts the employee's exit, or moves the ending date of
+// the current one, and keeps Tbl_Employee.ending_date in line. A nil reason
+// keeps the current one.
+func (h *HandlerFunc) scheduleOffboarding(tx *sqlx.Tx, empID uuid.UUID, endingDate time.Time, reason *string, by uuid.UUID) (id uuid.UUID, created bool, err error) {
+	current, err := h.Query.GetCurrentOffboarding(tx, empID)
+	switch {
+	case err == sql.ErrNoRows:
+		newReason := ""
+		if reason != nil {
+			newReason = *reason
+		}
+		if id, err = h.Query.CreateOffboarding(tx, empID, endingDate, newReason, by); err != nil {
+			return id, false, err
+		}
+		created = true
+	case err != nil:
+		return id, false, err
+	default:
+		id = current.ID
+		newReason := current.Reason
+		if reason != nil {
+			newReason = *reason
+		}
+		if err = h.Query.UpdateOffboarding(tx, id, endingDate, newReason); err != nil {
+			return id, false, err
+		}
+	}
+
+	if err = h.Query.SetEmployeeEndingDate(tx, empID, &endingDate); err != nil {
+		return id, created, err
+	}
+	return id, created, h.Query.SyncOffboardingItems(tx, id)
+}
+
+// offboardingReport refreshes the checklist of the employee's current exit and
+// returns it as the clearance report
+func (h *HandlerFunc) offboardingReport(c *gin.Context, tx *sqlx.Tx, empID uuid.UUID) (gin.H, error) {
+	o, err := h.Query.GetCurrentOffboarding(tx, empID)
+	if err == sql.ErrNoRows {
+		return nil, utils.CustomErr(c, http.StatusNotFound, "employee has no offboarding in progress")
+	} else if err != nil {
+		return nil, utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch offboarding: "+err.Error())
+	}
+	if err := h.Query.SyncOffboardingItems(tx, o.ID); err != nil {
+		return nil, utils.CustomErr(c, http.StatusInternalServerError, "failed to refresh checklist: "+err.Error())
+	}
+	// Syncing may have changed the status and pending count
+	if o, err = h.Query.GetCurrentOffboarding(tx, empID); err != nil {
+		return nil, utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch offboarding: "+err.Error())
+	}
+	items, err := h.Query.GetOffboardingItems(tx, o.ID)
+	if err != nil {
+		return nil, utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch checklist: "+err.Error())
+	}
+
+	counts := map[string]int{}
+	for _, item := range items {
+		counts[item.Status]++
+	}
+	return gin.H{
+		"offboarding": o,
+		"items":       items,
+		"summary": gin.H{
+			"total":   len(items),
+			"pending": counts["PENDING"],
+			"done":    counts["DONE"],
+			"waived":  counts["WAIVED"],
+		},
+		"cleared": o.Status == "CLEARED",
+	}, nil
+}
+
+// StartOffboarding - POST /api/employee/:id/offboarding
+// Sets the ending date and builds the exit checklist; posting again moves the
+// ending date. Requires offboarding.manage
+func (h *HandlerFunc) StartOffboarding(c *gin.Context) {
+	// 1️ Target and input
+	currentUserID, emp, ok := h.targetEmployee(c, true)
+	if !ok {
+		return
+	}
+	empID := *emp.ID
+	var input models.StartOffboardingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	endingDate, _ := time.Parse("2006-01-02", input.EndingDate)
+	if input.Reason != nil {
+		reason := strings.TrimSpace(*input.Reason)
+		input.Reason = &reason
+	}
+
+	if emp.JoiningDate != nil && endingDate.Before(*emp.JoiningDate) {
+		utils.RespondWithError(c, http.StatusBadRequest, "ending_date must not be before the joining date")
+		return
+	}
+
+	// 2️ Start or reschedule, then report
+	var report gin.H
+	created := false
+	err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		var err error
+		if _, created, err = h.scheduleOffboarding(tx, empID, endingDate, input.Reason, currentUserID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to start offboarding: "+err.Error())
+		}
+		if report, err = h.offboardingReport(c, tx, empID); err != nil {
+			return err
+		}
+		action := constant.ActionUpdate
+		if created {
+			action = constant.ActionCreate
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentOffboarding, action, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	status, message := http.StatusOK, "offboarding updated successfully"
+	if created {
+		status, message = http.StatusCreated, "offboarding started successfully"
+	}
+	report["message"] = message
+	c.JSON(status, report)
+}
+
+// GetOffboarding - GET /api/employee/:id/offboarding
+// Clearance report of the employee's exit (Self/offboarding.manage)
+func (h *HandlerFunc) GetOffboarding(c *gin.Context) {
+	currentUserID, emp, ok := h.targetEmployee(c, false)
+	if !ok {
+		return
+	}
+	empID := *emp.ID
+	if empID != currentUserID && !h.HasPermission(c, constant.PermOffboardingManage) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to view the offboarding of this employee")
+		return
+	}
+
+	var report gin.H
+	err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		var err error
+		report, err = h.offboardingReport(c, tx, empID)
+		return err
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	report["message"] = "offboarding fetched successfully"
+	c.JSON(http.StatusOK, report)
+}
+
+// UpdateOffboardingItem - PATCH /api/employee/:id/offboarding/items/:itemId
+// Marks a checklist item DONE or WAIVED (a note is required), or reopens it.
+// Requires offboarding.manage
+func (h *HandlerFunc) UpdateOffboardingItem(c *gin.Context) {
+	// 1️ Target and input
+	currentUserID, emp, ok := h.targetEmployee(c, true)
+	if !ok {
+		return
+	}
+	empID := *emp.ID
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid item ID")
+		return
+	}
+	var input models.UpdateOffboardingItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	input.Note = strings.TrimSpace(input.Note)
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	if input.Status == "WAIVED" && input.Note == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, "note is required when waiving an item")
+		return
+	}
+
+	// 2️ Update the item and report
+	var report gin.H
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		o, err := h.Query.GetCurrentOffboarding(tx, empID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "employee has no offboarding in progress")
+		} else if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch offboarding: "+err.Error())
+		}
+		item, err := h.Query.GetOffboardingItem(tx, o.ID, itemID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "checklist item not found")
+		} else if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch checklist item: "+err.Error())
+		}
+		if reason, auto := autoOffboardingItems[item.ItemType]; auto && input.Status == "DONE" {
+			return utils.CustomErr(c, http.StatusConflict, "cannot mark "+item.ItemType+" as DONE, "+reason)
+		}
+
+		if err := h.Query.UpdateOffboardingItem(tx, item.ID, input.Status, input.Note, currentUserID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update checklist item: "+err.Error())
+		}
+		if report, err = h.offboardingReport(c, tx, empID); err != nil {
+			return err
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentOffboarding, constant.ActionUpdate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	report["message"] = "checklist item updated successfully"
+	c.JSON(http.StatusOK, report)
+}
+
+// CancelOffboarding - DELETE /api/employee/:id/offboarding
+// Cancels an exit before the employee is deactivated and clears the ending
+// date. Requires offboarding.manage
+func (h *HandlerFunc) CancelOffboarding(c *gin.Context) {
+	currentUserID, emp, ok := h.targetEmployee(c, true)
+	if !ok {
+		return
+	}
+	empID := *emp.ID
+
+	var offboardingID uuid.UUID
+	err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		o, err := h.Query.GetCurrentOffboarding(tx, empID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "employee has no offboarding in progress")
+		} else if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch offboarding: "+err.Error())
+		}
+		if o.DeactivatedAt != nil {
+			return utils.CustomErr(c, http.StatusConflict, "employee was already deactivated on their ending date, reactivate them instead")
+		}
+		offboardingID = o.ID
+
+		if err := h.Query.CancelOffboarding(tx, o.ID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to cancel offboarding: "+err.Error())
+		}
+		if err := h.Query.SetEmployeeEndingDate(tx, empID, nil); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to clear ending date: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentOffboarding, constant.ActionCancel, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "offboarding cancelled successfully",
+		"offboarding_id": offboardingID,
+	})
+}
+
+// ListOffboardings - GET /api/employee/offboarding?status=OPEN
+// Requires offboarding.manage
+func (h *HandlerFunc) ListOffboardings(c *gin.Context) {
+	status := strings.ToUpper(c.Query("status"))
+	if status != "" && !slices.Contains([]string{"OPEN", "CLEARED", "CANCELLED"}, status) {
+		utils.RespondWithError(c, http.StatusBadRequest, "status must be OPEN, CLEARED or CANCELLED")
+		return
+	}
+
+	list, err := h.Query.ListOffboardings(status)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch offboardings: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "offboardings fetched successfully",
+		"total":        len(list),
+		"offboardings": list,
+	})
+}
+
+// pendingClearances returns the exits whose final payroll is the given month
+// and that are not cleared yet
+func (h *HandlerFunc) pendingClearances(ctx context.Context, month, year int) ([]models.Offboarding, error) {
+	pending := []models.Offboarding{}
+	err := common.ExecuteTransaction(ctx, h.Query.DB, func(tx *sqlx.Tx) error {
+		exits, err := h.Query.GetOffboardingsEndingIn(tx, month, year)
+		if err != nil {
+			return err
+		}
+		for _, o := range exits {
+			if err := h.Query.SyncOffboardingItems(tx, o.ID); err != nil {
+				return err
+			}
+		}
+		if exits, err = h.Query.GetOffboardingsEndingIn(tx, month, year); err != nil {
+			return err
+		}
+		for _, o := range exits {
+			if o.Status != "CLEARED" {
+				pending = append(pending, o)
+			}
+		}
+		return nil
+	})
+	return pending, err
+}
+
+// StartOffboardingJob deactivates employees whose ending date has passed,
+// right away and then every interval
+func (h *HandlerFunc) StartOffboardingJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			h.processDueOffboardings()
+			<-ticker.C
+		}
+	}()
+}
+
+// processDueOffboardings deactivates every employee past their ending date,
+// hands their direct reports to the nearest active manager above them and
+// closes the access revocation item
+func (h *HandlerFunc) processDueOffboardings() {
+	due, err := h.Query.GetDueOffboardings()
+	if err != nil {
+		fmt.Printf("Failed to fetch due offboardings: %v\n", err)
+		return
+	}
+
+	for _, o := range due {
+		// Logged as the person who started the exit, with the leaver as target
+		by := o.EmployeeID
+		if o.CreatedBy != nil {
+			by = *o.CreatedBy
+		}
+
+		err := common.ExecuteTransaction(context.Background(), h.Query.DB, func(tx *sqlx.Tx) error {
+			if err := h.Query.LockReportingLines(tx); err != nil {
+				return err
+			}
+			// Another instance may have processed or someone cancelled it meanwhile
+			current, err := h.Query.GetCurrentOffboarding(tx, o.EmployeeID)
+			if err == sql.ErrNoRows || (err == nil && (current.ID != o.ID || current.DeactivatedAt != nil)) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			newManagerID, err := h.Query.GetReplacementManager(tx, o.EmployeeID)
+			if err != nil {
+				return fmt.Errorf("find a new manager for the reports: %w", err)
+			}
+			if _, err := h.Query.ReassignDirectReports(tx, o.EmployeeID, newManagerID); err != nil {
+				return err
+			}
+			if err := h.Query.DeactivateOffboardedEmployee(tx, current); err != nil {
+				return err
+			}
+			if err := h.Query.SyncOffboardingItems(tx, current.ID); err != nil {
+				return err
+			}
+			return common.AddLog(utils.NewCommon(constant.ComponentOffboarding, constant.ActionUpdate, by).WithTarget(o.EmployeeID), tx)
+		})
+		if err != nil {
+			fmt.Printf("Failed to deactivate %s after their ending date: %v\n", o.EmployeeName, err)
+			continue
+		}
+		h.Principals.Invalidate(o.EmployeeID)
+		fmt.Printf("Deactivated %s after their ending date %s\n", o.EmployeeName, o.EndingDate.Format("2006-01-02"))
+	}
+}
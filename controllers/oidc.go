@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/sso"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+)
+
+// oidcLoginTTL is how long the user has to finish signing in at the provider
+const oidcLoginTTL = 10 * time.Minute
+
+// StartOIDCLogin - GET /api/auth/oidc/login
+// Returns the identity provider URL the frontend should send the browser to.
+func (h *HandlerFunc) StartOIDCLogin(c *gin.Context) {
+	if !h.SSO.Enabled() {
+		utils.RespondWithError(c, http.StatusNotFound, sso.ErrDisabled.Error())
+		return
+	}
+
+	// 1. State (CSRF), nonce (ID token replay) and PKCE verifier
+	state, stateHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start single sign-on")
+		return
+	}
+	nonce, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start single sign-on")
+		return
+	}
+	verifier := sso.NewVerifier()
+
+	// 2. Build the provider URL (runs discovery on first use)
+	authURL, err := h.SSO.AuthCodeURL(c, state, nonce, verifier)
+	if err != nil {
+		log.Printf("OIDC login error: %v", err)
+		utils.RespondWithError(c, http.StatusBadGateway, "Identity provider is unavailable")
+		return
+	}
+
+	// 3. Remember verifier and nonce until the callback
+	if err := h.Query.CreateOIDCLogin(stateHash, verifier, nonce, oidcLoginTTL); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start single sign-on: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":           true,
+		"authorization_url": authURL,
+		"state":             state,
+	})
+}
+
+// OIDCCallback - POST /api/auth/oidc/callback
+// Exchanges the code the provider redirected back with for our own tokens.
+// The verified email must belong to an existing employee.
+func (h *HandlerFunc) OIDCCallback(c *gin.Context) {
+	if !h.SSO.Enabled() {
+		utils.RespondWithError(c, http.StatusNotFound, sso.ErrDisabled.Error())
+		return
+	}
+
+	// 1. Parse request body
+	var input models.OIDCCallbackInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "code and state are required")
+		return
+	}
+
+	// 2. State must match a login we started (single use)
+	verifier, nonce, err := h.Query.ConsumeOIDCLogin(utils.HashToken(input.State))
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid or expired sign-in attempt, please start again")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to verify sign-in attempt: "+err.Error())
+		return
+	}
+
+	// 3. Redeem the code and validate the ID token
+	identity, err := h.SSO.Exchange(c, input.Code, verifier, nonce)
+	if err != nil {
+		log.Printf("OIDC callback error: %v", err)
+		utils.RespondWithError(c, http.StatusUnauthorized, "Single sign-on failed")
+		return
+	}
+
+	email := strings.TrimSpace(identity.Email)
+	if email == "" {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Identity provider did not share an email address")
+		return
+	}
+	if h.Env.OIDC_REQUIRE_VERIFIED_EMAIL && !identity.EmailVerified {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Email address is not verified by the identity provider")
+		return
+	}
+
+	// 4. Map the email to an employee
+	emp, err := h.Query.GetEmployeeBySSOEmail(email)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Printf("OIDC login for unknown email: %s", email)
+		utils.RespondWithError(c, http.StatusUnauthorized, "No employee account exists for "+email)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to load employee: "+err.Error())
+		return
+	}
+
+	if emp.Status == "deactive" {
+		utils.RespondWithError(c, http.StatusForbidden, "Your account is deactivated. You cannot login")
+		return
+	}
+
+	// 5. MFA challenge or tokens, as for a password login
+	h.completeLogin(c, emp)
+}
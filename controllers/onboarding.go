@@ -0,0 +1,503 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// onboardingAccess tells who the caller is to the new hire: HR (anyone with
+// onboarding.manage), the new hire's manager or the new hire themselves
+type onboardingAccess struct {
+	userID    uuid.UUID
+	canManage bool
+	isManager bool
+	isSelf    bool
+}
+
+// canComplete reports whether the caller owns the task
+func (a onboardingAccess) canComplete(task models.OnboardingTask) bool {
+	switch {
+	case a.canManage:
+		return true
+	case task.OwnerRole == "MANAGER":
+		return a.isManager
+	case task.OwnerRole == "EMPLOYEE":
+		return a.isSelf
+	}
+	return false
+}
+
+// onboardingTarget parses the employee, checks they exist and that the caller
+// is HR, their manager or the employee themselves
+func (h *HandlerFunc) onboardingTarget(c *gin.Context) (access onboardingAccess, empID uuid.UUID, ok bool) {
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	empID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	emp, err := h.Query.GetEmployeeByID(empID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	access = onboardingAccess{
+		userID:    currentUserID,
+		canManage: h.HasPermission(c, constant.PermOnboardingManage),
+		isManager: emp.ManagerID != nil && *emp.ManagerID == currentUserID,
+		isSelf:    empID == currentUserID,
+	}
+	if !access.canManage && !access.isManager && !access.isSelf {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to view the onboarding of this employee")
+		return
+	}
+	return access, empID, true
+}
+
+// onboardingReport returns the employee's checklist with a summary
+func (h *HandlerFunc) onboardingReport(empID uuid.UUID) (gin.H, error) {
+	tasks, err := h.Query.GetOnboardingTasks(empID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	overdue := 0
+	for _, task := range tasks {
+		counts[task.Status]++
+		if task.Status == "PENDING" && task.DaysOverdue > 0 {
+			overdue++
+		}
+	}
+	return gin.H{
+		"employee_id": empID,
+		"tasks":       tasks,
+		"summary": gin.H{
+			"total":   len(tasks),
+			"pending": counts["PENDING"],
+			"done":    counts["DONE"],
+			"skipped": counts["SKIPPED"],
+			"overdue": overdue,
+		},
+		"completed": len(tasks) > 0 && counts["PENDING"] == 0,
+	}, nil
+}
+
+// bindOnboardingTemplate reads and validates a template, checking the name is
+// not taken by another template
+func (h *HandlerFunc) bindOnboardingTemplate(c *gin.Context, excludeID *uuid.UUID) (input models.OnboardingTemplateInput, ok bool) {
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	input.Description = strings.TrimSpace(input.Description)
+	if input.Role != nil {
+		role := strings.ToUpper(strings.TrimSpace(*input.Role))
+		input.Role = &role
+	}
+	for i := range input.Tasks {
+		input.Tasks[i].Title = strings.TrimSpace(input.Tasks[i].Title)
+		input.Tasks[i].Description = strings.TrimSpace(input.Tasks[i].Description)
+		input.Tasks[i].OwnerRole = strings.ToUpper(input.Tasks[i].OwnerRole)
+	}
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+
+	exists, err := h.Query.OnboardingTemplateNameExists(input.Name, excludeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to check template name: "+err.Error())
+		return
+	}
+	if exists {
+		utils.RespondWithError(c, http.StatusConflict, "an onboarding template with this name already exists")
+		return
+	}
+	return input, true
+}
+
+// ListOnboardingTemplates - GET /api/employee/onboarding/templates
+// Requires onboarding.manage
+func (h *HandlerFunc) ListOnboardingTemplates(c *gin.Context) {
+	templates, err := h.Query.ListOnboardingTemplates()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch onboarding templates: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "onboarding templates fetched successfully",
+		"total":     len(templates),
+		"templates": templates,
+	})
+}
+
+// GetOnboardingTemplate - GET /api/employee/onboarding/templates/:templateId
+// Requires onboarding.manage
+func (h *HandlerFunc) GetOnboardingTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid template ID")
+		return
+	}
+
+	template, err := h.Query.GetOnboardingTemplate(templateID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "onboarding template not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch onboarding template: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "onboarding template fetched successfully",
+		"template": template,
+	})
+}
+
+// CreateOnboardingTemplate - POST /api/employee/onboarding/templates
+// Active templates are copied to every new hire of their role (all roles when
+// role is omitted). Requires onboarding.manage
+func (h *HandlerFunc) CreateOnboardingTemplate(c *gin.Context) {
+	// 1️ Current user and input
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	input, ok := h.bindOnboardingTemplate(c, nil)
+	if !ok {
+		return
+	}
+	isActive := input.IsActive == nil || *input.IsActive
+
+	// 2️ Create the template and its tasks
+	var templateID uuid.UUID
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		var err error
+		if templateID, err = h.Query.CreateOnboardingTemplate(tx, input, isActive, currentUserID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to create onboarding template: "+err.Error())
+		}
+		if err := h.Query.ReplaceOnboardingTemplateTasks(tx, templateID, input.Tasks); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to create template tasks: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentOnboarding, constant.ActionCreate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "onboarding template created successfully",
+		"template_id": templateID,
+	})
+}
+
+// UpdateOnboardingTemplate - PUT /api/employee/onboarding/templates/:templateId
+// Replaces the template and its task list. Checklists already handed out keep
+// their tasks. Requires onboarding.manage
+func (h *HandlerFunc) UpdateOnboardingTemplate(c *gin.Context) {
+	// 1️ Current user, template and input
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid template ID")
+		return
+	}
+	input, ok := h.bindOnboardingTemplate(c, &templateID)
+	if !ok {
+		return
+	}
+	isActive := input.IsActive == nil || *input.IsActive
+
+	// 2️ Update the template and swap its tasks
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		found, err := h.Query.UpdateOnboardingTemplate(tx, templateID, input, isActive)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update onboarding template: "+err.Error())
+		}
+		if !found {
+			return utils.CustomErr(c, http.StatusNotFound, "onboarding template not found")
+		}
+		if err := h.Query.ReplaceOnboardingTemplateTasks(tx, templateID, input.Tasks); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update template tasks: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentOnboarding, constant.ActionUpdate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "onboarding template updated successfully",
+		"template_id": templateID,
+	})
+}
+
+// DeleteOnboardingTemplate - DELETE /api/employee/onboarding/templates/:templateId
+// Checklists already handed out keep their tasks. Requires onboarding.manage
+func (h *HandlerFunc) DeleteOnboardingTemplate(c *gin.Context) {
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid template ID")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		found, err := h.Query.DeleteOnboardingTemplate(tx, templateID)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to delete onboarding template: "+err.Error())
+		}
+		if !found {
+			return utils.CustomErr(c, http.StatusNotFound, "onboarding template not found")
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentOnboarding, constant.ActionDelete, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "onboarding template deleted successfully",
+		"template_id": templateID,
+	})
+}
+
+// GetOnboarding - GET /api/employee/:id/onboarding
+// The employee's onboarding checklist (Self/Manager/onboarding.manage)
+func (h *HandlerFunc) GetOnboarding(c *gin.Context) {
+	_, empID, ok := h.onboardingTarget(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.onboardingReport(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch onboarding tasks: "+err.Error())
+		return
+	}
+
+	report["message"] = "onboarding fetched successfully"
+	c.JSON(http.StatusOK, report)
+}
+
+// ApplyOnboardingTemplate - POST /api/employee/:id/onboarding
+// Adds the tasks of a template to the employee's checklist, e.g. for
+// employees who joined before it existed. Requires onboarding.manage
+func (h *HandlerFunc) ApplyOnboardingTemplate(c *gin.Context) {
+	// 1️ Target and input
+	access, empID, ok := h.onboardingTarget(c)
+	if !ok {
+		return
+	}
+	var input models.ApplyOnboardingTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	templateID, _ := uuid.Parse(input.TemplateID)
+
+	template, err := h.Query.GetOnboardingTemplate(templateID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "onboarding template not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch onboarding template: "+err.Error())
+		return
+	}
+	if len(template.Tasks) == 0 {
+		utils.RespondWithError(c, http.StatusBadRequest, "onboarding template has no tasks")
+		return
+	}
+
+	// 2️ Copy the tasks once
+	var created int64
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		applied, err := h.Query.HasOnboardingTemplate(tx, empID, templateID)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to check onboarding tasks: "+err.Error())
+		}
+		if applied {
+			return utils.CustomErr(c, http.StatusConflict, "template was already applied to this employee")
+		}
+		if created, err = h.Query.InstantiateOnboarding(tx, empID, &templateID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to create onboarding tasks: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentOnboarding, constant.ActionCreate, access.userID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	report, err := h.onboardingReport(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch onboarding tasks: "+err.Error())
+		return
+	}
+	report["message"] = "onboarding template applied successfully"
+	report["created_tasks"] = created
+	c.JSON(http.StatusCreated, report)
+}
+
+// UpdateOnboardingTask - PATCH /api/employee/:id/onboarding/tasks/:taskId
+// Marks a task DONE or reopens it. HR tasks are completed by onboarding.manage,
+// MANAGER tasks by the employee's manager and EMPLOYEE tasks by the employee;
+// onboarding.manage may complete any task and SKIP one with a note.
+func (h *HandlerFunc) UpdateOnboardingTask(c *gin.Context) {
+	// 1️ Target and input
+	access, empID, ok := h.onboardingTarget(c)
+	if !ok {
+		return
+	}
+	taskID, err := uuid.Parse(c.Param("taskId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid task ID")
+		return
+	}
+	var input models.UpdateOnboardingTaskInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	input.Note = strings.TrimSpace(input.Note)
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	if input.Status == "SKIPPED" {
+		if !access.canManage {
+			utils.RespondWithError(c, http.StatusForbidden, "not permitted to skip onboarding tasks")
+			return
+		}
+		if input.Note == "" {
+			utils.RespondWithError(c, http.StatusBadRequest, "note is required when skipping a task")
+			return
+		}
+	}
+
+	// 2️ Update the task
+	var task models.OnboardingTask
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		current, err := h.Query.GetOnboardingTask(tx, empID, taskID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "onboarding task not found")
+		} else if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch onboarding task: "+err.Error())
+		}
+		if !access.canComplete(current) {
+			return utils.CustomErr(c, http.StatusForbidden, "task is owned by "+current.OwnerRole+", not permitted to update it")
+		}
+
+		if err := h.Query.UpdateOnboardingTask(tx, current.ID, input.Status, input.Note, access.userID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update onboarding task: "+err.Error())
+		}
+		if task, err = h.Query.GetOnboardingTask(tx, empID, taskID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch onboarding task: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentOnboarding, constant.ActionUpdate, access.userID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "onboarding task updated successfully",
+		"task":    task,
+	})
+}
+
+// GetOverdueOnboardingTasks - GET /api/employee/onboarding/overdue?owner_role=MANAGER&employee_id=
+// Pending tasks past their due date. onboarding.manage sees every task,
+// others the tasks they have to complete: their own and their direct reports'
+// MANAGER tasks.
+func (h *HandlerFunc) GetOverdueOnboardingTasks(c *gin.Context) {
+	// 1️ Current user and filters
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	ownerRole := strings.ToUpper(c.Query("owner_role"))
+	if ownerRole != "" && !slices.Contains([]string{"HR", "MANAGER", "EMPLOYEE"}, ownerRole) {
+		utils.RespondWithError(c, http.StatusBadRequest, "owner_role must be HR, MANAGER or EMPLOYEE")
+		return
+	}
+	var empID *uuid.UUID
+	if s := c.Query("employee_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+			return
+		}
+		empID = &id
+	}
+
+	// 2️ Fetch
+	all := h.HasPermission(c, constant.PermOnboardingManage)
+	tasks, err := h.Query.GetOverdueOnboardingTasks(all, currentUserID, ownerRole, empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch overdue onboarding tasks: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "overdue onboarding tasks fetched successfully",
+		"total":   len(tasks),
+		"tasks":   tasks,
+	})
+}
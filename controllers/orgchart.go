@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// maxOrgChartDepth bounds how many levels below an employee are loaded
+const maxOrgChartDepth = 20
+
+// orgChartAccess checks that the employee exists and the caller may see their
+// place in the hierarchy: themselves, anyone with employee.view_all, or a
+// manager above them. The chain of command is returned for reuse.
+func (h *HandlerFunc) orgChartAccess(c *gin.Context) (empID uuid.UUID, chain []models.OrgChartNode, ok bool) {
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	empID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	if _, err := h.Query.GetEmployeeCurrentRole(empID.String()); err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	chain, err = h.Query.GetChainOfCommand(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch chain of command: "+err.Error())
+		return
+	}
+
+	if empID != currentUserID && !h.HasPermission(c, constant.PermEmployeeViewAll) &&
+		!slices.ContainsFunc(chain, func(m models.OrgChartNode) bool { return m.ID == currentUserID }) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to view the hierarchy of this employee")
+		return
+	}
+	return empID, chain, true
+}
+
+// orgTreeQuery reads ?depth= (1 = direct reports only, default all levels up
+// to maxOrgChartDepth) and ?include_inactive=true
+func orgTreeQuery(c *gin.Context) (depth int, includeInactive bool, ok bool) {
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", strconv.Itoa(maxOrgChartDepth)))
+	if err != nil || depth < 1 || depth > maxOrgChartDepth {
+		utils.RespondWithError(c, http.StatusBadRequest, fmt.Sprintf("depth must be between 1 and %d", maxOrgChartDepth))
+		return
+	}
+	includeInactive, _ = strconv.ParseBool(c.DefaultQuery("include_inactive", "false"))
+	return depth, includeInactive, true
+}
+
+// GetEmployeeReports - GET /api/employee/:id/reports?depth=2&include_inactive=true
+// Direct and indirect reports as a flat list, level 1 being direct reports
+func (h *HandlerFunc) GetEmployeeReports(c *gin.Context) {
+	// 1️ Access and depth
+	empID, _, ok := h.orgChartAccess(c)
+	if !ok {
+		return
+	}
+	depth, includeInactive, ok := orgTreeQuery(c)
+	if !ok {
+		return
+	}
+
+	// 2️ Fetch the tree, the first row is the employee
+	nodes, err := h.Query.GetOrgTree(empID, depth, includeInactive)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch reports: "+err.Error())
+		return
+	}
+	reports := []models.OrgChartNode{}
+	if len(nodes) > 1 {
+		reports = nodes[1:]
+	}
+
+	directCount := 0
+	for _, r := range reports {
+		if r.Level == 1 {
+			directCount++
+		}
+	}
+
+	// 3️ Response
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "reports fetched successfully",
+		"employee_id":  empID,
+		"depth":        depth,
+		"direct_count": directCount,
+		"total_count":  len(reports),
+		"reports":      reports,
+	})
+}
+
+// GetOrgChart - GET /api/employee/:id/org-chart?depth=3&include_inactive=true
+// Nested tree of reports rooted at the employee
+func (h *HandlerFunc) GetOrgChart(c *gin.Context) {
+	// 1️ Access and depth
+	empID, _, ok := h.orgChartAccess(c)
+	if !ok {
+		return
+	}
+	depth, includeInactive, ok := orgTreeQuery(c)
+	if !ok {
+		return
+	}
+
+	// 2️ Fetch the tree
+	nodes, err := h.Query.GetOrgTree(empID, depth, includeInactive)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch org chart: "+err.Error())
+		return
+	}
+	if len(nodes) == 0 {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+
+	// 3️ Nest it: rows come level by level, so every manager is seen before
+	// their reports
+	byID := make(map[uuid.UUID]*models.OrgChartNode, len(nodes))
+	for i := range nodes {
+		node := &nodes[i]
+		byID[node.ID] = node
+		if node.Level == 0 || node.ManagerID == nil {
+			continue
+		}
+		if manager, ok := byID[*node.ManagerID]; ok {
+			manager.Reports = append(manager.Reports, node)
+		}
+	}
+
+	// 4️ Response
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "org chart fetched successfully",
+		"depth":       depth,
+		"total_count": len(nodes) - 1,
+		"org_chart":   &nodes[0],
+	})
+}
+
+// GetChainOfCommand - GET /api/employee/:id/chain-of-command
+// Managers from the direct manager (level 1) up to the top
+func (h *HandlerFunc) GetChainOfCommand(c *gin.Context) {
+	empID, chain, ok := h.orgChartAccess(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "chain of command fetched successfully",
+		"employee_id":      empID,
+		"chain_count":      len(chain),
+		"chain_of_command": chain,
+	})
+}
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+)
+
+const defaultFrontendURL = "https://zenithiveapp.netlify.app"
+
+// ForgotPassword - POST /api/auth/forgot-password
+// Emails a single-use reset link. The response is identical whether or not
+// the email exists so the endpoint cannot be used to enumerate accounts.
+func (h *HandlerFunc) ForgotPassword(c *gin.Context) {
+	var input models.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "a valid email is required")
+		return
+	}
+
+	genericResponse := gin.H{
+		"success": true,
+		"message": "If the email is registered, a password reset link has been sent",
+	}
+
+	emp, err := h.Query.GetEmployeeByEmail(strings.TrimSpace(input.Email))
+	if err != nil || emp.Status == "deactive" {
+		c.JSON(http.StatusOK, genericResponse)
+		return
+	}
+	empID, err := uuid.Parse(emp.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, genericResponse)
+		return
+	}
+
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate reset token")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		return h.Query.CreatePasswordResetToken(tx, empID, tokenHash, h.Env.RESET_TOKEN_TTL)
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to create reset token: "+err.Error())
+		return
+	}
+
+	frontend := h.Env.FRONTEND_SERVER
+	if frontend == "" {
+		frontend = defaultFrontendURL
+	}
+	resetLink := fmt.Sprintf("%s/reset-password?token=%s", strings.TrimRight(frontend, "/"), token)
+
+	go func() {
+		details, err := h.Query.GetEmployeeDetailsForNotification(empID)
+		if err != nil {
+			fmt.Printf("Failed to fetch employee details for reset email: %v\n", err)
+			return
+		}
+		if err := utils.SendPasswordResetEmail(details.Email, details.FullName, resetLink, h.Env.RESET_TOKEN_TTL); err != nil {
+			fmt.Printf("Failed to send password reset email to %s: %v\n", details.Email, err)
+		}
+	}()
+
+	c.JSON(http.StatusOK, genericResponse)
+}
+
+// ResetPassword - POST /api/auth/reset-password
+// Consumes a reset token and sets a new password. All sessions are revoked.
+func (h *HandlerFunc) ResetPassword(c *gin.Context) {
+	var input models.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: token is required and password must be at least 6 characters")
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to hash password")
+		return
+	}
+
+	empID, err := h.Query.ResetPasswordWithToken(utils.HashToken(input.Token), hashedPassword)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusBadRequest, "reset link is invalid or has expired")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to reset password: "+err.Error())
+		return
+	}
+
+	if err := h.Query.RevokeAllEmployeeTokens(empID); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to revoke sessions: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "password has been reset, please login with your new password",
+	})
+}
+
+// ChangePassword - POST /api/auth/change-password
+// Logged-in user changes their own password after confirming the current one.
+// Every other session is signed out and a fresh token pair is returned.
+func (h *HandlerFunc) ChangePassword(c *gin.Context) {
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	var input models.ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: new password must be at least 6 characters")
+		return
+	}
+
+	currentHash, err := h.Query.GetEmployeePasswordHash(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+
+	if !utils.CheckPassword(input.CurrentPassword, currentHash) {
+		utils.RespondWithError(c, http.StatusUnauthorized, "current password is incorrect")
+		return
+	}
+
+	if input.CurrentPassword == input.NewPassword {
+		utils.RespondWithError(c, http.StatusBadRequest, "new password must be different from the current password")
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to hash password")
+		return
+	}
+
+	if err := h.Query.UpdateEmployeePassword(empID, hashedPassword); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to update password: "+err.Error())
+		return
+	}
+
+	if err := h.Query.RevokeAllEmployeeTokens(empID); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to revoke sessions: "+err.Error())
+		return
+	}
+
+	tokens, err := h.issueTokens(c, empID.String(), c.GetString("role"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "password changed but failed to issue new token, please login again")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"message":       "password changed successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
@@ -13,28 +13,24 @@ import (
 	"github.com/jung-kurt/gofpdf"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
 )
 
 // PayrollPreview represents preview data for a payroll run
 type PayrollPreview struct {
-	EmployeeID  uuid.UUID `json:"employee_id"`
-	Employee    string    `json:"employee"`
-	BasicSalary float64   `json:"basic_salary"`
-	WorkingDays int       `json:"working_days"`
-	AbsentDays  float64   `json:"absent_days"`
-	Deductions  float64   `json:"deductions"`
-	NetSalary   float64   `json:"net_salary"`
+	EmployeeID     uuid.UUID `json:"employee_id"`
+	Employee       string    `json:"employee"`
+	EmploymentType string    `json:"employment_type"`
+	BasicSalary    float64   `json:"basic_salary"`
+	WorkingDays    int       `json:"working_days"`
+	AbsentDays     float64   `json:"absent_days"`
+	Deductions     float64   `json:"deductions"` // 0 for employment types that do not deduct absences
+	Arrears        float64   `json:"arrears"`    // pending arrears of backdated salary revisions, paid with this payroll
+	NetSalary      float64   `json:"net_salary"`
 }
 
 // RunPayroll handles payroll preview
 func (h *HandlerFunc) RunPayroll(c *gin.Context) {
-	roleRaw, _ := c.Get("role")
-	role := roleRaw.(string)
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, 403, "Not authorized to run payroll")
-		return
-	}
-
 	var input struct {
 		Month int `json:"month" validate:"required"`
 		Year  int `json:"year" validate:"required"`
@@ -74,6 +70,13 @@ func (h *HandlerFunc) RunPayroll(c *gin.Context) {
 		utils.RespondWithError(c, 500, "Failed to fetch employees: "+err.Error())
 		return
 	}
+	// --- Exits of this month that would block finalization ---
+	pending, err := h.pendingClearances(c, input.Month, input.Year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to check offboarding clearances: "+err.Error())
+		return
+	}
+
 	// --- Fetch working days ---
 	workingDays := h.Query.GetCompanyCurrWorkingDays()
 
@@ -82,10 +85,16 @@ func (h *HandlerFunc) RunPayroll(c *gin.Context) {
 	var previews []PayrollPreview
 
 	for _, emp := range employees {
-		// Set default salary to 0 if null
-		salary := 0.0
-		if emp.Salary != nil {
-			salary = *emp.Salary
+		// Salary in force during the month, prorated across revisions
+		salary, err := h.Query.GetMonthlySalary(h.Query.DB, emp.ID, input.Month, input.Year)
+		if err != nil {
+			utils.RespondWithError(c, 500, "Failed to calculate salary: "+err.Error())
+			return
+		}
+		arrears, err := h.Query.GetPendingArrears(h.Query.DB, emp.ID)
+		if err != nil {
+			utils.RespondWithError(c, 500, "Failed to fetch arrears: "+err.Error())
+			return
 		}
 
 		// Calculate absent days for this specific month only
@@ -96,17 +105,22 @@ func (h *HandlerFunc) RunPayroll(c *gin.Context) {
 			return
 		}
 
-		deduction := salary / float64(workingDays) * absentDays
-		net := salary - deduction
+		deduction := 0.0
+		if emp.DeductAbsences {
+			deduction = salary / float64(workingDays) * absentDays
+		}
+		net := salary - deduction + arrears
 
 		previews = append(previews, PayrollPreview{
-			EmployeeID:  emp.ID,
-			Employee:    emp.FullName,
-			BasicSalary: salary,
-			WorkingDays: workingDays,
-			AbsentDays:  absentDays,
-			Deductions:  deduction,
-			NetSalary:   net,
+			EmployeeID:     emp.ID,
+			Employee:       emp.FullName,
+			EmploymentType: emp.EmploymentType,
+			BasicSalary:    salary,
+			WorkingDays:    workingDays,
+			AbsentDays:     absentDays,
+			Deductions:     deduction,
+			Arrears:        arrears,
+			NetSalary:      net,
 		})
 
 		totalPayroll += net
@@ -125,27 +139,20 @@ func (h *HandlerFunc) RunPayroll(c *gin.Context) {
 	}
 
 	c.JSON(200, gin.H{
-		"payroll_run_id":   runID,
-		"month":            input.Month,
-		"year":             input.Year,
-		"total_payroll":    totalPayroll,
-		"total_deductions": totalDeductions,
-		"employees_count":  len(employees),
-		"payroll_preview":  previews,
+		"payroll_run_id":     runID,
+		"month":              input.Month,
+		"year":               input.Year,
+		"total_payroll":      totalPayroll,
+		"total_deductions":   totalDeductions,
+		"employees_count":    len(employees),
+		"payroll_preview":    previews,
+		"pending_clearances": pending,
 	})
 }
 
 // FinalizePayroll - generates payslips
-// Only SUPERADMIN can finalize payroll
+// Requires payroll.finalize
 func (h *HandlerFunc) FinalizePayroll(c *gin.Context) {
-	// --- Role Check - Only SUPERADMIN ---
-	roleRaw, _ := c.Get("role")
-	role := roleRaw.(string)
-	if role != "SUPERADMIN" {
-		utils.RespondWithError(c, 403, "Only SUPERADMIN can finalize payroll")
-		return
-	}
-
 	// --- Parse Payroll Run ID ---
 	runID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -178,6 +185,23 @@ func (h *HandlerFunc) FinalizePayroll(c *gin.Context) {
 		return
 	}
 
+	// --- Block while an exit of this month is not cleared ---
+	pending, err := h.pendingClearances(c, run.Month, run.Year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to check offboarding clearances: "+err.Error())
+		return
+	}
+	if len(pending) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": gin.H{
+				"code":    http.StatusConflict,
+				"message": "Cannot finalize payroll while offboardings ending this month are not cleared",
+			},
+			"pending_clearances": pending,
+		})
+		return
+	}
+
 	// --- Fetch working days ---
 	var workingDays int
 	err = h.Query.DB.Get(&workingDays,
@@ -196,16 +220,19 @@ func (h *HandlerFunc) FinalizePayroll(c *gin.Context) {
 
 	// --- Fetch Only Employees Belonging To The Payroll Run Period ---
 	var employees []struct {
-		ID       uuid.UUID `db:"id"`
-		FullName string    `db:"full_name"`
-		Salary   *float64  `db:"salary"`
+		ID             uuid.UUID `db:"id"`
+		FullName       string    `db:"full_name"`
+		DeductAbsences bool      `db:"deduct_absences"`
 	}
 
 	err = tx.Select(&employees, `
-        SELECT e.id, e.full_name, e.salary
+        SELECT e.id, e.full_name, t.deduct_absences
         FROM Tbl_Employee e
         JOIN Tbl_Payroll_run r ON r.id = $1
-        WHERE e.status='active'
+        JOIN tbl_employment_type t ON t.code = e.employment_type
+        WHERE t.include_in_payroll
+          AND (e.status='active' OR e.ending_date >= MAKE_DATE(r.year, r.month, 1))
+          AND (e.ending_date IS NULL OR e.ending_date >= MAKE_DATE(r.year, r.month, 1))
           AND (
                EXTRACT(YEAR FROM e.joining_date) < r.year
                OR (EXTRACT(YEAR FROM e.joining_date) = r.year
@@ -222,10 +249,16 @@ func (h *HandlerFunc) FinalizePayroll(c *gin.Context) {
 	var payslipIDs []uuid.UUID
 
 	for _, emp := range employees {
-		// Set default salary to 0 if null
-		salary := 0.0
-		if emp.Salary != nil {
-			salary = *emp.Salary
+		// Salary in force during the month, prorated across revisions; the
+		// lock waits for a salary revision being saved for the employee
+		if err := h.Query.LockSalaryHistory(tx, emp.ID); err != nil {
+			utils.RespondWithError(c, 500, "Failed to lock salary history: "+err.Error())
+			return
+		}
+		salary, err := h.Query.GetMonthlySalary(tx, emp.ID, run.Month, run.Year)
+		if err != nil {
+			utils.RespondWithError(c, 500, "Failed to calculate salary: "+err.Error())
+			return
 		}
 
 		// Calculate absent days for this specific month only
@@ -236,7 +269,10 @@ func (h *HandlerFunc) FinalizePayroll(c *gin.Context) {
 			return
 		}
 
-		deduction := salary / float64(workingDays) * absentDays
+		deduction := 0.0
+		if emp.DeductAbsences {
+			deduction = salary / float64(workingDays) * absentDays
+		}
 		net := salary - deduction
 
 		pID := uuid.New()
@@ -251,6 +287,12 @@ func (h *HandlerFunc) FinalizePayroll(c *gin.Context) {
 			return
 		}
 
+		// Pay pending arrears of backdated salary revisions with this payslip
+		if _, err := h.Query.SettleArrears(tx, emp.ID, pID); err != nil {
+			utils.RespondWithError(c, 500, "Failed to settle arrears: "+err.Error())
+			return
+		}
+
 		payslipIDs = append(payslipIDs, pID)
 	}
 
@@ -292,13 +334,14 @@ func (h *HandlerFunc) GetPayslipPDF(c *gin.Context) {
 		WorkingDays  int       `db:"working_days"`
 		AbsentDays   float64   `db:"absent_days"`
 		Deductions   float64   `db:"deduction_amount"`
+		Arrears      float64   `db:"arrears_amount"`
 		NetSalary    float64   `db:"net_salary"`
 	}
 
 	err = h.Query.DB.Get(&payslip, `
 		SELECT e.id as employee_id, e.full_name, e.email, 
 		       p.basic_salary, p.working_days, p.absent_days, 
-		       p.deduction_amount, p.net_salary,
+		       p.deduction_amount, p.arrears_amount, p.net_salary,
 		       pr.month, pr.year
 		FROM Tbl_Payslip p
 		JOIN Tbl_Employee e ON e.id = p.employee_id
@@ -310,6 +353,12 @@ func (h *HandlerFunc) GetPayslipPDF(c *gin.Context) {
 		return
 	}
 
+	// Only the owner or roles with payroll.view_all can download a payslip
+	if payslip.EmployeeID.String() != c.GetString("user_id") && !h.HasPermission(c, constant.PermPayrollViewAll) {
+		c.JSON(403, gin.H{"error": "You can only download your own payslips"})
+		return
+	}
+
 	// Create PDF with improved design
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.AddPage()
@@ -400,11 +449,15 @@ func (h *HandlerFunc) GetPayslipPDF(c *gin.Context) {
 	pdf.SetFont("Arial", "", 11)
 	pdf.CellFormat(130, 9, "  Basic Salary", "1", 0, "L", false, 0, "")
 	pdf.CellFormat(50, 9, fmt.Sprintf("%.2f", payslip.BasicSalary), "1", 1, "R", false, 0, "")
+	if payslip.Arrears != 0 {
+		pdf.CellFormat(130, 9, "  Salary Arrears (backdated revision)", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(50, 9, fmt.Sprintf("%.2f", payslip.Arrears), "1", 1, "R", false, 0, "")
+	}
 
 	pdf.SetFont("Arial", "B", 11)
 	pdf.SetFillColor(232, 245, 233)
 	pdf.CellFormat(130, 9, "  GROSS EARNINGS", "1", 0, "L", true, 0, "")
-	pdf.CellFormat(50, 9, fmt.Sprintf("%.2f", payslip.BasicSalary), "1", 1, "R", true, 0, "")
+	pdf.CellFormat(50, 9, fmt.Sprintf("%.2f", payslip.BasicSalary+payslip.Arrears), "1", 1, "R", true, 0, "")
 
 	// ========================================
 	// DEDUCTIONS SECTION
@@ -472,13 +525,19 @@ func (h *HandlerFunc) GetPayslipPDF(c *gin.Context) {
 
 	pdf.SetFont("Arial", "", 10)
 	pdf.Ln(2)
+	netLine := fmt.Sprintf("Net Salary = Basic Salary - Leave Deduction = %.2f - %.2f = %.2f",
+		payslip.BasicSalary, payslip.Deductions, payslip.NetSalary)
+	if payslip.Arrears != 0 {
+		netLine = fmt.Sprintf("Net Salary = Basic Salary - Leave Deduction + Arrears = %.2f - %.2f + %.2f = %.2f",
+			payslip.BasicSalary, payslip.Deductions, payslip.Arrears, payslip.NetSalary)
+	}
 	pdf.MultiCell(0, 6, fmt.Sprintf(
 		"Per Day Salary = Basic Salary / Working Days = %.2f / %d = %.2f\n"+
 			"Leave Deduction = Per Day Salary x Absent Days = %.2f x %.1f = %.2f\n"+
-			"Net Salary = Basic Salary - Leave Deduction = %.2f - %.2f = %.2f",
+			"%s",
 		payslip.BasicSalary, payslip.WorkingDays, payslip.BasicSalary/float64(payslip.WorkingDays),
 		payslip.BasicSalary/float64(payslip.WorkingDays), payslip.AbsentDays, payslip.Deductions,
-		payslip.BasicSalary, payslip.Deductions, payslip.NetSalary,
+		netLine,
 	), "", "L", false)
 
 	// ========================================
@@ -513,18 +572,11 @@ func (h *HandlerFunc) GetPayslipPDF(c *gin.Context) {
 }
 
 func (h *HandlerFunc) GetFinalizedPayslips(c *gin.Context) {
-	roleValue, ok := c.Get("role")
-	if !ok {
-		utils.RespondWithError(c, 500, "Failed to get role")
-		return
-	}
-	role := roleValue.(string)
-
 	var rows *sql.Rows
 	var err error
 
-	// 🌟 If Employee or Manager -> only their own slips
-	if role == "EMPLOYEE" || role == "MANAGER" {
+	// 🌟 Without payroll.view_all -> only their own slips
+	if !h.HasPermission(c, constant.PermPayrollViewAll) {
 		empIDValue, ok := c.Get("user_id")
 		if !ok {
 			utils.RespondWithError(c, 500, "Failed to get employee ID")
@@ -545,8 +597,13 @@ func (h *HandlerFunc) GetFinalizedPayslips(c *gin.Context) {
 		}
 		rows, err = h.Query.GetFinalizedPayslipsByEmployee(empID)
 	} else {
-		// 🌟 SuperAdmin / Admin -> all slips
-		rows, err = h.Query.GetAllFinalizedPayslips()
+		// 🌟 SuperAdmin / Admin -> all slips, optionally of one department
+		departmentID, filterErr := departmentFilter(c)
+		if filterErr != nil {
+			utils.RespondWithError(c, 400, filterErr.Error())
+			return
+		}
+		rows, err = h.Query.GetAllFinalizedPayslips(departmentID)
 	}
 
 	if err != nil {
@@ -573,6 +630,7 @@ func (h *HandlerFunc) GetFinalizedPayslips(c *gin.Context) {
 		WorkingDays     int       `json:"working_days"`
 		AbsentDays      float64   `json:"absent_days"`
 		DeductionAmount float64   `json:"deduction_amount"`
+		ArrearsAmount   float64   `json:"arrears_amount"`
 		NetSalary       float64   `json:"net_salary"`
 		PDFPath         string    `json:"pdf_path"`
 		Calculation     string    `json:"calculation"`
@@ -594,6 +652,7 @@ func (h *HandlerFunc) GetFinalizedPayslips(c *gin.Context) {
 			&slip.WorkingDays,
 			&slip.AbsentDays,
 			&slip.DeductionAmount,
+			&slip.ArrearsAmount,
 			&slip.NetSalary,
 			&slip.PDFPath,
 			&slip.Calculation,
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// RolePermissions returns the set of permission codes granted to a role,
+// served from a short-lived cache
+func (h *HandlerFunc) RolePermissions(role string) (map[string]bool, error) {
+	if perms, ok := h.Permissions.Get(role); ok {
+		return perms, nil
+	}
+
+	codes, err := h.Query.GetRolePermissionCodes(role)
+	if err != nil {
+		return nil, err
+	}
+	perms := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		perms[code] = true
+	}
+	h.Permissions.Set(role, perms)
+	return perms, nil
+}
+
+// RequestPermissions returns the permissions of the current request: the
+// role's permissions, narrowed to the key's scopes for API key requests
+func (h *HandlerFunc) RequestPermissions(c *gin.Context) (map[string]bool, error) {
+	perms, err := h.RolePermissions(c.GetString("role"))
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, ok := c.Get("api_key_scopes")
+	if !ok {
+		return perms, nil
+	}
+	scoped := map[string]bool{}
+	for _, code := range scopes.([]string) {
+		if perms[code] {
+			scoped[code] = true
+		}
+	}
+	return scoped, nil
+}
+
+// HasPermission reports whether the current request has the permission.
+// Lookup errors are treated as "no permission".
+func (h *HandlerFunc) HasPermission(c *gin.Context, code string) bool {
+	perms, err := h.RequestPermissions(c)
+	if err != nil {
+		fmt.Printf("Failed to load permissions for role %s: %v\n", c.GetString("role"), err)
+		return false
+	}
+	return perms[code]
+}
+
+// GetPermissions - GET /api/permissions
+// Lists the permission catalog and the current grants of every role
+func (h *HandlerFunc) GetPermissions(c *gin.Context) {
+	permissions, err := h.Query.GetAllPermissions()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch permissions: "+err.Error())
+		return
+	}
+
+	grants, err := h.Query.GetAllRolePermissions()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch role permissions: "+err.Error())
+		return
+	}
+
+	roles := map[string][]string{}
+	for _, g := range grants {
+		roles[g.Role] = append(roles[g.Role], g.Permission)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"permissions": permissions,
+		"roles":       roles,
+	})
+}
+
+// GrantRolePermission - POST /api/permissions/roles/:role
+func (h *HandlerFunc) GrantRolePermission(c *gin.Context) {
+	h.changeRolePermission(c, true)
+}
+
+// RevokeRolePermission - DELETE /api/permissions/roles/:role/:permission
+func (h *HandlerFunc) RevokeRolePermission(c *gin.Context) {
+	h.changeRolePermission(c, false)
+}
+
+func (h *HandlerFunc) changeRolePermission(c *gin.Context, grant bool) {
+	// 1️ Current user for logging
+	adminID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "invalid employee UUID")
+		return
+	}
+
+	// 2️ Role and permission code
+	role := strings.ToUpper(strings.TrimSpace(c.Param("role")))
+	code := c.Param("permission")
+	if grant {
+		var input models.RolePermissionInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "permission is required")
+			return
+		}
+		code = input.Permission
+	}
+	code = strings.TrimSpace(code)
+
+	if _, err := h.Query.GetRoleID(role); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "role not found")
+		return
+	}
+	exists, err := h.Query.PermissionExists(code)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to check permission: "+err.Error())
+		return
+	}
+	if !exists {
+		utils.RespondWithError(c, http.StatusNotFound, "permission not found")
+		return
+	}
+
+	// 3️ Never let SUPERADMIN lose the ability to manage permissions
+	if !grant && role == constant.ROLE_SUPER_ADMIN && code == constant.PermPermissionManage {
+		utils.RespondWithError(c, http.StatusBadRequest, "cannot revoke permission.manage from SUPERADMIN")
+		return
+	}
+
+	// 4️ Apply change + log
+	changed := false
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		var err error
+		action := constant.ActionCreate
+		if grant {
+			changed, err = h.Query.GrantRolePermission(tx, role, code)
+		} else {
+			action = constant.ActionDelete
+			changed, err = h.Query.RevokeRolePermission(tx, role, code)
+		}
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update role permission: "+err.Error())
+		}
+		if !changed {
+			return nil
+		}
+
+		data := utils.NewCommon(constant.ComponentPermission, action, adminID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.Permissions.Invalidate(role)
+
+	message := "permission granted successfully"
+	if !grant {
+		message = "permission revoked successfully"
+	}
+	if !changed {
+		message = "no change, role permissions already up to date"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    message,
+		"role":       role,
+		"permission": code,
+	})
+}
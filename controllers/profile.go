@@ -0,0 +1,380 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/fieldcrypt"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// profileGroup decides who may edit a profile field
+type profileGroup int
+
+const (
+	profileContact   profileGroup = iota // the employee or profile.update
+	profilePersonal                      // profile.update
+	profileSensitive                     // profile.sensitive, stored encrypted
+)
+
+// profileField describes one editable profile field
+type profileField struct {
+	name      string // JSON name
+	column    string // tbl_employee_profile column
+	group     profileGroup
+	value     func(in *models.UpdateProfileInput) *string
+	normalize func(string) string // optional
+	valid     func(string) bool
+	message   string
+}
+
+var (
+	phonePattern      = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{6,19}$`)
+	postalCodePattern = regexp.MustCompile(`^[A-Za-z0-9 -]{3,10}$`)
+	bloodGroupPattern = regexp.MustCompile(`^(A|B|AB|O)[+-]$`)
+	ifscPattern       = regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+	accountPattern    = regexp.MustCompile(`^[0-9]{9,18}$`)
+	panPattern        = regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+	aadhaarPattern    = regexp.MustCompile(`^[0-9]{12}$`)
+	passportPattern   = regexp.MustCompile(`^[A-Z0-9]{6,9}$`)
+)
+
+func maxLength(n int) func(string) bool {
+	return func(s string) bool { return len(s) <= n }
+}
+
+// compactUpper removes spaces and upper-cases IDs like "abcde 1234 f"
+func compactUpper(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+}
+
+var profileFields = []profileField{
+	{"phone", "phone", profileContact, func(in *models.UpdateProfileInput) *string { return in.Phone }, nil, phonePattern.MatchString, "phone must be a valid phone number"},
+	{"personal_email", "personal_email", profileContact, func(in *models.UpdateProfileInput) *string { return in.PersonalEmail }, strings.ToLower, utils.IsValidEmail, "personal_email must be a valid email"},
+	{"address_line1", "address_line1", profileContact, func(in *models.UpdateProfileInput) *string { return in.AddressLine1 }, nil, maxLength(200), "address_line1 is too long"},
+	{"address_line2", "address_line2", profileContact, func(in *models.UpdateProfileInput) *string { return in.AddressLine2 }, nil, maxLength(200), "address_line2 is too long"},
+	{"city", "city", profileContact, func(in *models.UpdateProfileInput) *string { return in.City }, nil, maxLength(100), "city is too long"},
+	{"state", "state", profileContact, func(in *models.UpdateProfileInput) *string { return in.State }, nil, maxLength(100), "state is too long"},
+	{"postal_code", "postal_code", profileContact, func(in *models.UpdateProfileInput) *string { return in.PostalCode }, nil, postalCodePattern.MatchString, "postal_code is invalid"},
+	{"country", "country", profileContact, func(in *models.UpdateProfileInput) *string { return in.Country }, nil, maxLength(100), "country is too long"},
+
+	{"date_of_birth", "date_of_birth", profilePersonal, func(in *models.UpdateProfileInput) *string { return in.DateOfBirth }, nil, validDateOfBirth, "date_of_birth must be a past date (YYYY-MM-DD)"},
+	{"gender", "gender", profilePersonal, func(in *models.UpdateProfileInput) *string { return in.Gender }, strings.ToLower, validGender, "gender must be male, female or other"},
+	{"blood_group", "blood_group", profilePersonal, func(in *models.UpdateProfileInput) *string { return in.BloodGroup }, strings.ToUpper, bloodGroupPattern.MatchString, "blood_group must be like A+, O- or AB+"},
+
+	{"bank_account_holder", "bank_account_holder", profileSensitive, func(in *models.UpdateProfileInput) *string { return in.BankAccountHolder }, nil, maxLength(100), "bank_account_holder is too long"},
+	{"bank_name", "bank_name", profileSensitive, func(in *models.UpdateProfileInput) *string { return in.BankName }, nil, maxLength(100), "bank_name is too long"},
+	{"bank_ifsc", "bank_ifsc", profileSensitive, func(in *models.UpdateProfileInput) *string { return in.BankIFSC }, compactUpper, ifscPattern.MatchString, "bank_ifsc must be a valid IFSC code"},
+	{"bank_account_number", "bank_account_number_enc", profileSensitive, func(in *models.UpdateProfileInput) *string { return in.BankAccountNumber }, compactUpper, accountPattern.MatchString, "bank_account_number must be 9 to 18 digits"},
+	{"pan_number", "pan_number_enc", profileSensitive, func(in *models.UpdateProfileInput) *string { return in.PANNumber }, compactUpper, panPattern.MatchString, "pan_number must be a valid PAN like ABCDE1234F"},
+	{"aadhaar_number", "aadhaar_number_enc", profileSensitive, func(in *models.UpdateProfileInput) *string { return in.AadhaarNumber }, compactUpper, aadhaarPattern.MatchString, "aadhaar_number must be 12 digits"},
+	{"passport_number", "passport_number_enc", profileSensitive, func(in *models.UpdateProfileInput) *string { return in.PassportNumber }, compactUpper, passportPattern.MatchString, "passport_number is invalid"},
+}
+
+func validDateOfBirth(s string) bool {
+	date, err := time.Parse("2006-01-02", s)
+	return err == nil && date.Before(time.Now()) && date.Year() >= 1900
+}
+
+func validGender(s string) bool {
+	return s == "male" || s == "female" || s == "other"
+}
+
+// encrypted reports whether the field's column holds ciphertext
+func (f profileField) encrypted() bool {
+	return strings.HasSuffix(f.column, "_enc")
+}
+
+// GetEmployeeProfile - GET /api/employee/:id/profile?reveal=true
+// The employee and profile.view_all holders can view the profile. Bank
+// account and ID numbers are masked; reveal=true needs profile.sensitive and
+// is written to the activity log.
+func (h *HandlerFunc) GetEmployeeProfile(c *gin.Context) {
+	// 1️ Current user and target employee
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	empID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	// 2️ Access
+	if empID != currentUserID && !h.HasPermission(c, constant.PermProfileViewAll) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to view this profile")
+		return
+	}
+	reveal, _ := strconv.ParseBool(c.DefaultQuery("reveal", "false"))
+	if reveal && !h.HasPermission(c, constant.PermProfileSensitive) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to view bank and ID numbers")
+		return
+	}
+
+	if _, err := h.Query.GetEmployeeCurrentRole(empID.String()); err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// 3️ Load
+	row, err := h.Query.GetEmployeeProfile(empID)
+	if err != nil && err != sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch profile: "+err.Error())
+		return
+	}
+	row.EmployeeID = empID
+
+	profile, err := h.buildProfile(row, reveal)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if profile.EmergencyContacts, err = h.Query.GetEmergencyContacts(empID); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch emergency contacts: "+err.Error())
+		return
+	}
+
+	// 4️ Record who saw the unmasked numbers
+	if reveal {
+		err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+			data := utils.NewCommon(constant.ComponentProfile, constant.ActionReveal, currentUserID).WithTarget(empID)
+			if err := common.AddLog(data, tx); err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+			}
+			return nil
+		})
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "profile fetched",
+		"profile": profile,
+	})
+}
+
+// buildProfile decrypts the sensitive columns, masking them unless reveal
+func (h *HandlerFunc) buildProfile(row repositories.EmployeeProfileRow, reveal bool) (models.EmployeeProfile, error) {
+	profile := models.EmployeeProfile{
+		EmployeeID:        row.EmployeeID,
+		Phone:             row.Phone,
+		PersonalEmail:     row.PersonalEmail,
+		AddressLine1:      row.AddressLine1,
+		AddressLine2:      row.AddressLine2,
+		City:              row.City,
+		State:             row.State,
+		PostalCode:        row.PostalCode,
+		Country:           row.Country,
+		DateOfBirth:       row.DateOfBirth,
+		Gender:            row.Gender,
+		BloodGroup:        row.BloodGroup,
+		BankAccountHolder: row.BankAccountHolder,
+		BankName:          row.BankName,
+		BankIFSC:          row.BankIFSC,
+		SensitiveMasked:   !reveal,
+	}
+	if !row.UpdatedAt.IsZero() {
+		profile.UpdatedAt = &row.UpdatedAt
+	}
+
+	sensitive := []struct {
+		column string
+		stored *string
+		out    **string
+	}{
+		{"bank_account_number_enc", row.BankAccountNumberEnc, &profile.BankAccountNumber},
+		{"pan_number_enc", row.PANNumberEnc, &profile.PANNumber},
+		{"aadhaar_number_enc", row.AadhaarNumberEnc, &profile.AadhaarNumber},
+		{"passport_number_enc", row.PassportNumberEnc, &profile.PassportNumber},
+	}
+	for _, field := range sensitive {
+		if field.stored == nil {
+			continue
+		}
+		value, err := h.FieldCrypt.Decrypt(*field.stored, profileCryptContext(row.EmployeeID, field.column))
+		if err != nil {
+			return profile, err
+		}
+		if !reveal {
+			value = maskValue(value)
+		}
+		*field.out = &value
+	}
+	return profile, nil
+}
+
+// UpdateEmployeeProfile - PATCH /api/employee/:id/profile
+// Employees edit their own contact details and emergency contacts;
+// profile.update is needed for others and for personal details, and
+// profile.sensitive for bank account and ID numbers.
+func (h *HandlerFunc) UpdateEmployeeProfile(c *gin.Context) {
+	// 1️ Current user and target employee
+	currentUserID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+	empID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	// 2️ Bind input JSON
+	var input models.UpdateProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+
+	// 3️ Target must exist; SUPERADMIN profiles are protected like the rest of the account
+	targetRole, err := h.Query.GetEmployeeCurrentRole(empID.String())
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	self := empID == currentUserID
+	if !self && targetRole == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to modify SUPERADMIN users")
+		return
+	}
+
+	allowed := map[profileGroup]bool{
+		profileContact:   self || h.HasPermission(c, constant.PermProfileUpdate),
+		profilePersonal:  h.HasPermission(c, constant.PermProfileUpdate),
+		profileSensitive: h.HasPermission(c, constant.PermProfileSensitive),
+	}
+
+	// 4️ Validate, check access and encrypt field by field
+	columns := map[string]any{}
+	updated := []string{}
+	for _, field := range profileFields {
+		value := field.value(&input)
+		if value == nil {
+			continue
+		}
+		if !allowed[field.group] {
+			utils.RespondWithError(c, http.StatusForbidden, "not permitted to update "+field.name)
+			return
+		}
+
+		v := strings.TrimSpace(*value)
+		if field.normalize != nil {
+			v = field.normalize(v)
+		}
+		updated = append(updated, field.name)
+		if v == "" {
+			columns[field.column] = nil
+			continue
+		}
+		if !field.valid(v) {
+			utils.RespondWithError(c, http.StatusBadRequest, field.message)
+			return
+		}
+
+		if field.encrypted() {
+			if !h.FieldCrypt.Enabled() {
+				utils.RespondWithError(c, http.StatusServiceUnavailable, fieldcrypt.ErrDisabled.Error())
+				return
+			}
+			if v, err = h.FieldCrypt.Encrypt(v, profileCryptContext(empID, field.column)); err != nil {
+				utils.RespondWithError(c, http.StatusInternalServerError, "failed to encrypt "+field.name)
+				return
+			}
+		}
+		columns[field.column] = v
+	}
+
+	if input.EmergencyContacts != nil {
+		if !allowed[profileContact] {
+			utils.RespondWithError(c, http.StatusForbidden, "not permitted to update emergency_contacts")
+			return
+		}
+		for i := range *input.EmergencyContacts {
+			contact := &(*input.EmergencyContacts)[i]
+			contact.Name = strings.TrimSpace(contact.Name)
+			contact.Relationship = strings.TrimSpace(contact.Relationship)
+			contact.Phone = strings.TrimSpace(contact.Phone)
+			if contact.Name == "" || contact.Relationship == "" {
+				utils.RespondWithError(c, http.StatusBadRequest, "emergency_contacts["+strconv.Itoa(i)+"]: name and relationship are required")
+				return
+			}
+			if !phonePattern.MatchString(contact.Phone) {
+				utils.RespondWithError(c, http.StatusBadRequest, "emergency_contacts["+strconv.Itoa(i)+"]: phone must be a valid phone number")
+				return
+			}
+		}
+		updated = append(updated, "emergency_contacts")
+	}
+
+	if len(updated) == 0 {
+		utils.RespondWithError(c, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	// 5️ Save
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.UpsertEmployeeProfile(tx, empID, currentUserID, columns); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update profile: "+err.Error())
+		}
+		if input.EmergencyContacts != nil {
+			if err := h.Query.ReplaceEmergencyContacts(tx, empID, *input.EmergencyContacts); err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError, "failed to update emergency contacts: "+err.Error())
+			}
+		}
+
+		data := utils.NewCommon(constant.ComponentProfile, constant.ActionUpdate, currentUserID).WithTarget(empID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "profile updated successfully",
+		"employee_id": empID,
+		"updated":     updated,
+	})
+}
+
+// profileCryptContext binds ciphertext to its employee and column
+func profileCryptContext(empID uuid.UUID, column string) string {
+	return "tbl_employee_profile:" + empID.String() + ":" + column
+}
+
+// maskValue keeps the last four characters, e.g. "******1234"
+func maskValue(s string) string {
+	if len(s) <= 4 {
+		return strings.Repeat("*", len(s))
+	}
+	return strings.Repeat("*", len(s)-4) + s[len(s)-4:]
+}
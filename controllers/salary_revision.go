@@ -0,0 +1,307 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// today is the current date at midnight UTC, comparable with parsed YYYY-MM-DD dates
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// saveOpeningSalary records a new employee's salary as their first revision,
+// in force from the joining date (today when it is not set)
+func (h *HandlerFunc) saveOpeningSalary(tx *sqlx.Tx, empID uuid.UUID, salary *float64, joiningDate *time.Time, approvedBy *uuid.UUID) error {
+	amount := 0.0
+	if salary != nil {
+		amount = *salary
+	}
+	effectiveFrom := today()
+	if joiningDate != nil {
+		y, m, d := joiningDate.Date()
+		effectiveFrom = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	_, err := h.Query.SaveSalaryRevision(tx, empID, amount, effectiveFrom, "Opening salary", approvedBy)
+	return err
+}
+
+// currentSalaryRevision returns the revision in force today from a history
+// sorted latest first, or nil
+func currentSalaryRevision(revisions []models.SalaryRevision) *models.SalaryRevision {
+	for i := range revisions {
+		if !revisions[i].EffectiveFrom.After(today()) {
+			return &revisions[i]
+		}
+	}
+	return nil
+}
+
+// recordArrears compares every finalized payslip of the employee from the
+// month of from on with what it would pay under the current salary history,
+// and records the difference not owed yet as arrears
+func (h *HandlerFunc) recordArrears(tx *sqlx.Tx, emp *models.EmployeeInput, revisionID uuid.UUID, from time.Time) ([]models.SalaryArrear, error) {
+	arrears := []models.SalaryArrear{}
+	payslips, err := h.Query.GetPaidPayslipsFrom(tx, *emp.ID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range payslips {
+		revised, err := h.Query.GetMonthlySalary(tx, *emp.ID, p.Month, p.Year)
+		if err != nil {
+			return nil, err
+		}
+		// Same calculation as the payroll run, with the days of the payslip.
+		// Absences are only taken off again if the payslip deducted them.
+		net := revised
+		if p.Deducted && p.WorkingDays > 0 {
+			net -= revised / float64(p.WorkingDays) * p.AbsentDays
+		}
+		amount := math.Round((net-p.PaidNet-p.ArrearsRecorded)*100) / 100
+		if amount == 0 {
+			continue
+		}
+
+		arrear := models.SalaryArrear{
+			EmployeeID:    *emp.ID,
+			EmployeeName:  emp.FullName,
+			RevisionID:    &revisionID,
+			PayslipID:     p.ID,
+			Month:         p.Month,
+			Year:          p.Year,
+			PaidSalary:    p.BasicSalary,
+			RevisedSalary: math.Round(revised*100) / 100,
+			Amount:        amount,
+		}
+		if err := h.Query.CreateSalaryArrear(tx, &arrear); err != nil {
+			return nil, err
+		}
+		arrears = append(arrears, arrear)
+	}
+	return arrears, nil
+}
+
+// GetSalaryRevisions - GET /api/employee/:id/salary-revisions
+// Salary history, latest first (Self/salary.revise/payroll.view_all)
+func (h *HandlerFunc) GetSalaryRevisions(c *gin.Context) {
+	currentUserID, emp, ok := h.targetEmployee(c, false)
+	if !ok {
+		return
+	}
+	if *emp.ID != currentUserID && !h.HasPermission(c, constant.PermSalaryRevise) && !h.HasPermission(c, constant.PermPayrollViewAll) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to view the salary history of this employee")
+		return
+	}
+
+	revisions, err := h.Query.GetSalaryRevisions(h.Query.DB, *emp.ID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch salary revisions: "+err.Error())
+		return
+	}
+
+	scheduled := []models.SalaryRevision{}
+	for _, rev := range revisions {
+		if rev.EffectiveFrom.After(today()) {
+			scheduled = append(scheduled, rev)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "salary revisions fetched successfully",
+		"employee_id": emp.ID,
+		"current":     currentSalaryRevision(revisions),
+		"scheduled":   scheduled,
+		"revisions":   revisions,
+	})
+}
+
+// CreateSalaryRevision - POST /api/employee/:id/salary-revisions
+// Schedules a salary from effective_from. A date in the past is a backdated
+// revision: finalized payslips from that month on are recomputed and the
+// difference is recorded as arrears for the next payroll. Requires salary.revise
+func (h *HandlerFunc) CreateSalaryRevision(c *gin.Context) {
+	// 1️ Target and input
+	currentUserID, emp, ok := h.targetEmployee(c, true)
+	if !ok {
+		return
+	}
+	var input models.SalaryRevisionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if err := models.Validate.Struct(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "validation error: "+err.Error())
+		return
+	}
+	effectiveFrom, _ := time.Parse("2006-01-02", input.EffectiveFrom)
+	if emp.JoiningDate != nil && effectiveFrom.Before(*emp.JoiningDate) {
+		utils.RespondWithError(c, http.StatusBadRequest, "effective_from must not be before the joining date")
+		return
+	}
+	salary := math.Round(*input.Salary*100) / 100
+
+	// 2️ Save the revision, apply it if it is in force and work out arrears
+	var revisionID uuid.UUID
+	arrears := []models.SalaryArrear{}
+	err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.LockSalaryHistory(tx, *emp.ID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to lock salary history: "+err.Error())
+		}
+		exists, err := h.Query.SalaryRevisionExists(tx, *emp.ID, effectiveFrom)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to check salary revisions: "+err.Error())
+		}
+		if exists {
+			return utils.CustomErr(c, http.StatusConflict, "a salary revision already takes effect on "+input.EffectiveFrom)
+		}
+
+		if revisionID, err = h.Query.SaveSalaryRevision(tx, *emp.ID, salary, effectiveFrom, input.Reason, &currentUserID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to save salary revision: "+err.Error())
+		}
+		if _, err := h.Query.SyncCurrentSalaries(tx, emp.ID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to update current salary: "+err.Error())
+		}
+		if arrears, err = h.recordArrears(tx, emp, revisionID, effectiveFrom); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to compute arrears: "+err.Error())
+		}
+
+		if err := common.AddLog(utils.NewCommon(constant.ComponentSalary, constant.ActionCreate, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	arrearsTotal := 0.0
+	for _, a := range arrears {
+		arrearsTotal += a.Amount
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message":        "salary revision saved successfully",
+		"revision_id":    revisionID,
+		"employee_id":    emp.ID,
+		"salary":         salary,
+		"effective_from": input.EffectiveFrom,
+		"scheduled":      effectiveFrom.After(today()),
+		"arrears":        arrears,
+		"arrears_total":  math.Round(arrearsTotal*100) / 100,
+	})
+}
+
+// CancelSalaryRevision - DELETE /api/employee/:id/salary-revisions/:revisionId
+// Only revisions that are not in force yet can be cancelled; correct a past
+// one with a new revision. Requires salary.revise
+func (h *HandlerFunc) CancelSalaryRevision(c *gin.Context) {
+	currentUserID, emp, ok := h.targetEmployee(c, true)
+	if !ok {
+		return
+	}
+	revisionID, err := uuid.Parse(c.Param("revisionId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid revision ID")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		rev, err := h.Query.GetSalaryRevision(tx, *emp.ID, revisionID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "salary revision not found")
+		} else if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to fetch salary revision: "+err.Error())
+		}
+		if !rev.EffectiveFrom.After(today()) {
+			return utils.CustomErr(c, http.StatusConflict, "salary revision is already in force, record a new revision instead")
+		}
+
+		if err := h.Query.DeleteSalaryRevision(tx, rev.ID); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to cancel salary revision: "+err.Error())
+		}
+		if err := common.AddLog(utils.NewCommon(constant.ComponentSalary, constant.ActionCancel, currentUserID), tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "salary revision cancelled successfully",
+		"revision_id": revisionID,
+	})
+}
+
+// ListSalaryArrears - GET /api/payroll/arrears?status=PENDING&employee_id=
+// Requires payroll.view_all
+func (h *HandlerFunc) ListSalaryArrears(c *gin.Context) {
+	status := strings.ToUpper(c.Query("status"))
+	if status != "" && !slices.Contains([]string{"PENDING", "PAID"}, status) {
+		utils.RespondWithError(c, http.StatusBadRequest, "status must be PENDING or PAID")
+		return
+	}
+	var empID *uuid.UUID
+	if s := c.Query("employee_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+			return
+		}
+		empID = &id
+	}
+
+	arrears, err := h.Query.ListSalaryArrears(status, empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch arrears: "+err.Error())
+		return
+	}
+
+	total := 0.0
+	for _, a := range arrears {
+		total += a.Amount
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "arrears fetched successfully",
+		"total":        len(arrears),
+		"total_amount": math.Round(total*100) / 100,
+		"arrears":      arrears,
+	})
+}
+
+// StartSalaryRevisionJob copies revisions that came into force to
+// Tbl_Employee.salary, right away and then every interval
+func (h *HandlerFunc) StartSalaryRevisionJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			changed, err := h.Query.SyncCurrentSalaries(h.Query.DB, nil)
+			if err != nil {
+				fmt.Printf("Failed to apply salary revisions: %v\n", err)
+			} else if len(changed) > 0 {
+				fmt.Printf("Applied salary revisions for %d employees\n", len(changed))
+			}
+			<-ticker.C
+		}
+	}()
+}
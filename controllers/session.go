@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// GetMySessions - GET /api/auth/sessions
+// Lists the devices the current user is signed in on.
+func (h *HandlerFunc) GetMySessions(c *gin.Context) {
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	sessions, err := h.Query.GetEmployeeSessions(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch sessions: "+err.Error())
+		return
+	}
+
+	current := c.GetString("session_id")
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.String() == current
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "sessions fetched",
+		"sessions": sessions,
+	})
+}
+
+// RevokeMySession - DELETE /api/auth/sessions/:id
+// Signs the current user out of one of their sessions.
+func (h *HandlerFunc) RevokeMySession(c *gin.Context) {
+	empID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid user ID")
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid session ID")
+		return
+	}
+
+	revoked, err := h.Query.RevokeSession(empID, sessionID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to revoke session: "+err.Error())
+		return
+	}
+	if !revoked {
+		utils.RespondWithError(c, http.StatusNotFound, "session not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "session signed out successfully",
+		"id":      sessionID,
+	})
+}
+
+// GetEmployeeSessions - GET /api/employee/:id/sessions
+func (h *HandlerFunc) GetEmployeeSessions(c *gin.Context) {
+	empID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	if _, err := h.Query.GetEmployeeByID(empID); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+
+	sessions, err := h.Query.GetEmployeeSessions(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch sessions: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "sessions fetched",
+		"employee_id": empID,
+		"sessions":    sessions,
+	})
+}
+
+// RevokeEmployeeSessions - DELETE /api/employee/:id/sessions
+// Forces an employee to sign out on every device.
+func (h *HandlerFunc) RevokeEmployeeSessions(c *gin.Context) {
+	adminID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "invalid employee UUID")
+		return
+	}
+
+	empID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	emp, err := h.Query.GetEmployeeByID(empID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+
+	if emp.Role == "SUPERADMIN" && !h.HasPermission(c, constant.PermEmployeeManageSuperadmin) {
+		utils.RespondWithError(c, http.StatusForbidden, "not permitted to modify SUPERADMIN users")
+		return
+	}
+
+	if err := h.Query.RevokeAllEmployeeTokens(empID); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to revoke sessions: "+err.Error())
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		data := utils.NewCommon(constant.ComponentAuth, constant.ActionSignOut, adminID).WithTarget(empID)
+		if err := common.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "employee signed out of all sessions",
+		"employee_id": empID,
+	})
+}
@@ -3,6 +3,8 @@ package controllers
 import (
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,13 +17,6 @@ import (
 
 // GetCompanySettings - GET /api/settings/company
 func (h *HandlerFunc) GetCompanySettings(c *gin.Context) {
-	// Only SUPERADMIN and ADMIN allowed
-	roleRaw, _ := c.Get("role")
-	role := roleRaw.(string)
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, 403, "Not authorized to view settings")
-		return
-	}
 	var settings models.CompanySettings
 	err := h.Query.GetCompanySettings(&settings)
 	if err != nil {
@@ -37,19 +32,37 @@ func (h *HandlerFunc) GetCompanySettings(c *gin.Context) {
 
 // UpdateCompanySettings - PUT /api/settings/company
 func (h *HandlerFunc) UpdateCompanySettings(c *gin.Context) {
-	// Only SUPERADMIN and ADMIN allowed
-	roleRaw, _ := c.Get("role")
-	role := roleRaw.(string)
-	if role != "SUPERADMIN" && role != "ADMIN" && role != "HR" {
-		utils.RespondWithError(c, 403, "Not authorized to update settings")
-		return
-	}
 	var input models.CompanyField
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.RespondWithError(c, 400, "Invalid input: "+err.Error())
 		return
 	}
+	if input.MFARequiredRoles != nil {
+		// Enrollment stores an encrypted secret, so required MFA would lock these roles out
+		if len(*input.MFARequiredRoles) > 0 && !h.FieldCrypt.Enabled() {
+			utils.RespondWithError(c, http.StatusBadRequest, "mfa_required_roles needs FIELD_ENCRYPTION_KEYS to be set")
+			return
+		}
+		for i, r := range *input.MFARequiredRoles {
+			r = strings.ToUpper(strings.TrimSpace(r))
+			if _, err := h.Query.GetRoleID(r); err != nil {
+				utils.RespondWithError(c, 400, "Invalid role in mfa_required_roles: "+r)
+				return
+			}
+			(*input.MFARequiredRoles)[i] = r
+		}
+	}
+	if input.AllowedEmailDomains != nil {
+		for i, d := range *input.AllowedEmailDomains {
+			domain := utils.NormalizeDomain(d)
+			if domain == "" {
+				utils.RespondWithError(c, 400, "Invalid domain in allowed_email_domains: "+d)
+				return
+			}
+			(*input.AllowedEmailDomains)[i] = domain
+		}
+	}
 	empIDRaw, ok := c.Get("user_id")
 	if !ok {
 		utils.RespondWithError(c, http.StatusUnauthorized, "Employee ID missing")
@@ -75,7 +88,7 @@ func (h *HandlerFunc) UpdateCompanySettings(c *gin.Context) {
 			return utils.CustomErr(c, 500, "Failed to fetch settings: "+err.Error())
 		}
 		//add log
-		data := utils.NewCommon(constant.CompanySettings, constant.ActionCreate, empID)
+		data := utils.NewCommon(constant.CompanySettings, constant.ActionUpdate, empID)
 
 		err = common.AddLog(data, tx)
 		if err != nil {
@@ -93,3 +106,21 @@ func (h *HandlerFunc) UpdateCompanySettings(c *gin.Context) {
 		"message": "Company settings updated successfully",
 	})
 }
+
+// checkEmailDomain returns an error message when the email's domain is not
+// in the company's allowed_email_domains ("" when it is allowed)
+func (h *HandlerFunc) checkEmailDomain(email string) (string, error) {
+	domains, err := h.Query.GetAllowedEmailDomains()
+	if err != nil {
+		return "", err
+	}
+	return emailDomainMessage(domains, email), nil
+}
+
+// emailDomainMessage checks email against already loaded allowed domains
+func emailDomainMessage(domains []string, email string) string {
+	if len(domains) == 0 || slices.Contains(domains, utils.EmailDomain(email)) {
+		return ""
+	}
+	return "email domain must be one of: " + strings.Join(domains, ", ")
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/config"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/database"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/fieldcrypt"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/keyring"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/storage"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/routes"
 )
@@ -16,14 +19,57 @@ import (
 func main() {
 	// Initialize configuration and database
 	env := config.LoadENV()
+	if err := env.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 	db := database.Connection(env)
 
 	repo := repositories.InitializeRepo(db)
 
-	handlerFunc := controllers.NewHandler(env, repo)
+	keys, err := keyring.Load(env.JWT_KEYS_FILE, env.SERACT_KEY)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	fieldCrypt, err := fieldcrypt.New(env.FIELD_ENCRYPTION_KEYS)
+	if err != nil {
+		log.Fatalf("Failed to load field encryption keys: %v", err)
+	}
+	if !fieldCrypt.Enabled() {
+		log.Println("⚠ FIELD_ENCRYPTION_KEYS is not set, bank and ID numbers cannot be stored and MFA cannot be set up")
+	}
+
+	var store storage.Storage
+	if env.STORAGE_BACKEND == "local" && env.STORAGE_LOCAL_DIR == "" {
+		log.Println("⚠ STORAGE_LOCAL_DIR is not set, employee documents are disabled")
+	} else {
+		store, err = storage.New(storage.Config{
+			Backend:     env.STORAGE_BACKEND,
+			LocalDir:    env.STORAGE_LOCAL_DIR,
+			S3Endpoint:  env.S3_ENDPOINT,
+			S3Region:    env.S3_REGION,
+			S3Bucket:    env.S3_BUCKET,
+			S3AccessKey: env.S3_ACCESS_KEY,
+			S3SecretKey: env.S3_SECRET_KEY,
+			S3UseSSL:    env.S3_USE_SSL,
+		})
+		if err != nil {
+			log.Fatalf("Failed to set up document storage: %v", err)
+		}
+	}
+
+	handlerFunc := controllers.NewHandler(env, repo, keys, fieldCrypt, store)
+	handlerFunc.StartOffboardingJob(env.OFFBOARDING_CHECK_INTERVAL)
+	handlerFunc.StartSalaryRevisionJob(env.SALARY_REVISION_CHECK_INTERVAL)
+	handlerFunc.StartProbationReminderJob(env.PROBATION_REMINDER_INTERVAL, env.PROBATION_REMINDER_DAYS)
 
 	// Create a new Gin router
 	r := gin.Default()
+	// Client IPs key the rate limits and login lockouts, so forwarded
+	// headers are only believed from our own proxies
+	if err := r.SetTrustedProxies(env.TRUSTED_PROXIES); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	models.InitValidator()
 	routes.SetupRoutes(r, handlerFunc)
 
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/controllers"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// checkImpersonation validates the real actor behind an impersonation token
+// and enforces read-only mode. It responds itself when it returns false.
+func checkImpersonation(c *gin.Context, h *controllers.HandlerFunc, claims *utils.CustomClaims, opts authOptions) bool {
+	actorID, err := uuid.Parse(claims.ImpersonatorID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid impersonation token")
+		return false
+	}
+
+	actor, err := h.LoadPrincipal(actorID)
+	if err != nil || actor.Status == "deactive" {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Impersonating user is no longer active")
+		return false
+	}
+
+	perms, err := h.RolePermissions(actor.Role)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to load permissions")
+		return false
+	}
+	if !perms[constant.PermEmployeeImpersonate] {
+		utils.RespondWithError(c, http.StatusForbidden, "Impersonation is no longer permitted")
+		return false
+	}
+
+	if claims.ReadOnly && !opts.readOnlyWrites {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			utils.RespondWithError(c, http.StatusForbidden, "Impersonation session is read-only")
+			return false
+		}
+	}
+	return true
+}
+
+// logImpersonatedRequest records every request made while impersonating,
+// against both the impersonated employee and the real actor
+func logImpersonatedRequest(c *gin.Context, h *controllers.HandlerFunc, claims *utils.CustomClaims) {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return
+	}
+	actorID, err := uuid.Parse(claims.ImpersonatorID)
+	if err != nil {
+		return
+	}
+
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	// e.g. "GET /api/leave-balances/employee/:id 200"
+	action := fmt.Sprintf("%s %s %d", c.Request.Method, path, c.Writer.Status())
+	if err := h.Query.LogImpersonatedRequest(userID, actorID, constant.ComponentImpersonation, action); err != nil {
+		log.Printf("Failed to log impersonated request %s: %v", action, err)
+	}
+}
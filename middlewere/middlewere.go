@@ -1,18 +1,65 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/controllers"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
 )
 
-// AuthMiddleware verifies Bearer JWT Token
+// authOptions says which credentials an endpoint accepts
+type authOptions struct {
+	apiKeys  bool     // accept API keys besides JWTs
+	purposes []string // accepted token purposes, "" being a normal access token
+	// readOnlyWrites lets read-only impersonation tokens make non-GET requests
+	readOnlyWrites bool
+}
+
+// AuthMiddleware verifies Bearer JWT Token (including impersonation tokens),
+// or an API key sent as "X-API-Key: <key>" or "Authorization: ApiKey <key>"
 func AuthMiddleware(h *controllers.HandlerFunc) gin.HandlerFunc {
+	return authenticate(h, authOptions{
+		apiKeys:  true,
+		purposes: []string{"", utils.TokenPurposeImpersonation},
+	})
+}
+
+// TokenAuthMiddleware only accepts the user's own access token, for endpoints
+// that manage the user's own account and sessions
+func TokenAuthMiddleware(h *controllers.HandlerFunc) gin.HandlerFunc {
+	return authenticate(h, authOptions{purposes: []string{""}})
+}
+
+// MFAEnrollmentMiddleware accepts a normal access token or the restricted
+// token Login hands out when the user's role must enroll in MFA first
+func MFAEnrollmentMiddleware(h *controllers.HandlerFunc) gin.HandlerFunc {
+	return authenticate(h, authOptions{purposes: []string{"", utils.TokenPurposeMFAEnroll}})
+}
+
+// ImpersonationMiddleware only accepts impersonation tokens, read-only or not
+func ImpersonationMiddleware(h *controllers.HandlerFunc) gin.HandlerFunc {
+	return authenticate(h, authOptions{
+		purposes:       []string{utils.TokenPurposeImpersonation},
+		readOnlyWrites: true,
+	})
+}
+
+func authenticate(h *controllers.HandlerFunc, opts authOptions) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
+		// 0. API key instead of a JWT
+		if opts.apiKeys {
+			if apiKey, ok := readAPIKey(c); ok {
+				authenticateAPIKey(c, h, apiKey)
+				return
+			}
+		}
+
 		// 1. Read Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -38,19 +85,68 @@ func AuthMiddleware(h *controllers.HandlerFunc) gin.HandlerFunc {
 			return
 		}
 
-		// 3. Validate JWT token
-		claims, err := utils.ValidateToken(tokenString, h.Env.SERACT_KEY)
+		// 3. Validate JWT token (signature, expiry and revocation)
+		claims, err := h.ParseToken(tokenString, opts.purposes...)
 		if err != nil {
 			utils.RespondWithError(c, http.StatusUnauthorized, "Invalid or expired token"+err.Error())
 			c.Abort()
 			return
 		}
 
-		// 4. Store useful info in context
+		// 4. Re-check status and role from the database (cached briefly)
+		userID, err := uuid.Parse(claims.UserID)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusUnauthorized, "Invalid user ID")
+			c.Abort()
+			return
+		}
+
+		principal, err := h.LoadPrincipal(userID)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusUnauthorized, "User not found")
+			c.Abort()
+			return
+		}
+
+		if principal.Status == "deactive" {
+			utils.RespondWithError(c, http.StatusForbidden, "Account is deactivated")
+			c.Abort()
+			return
+		}
+
+		// 4.2 Impersonation: the real actor must still be allowed to impersonate
+		if claims.Purpose == utils.TokenPurposeImpersonation {
+			if !checkImpersonation(c, h, claims, opts) {
+				c.Abort()
+				logImpersonatedRequest(c, h, claims) // rejected attempts are audited too
+				return
+			}
+		}
+
+		// 4.5 Record session activity (the session itself was checked by ParseToken)
+		if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+			if err := h.Query.TouchSession(sessionID, c.ClientIP()); err != nil {
+				log.Printf("Failed to update session activity: %v", err)
+			}
+		}
+
+		// 5. Store useful info in context
+		// The role comes from the database, not from the token, so demotions apply immediately
 		c.Set("user_id", claims.UserID)
-		c.Set("role", claims.UserRole)
+		c.Set("role", principal.Role)
+		c.Set("jti", claims.ID)
+		c.Set("token_exp", claims.ExpiresAt.Time)
+		c.Set("token_purpose", claims.Purpose)
+		c.Set("session_id", claims.SessionID)
 
 		// Continue request
+		if claims.ImpersonatorID != "" {
+			c.Set(common.ContextImpersonatorID, claims.ImpersonatorID)
+			c.Header("X-Impersonated-By", claims.ImpersonatorID)
+			c.Next()
+			logImpersonatedRequest(c, h, claims)
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/controllers"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+)
+
+// RequirePermission allows the request if the user's role (or API key scopes)
+// has at least one of the given permissions. It must run after AuthMiddleware.
+func RequirePermission(h *controllers.HandlerFunc, codes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		perms, err := h.RequestPermissions(c)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to load permissions")
+			c.Abort()
+			return
+		}
+
+		for _, code := range codes {
+			if perms[code] {
+				c.Next()
+				return
+			}
+		}
+
+		utils.RespondWithError(c, http.StatusForbidden, "You do not have permission to perform this action")
+		c.Abort()
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // ----------------- ROLE -----------------
@@ -30,6 +31,382 @@ type EmployeeInput struct {
 	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 	ManagerName     *string    `json:"manager_name,omitempty"`     // optional
 	DesignationName *string    `json:"designation_name,omitempty"` // optional
+	DepartmentID    *uuid.UUID `json:"department_id,omitempty"`    // optional, set with PATCH /:id/department
+	DepartmentName  *string    `json:"department_name,omitempty"`  // optional
+	// Employment type code, PERMANENT when omitted on create
+	EmploymentType *string `json:"employment_type,omitempty"`
+	// Defaults to the type's probation_months after the joining date on create
+	ProbationEndDate *time.Time `json:"probation_end_date,omitempty"`
+	ProbationStatus  *string    `json:"probation_status,omitempty"` // ON_PROBATION, CONFIRMED or TERMINATED; read-only
+}
+
+// EmployeeListFilter selects and orders employees for the list and export
+type EmployeeListFilter struct {
+	Role        string
+	Designation string
+	Status      string
+	Search      string // words matched against name and email
+	Sort        string // sort field, "-" prefix for descending
+	Limit       int    // 0 returns all rows
+	Offset      int
+	// Keyset pagination: continue after this sort value and employee ID
+	AfterValue *string
+	AfterID    *uuid.UUID
+	// Department matches its members and the members of departments below it
+	DepartmentID   *uuid.UUID
+	EmploymentType string
+}
+
+// EmployeeImportRow is one row of a bulk import file and its validation result
+type EmployeeImportRow struct {
+	Row           int        `json:"row"` // line number in the file, header is row 1
+	FullName      string     `json:"full_name"`
+	Email         string     `json:"email"`
+	Role          string     `json:"role"`
+	ManagerEmail  string     `json:"manager_email,omitempty"`
+	Designation   string     `json:"designation,omitempty"`
+	Salary        *float64   `json:"salary,omitempty"`
+	JoiningDate   *time.Time `json:"joining_date,omitempty"`
+	RoleID        string     `json:"-"`
+	ManagerID     *uuid.UUID `json:"manager_id,omitempty"`
+	DesignationID *uuid.UUID `json:"designation_id,omitempty"`
+	Errors        []string   `json:"errors,omitempty"`
+	// Employment type code (PERMANENT when empty) and a probation end other
+	// than the type's default
+	EmploymentType   string     `json:"employment_type,omitempty"`
+	ProbationEndDate *time.Time `json:"probation_end_date,omitempty"`
+}
+
+// ----------------- EMPLOYEE PROFILE -----------------
+
+// EmployeeProfile is the extended profile of an employee. Bank account and
+// ID numbers are masked unless revealed by someone with profile.sensitive.
+type EmployeeProfile struct {
+	EmployeeID uuid.UUID `json:"employee_id"`
+
+	Phone         *string `json:"phone"`
+	PersonalEmail *string `json:"personal_email"`
+	AddressLine1  *string `json:"address_line1"`
+	AddressLine2  *string `json:"address_line2"`
+	City          *string `json:"city"`
+	State         *string `json:"state"`
+	PostalCode    *string `json:"postal_code"`
+	Country       *string `json:"country"`
+
+	DateOfBirth *time.Time `json:"date_of_birth"`
+	Gender      *string    `json:"gender"`
+	BloodGroup  *string    `json:"blood_group"`
+
+	BankAccountHolder *string `json:"bank_account_holder"`
+	BankName          *string `json:"bank_name"`
+	BankIFSC          *string `json:"bank_ifsc"`
+	BankAccountNumber *string `json:"bank_account_number"`
+	PANNumber         *string `json:"pan_number"`
+	AadhaarNumber     *string `json:"aadhaar_number"`
+	PassportNumber    *string `json:"passport_number"`
+	SensitiveMasked   bool    `json:"sensitive_masked"`
+
+	EmergencyContacts []EmergencyContact `json:"emergency_contacts"`
+	UpdatedAt         *time.Time         `json:"updated_at"`
+}
+
+type EmergencyContact struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Relationship string    `json:"relationship" db:"relationship"`
+	Phone        string    `json:"phone" db:"phone"`
+	Email        *string   `json:"email,omitempty" db:"email"`
+}
+
+type EmergencyContactInput struct {
+	Name         string  `json:"name" validate:"required,max=100"`
+	Relationship string  `json:"relationship" validate:"required,max=50"`
+	Phone        string  `json:"phone" validate:"required"`
+	Email        *string `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+// UpdateProfileInput changes the given fields; omitted fields are kept and
+// "" clears a field. emergency_contacts replaces the whole list.
+type UpdateProfileInput struct {
+	Phone         *string `json:"phone"`
+	PersonalEmail *string `json:"personal_email"`
+	AddressLine1  *string `json:"address_line1"`
+	AddressLine2  *string `json:"address_line2"`
+	City          *string `json:"city"`
+	State         *string `json:"state"`
+	PostalCode    *string `json:"postal_code"`
+	Country       *string `json:"country"`
+
+	DateOfBirth *string `json:"date_of_birth"` // YYYY-MM-DD
+	Gender      *string `json:"gender"`
+	BloodGroup  *string `json:"blood_group"`
+
+	BankAccountHolder *string `json:"bank_account_holder"`
+	BankName          *string `json:"bank_name"`
+	BankIFSC          *string `json:"bank_ifsc"`
+	BankAccountNumber *string `json:"bank_account_number"`
+	PANNumber         *string `json:"pan_number"`
+	AadhaarNumber     *string `json:"aadhaar_number"`
+	PassportNumber    *string `json:"passport_number"`
+
+	EmergencyContacts *[]EmergencyContactInput `json:"emergency_contacts" validate:"omitempty,max=5,dive"`
+}
+
+// ----------------- EMPLOYEE DOCUMENT -----------------
+type EmployeeDocument struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	EmployeeID  uuid.UUID  `json:"employee_id" db:"employee_id"`
+	Category    string     `json:"category" db:"category"`
+	FileName    string     `json:"file_name" db:"file_name"`
+	ContentType string     `json:"content_type" db:"content_type"`
+	SizeBytes   int64      `json:"size_bytes" db:"size_bytes"`
+	SHA256      string     `json:"sha256" db:"sha256"`
+	StorageKey  string     `json:"-" db:"storage_key"`
+	UploadedBy  *uuid.UUID `json:"uploaded_by,omitempty" db:"uploaded_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ----------------- ORG CHART -----------------
+
+// OrgChartNode is an employee in the reporting hierarchy. Level is the
+// distance from the employee the query started at (reports count down,
+// managers in the chain of command count up).
+type OrgChartNode struct {
+	ID                uuid.UUID       `json:"id" db:"id"`
+	FullName          string          `json:"full_name" db:"full_name"`
+	Email             string          `json:"email" db:"email"`
+	Role              string          `json:"role" db:"role"`
+	Status            string          `json:"status" db:"status"`
+	Designation       *string         `json:"designation,omitempty" db:"designation_name"`
+	ManagerID         *uuid.UUID      `json:"manager_id,omitempty" db:"manager_id"`
+	Level             int             `json:"level" db:"level"`
+	DirectReportCount int             `json:"direct_report_count" db:"direct_report_count"`
+	Reports           []*OrgChartNode `json:"reports,omitempty" db:"-"`
+}
+
+// ----------------- OFFBOARDING -----------------
+
+// Offboarding is an employee's exit, see tbl_employee_offboarding
+type Offboarding struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EmployeeID    uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName  string     `json:"employee_name" db:"employee_name"`
+	EndingDate    time.Time  `json:"ending_date" db:"ending_date"`
+	Reason        string     `json:"reason" db:"reason"`
+	Status        string     `json:"status" db:"status"` // OPEN, CLEARED or CANCELLED
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	ClearedAt     *time.Time `json:"cleared_at,omitempty" db:"cleared_at"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	PendingItems  int        `json:"pending_items" db:"pending_items"`
+}
+
+type OffboardingItem struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	OffboardingID uuid.UUID  `json:"offboarding_id" db:"offboarding_id"`
+	ItemType      string     `json:"item_type" db:"item_type"`
+	ReferenceID   *uuid.UUID `json:"reference_id,omitempty" db:"reference_id"` // equipment assignment or leave
+	Title         string     `json:"title" db:"title"`
+	Status        string     `json:"status" db:"status"` // PENDING, DONE or WAIVED
+	Note          string     `json:"note" db:"note"`
+	CompletedBy   *uuid.UUID `json:"completed_by,omitempty" db:"completed_by"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type StartOffboardingInput struct {
+	EndingDate string  `json:"ending_date" validate:"required,datetime=2006-01-02"` // last working day
+	Reason     *string `json:"reason" validate:"omitempty,max=500"`
+}
+
+type UpdateOffboardingItemInput struct {
+	Status string `json:"status" validate:"required,oneof=PENDING DONE WAIVED"`
+	Note   string `json:"note" validate:"max=500"`
+}
+
+// ----------------- ONBOARDING -----------------
+
+// OnboardingTemplate is a checklist handed to new hires of Role (every role
+// when nil), see tbl_onboarding_template
+type OnboardingTemplate struct {
+	ID          uuid.UUID                `json:"id" db:"id"`
+	Name        string                   `json:"name" db:"name"`
+	Description string                   `json:"description" db:"description"`
+	Role        *string                  `json:"role" db:"role"`
+	IsActive    bool                     `json:"is_active" db:"is_active"`
+	CreatedBy   *uuid.UUID               `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time                `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time                `json:"updated_at" db:"updated_at"`
+	Tasks       []OnboardingTemplateTask `json:"tasks" db:"-"`
+}
+
+type OnboardingTemplateTask struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	TemplateID    uuid.UUID `json:"template_id" db:"template_id"`
+	Title         string    `json:"title" db:"title"`
+	Description   string    `json:"description" db:"description"`
+	OwnerRole     string    `json:"owner_role" db:"owner_role"`           // HR, MANAGER or EMPLOYEE
+	DueOffsetDays int       `json:"due_offset_days" db:"due_offset_days"` // days after the joining date
+	Position      int       `json:"position" db:"position"`
+}
+
+// OnboardingTask is a new hire's copy of a template task
+type OnboardingTask struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	EmployeeID     uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName   string     `json:"employee_name" db:"employee_name"`
+	ManagerID      *uuid.UUID `json:"manager_id,omitempty" db:"manager_id"`
+	TemplateID     *uuid.UUID `json:"template_id,omitempty" db:"template_id"`
+	TemplateTaskID *uuid.UUID `json:"-" db:"template_task_id"`
+	Title          string     `json:"title" db:"title"`
+	Description    string     `json:"description" db:"description"`
+	OwnerRole      string     `json:"owner_role" db:"owner_role"`
+	DueOffsetDays  int        `json:"due_offset_days" db:"due_offset_days"`
+	DueDate        time.Time  `json:"due_date" db:"due_date"`
+	Position       int        `json:"-" db:"position"`
+	Status         string     `json:"status" db:"status"` // PENDING, DONE or SKIPPED
+	Note           string     `json:"note" db:"note"`
+	CompletedBy    *uuid.UUID `json:"completed_by,omitempty" db:"completed_by"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	DaysOverdue    int        `json:"days_overdue" db:"days_overdue"`
+}
+
+type OnboardingTemplateInput struct {
+	Name        string                        `json:"name" validate:"required,max=100"`
+	Description string                        `json:"description" validate:"max=500"`
+	Role        *string                       `json:"role" validate:"omitempty,oneof=SUPERADMIN ADMIN HR MANAGER EMPLOYEE"` // nil = every role
+	IsActive    *bool                         `json:"is_active"`                                                            // default true
+	Tasks       []OnboardingTemplateTaskInput `json:"tasks" validate:"required,min=1,max=100,dive"`
+}
+
+type OnboardingTemplateTaskInput struct {
+	Title         string `json:"title" validate:"required,max=200"`
+	Description   string `json:"description" validate:"max=1000"`
+	OwnerRole     string `json:"owner_role" validate:"required,oneof=HR MANAGER EMPLOYEE"`
+	DueOffsetDays int    `json:"due_offset_days" validate:"min=-365,max=365"`
+}
+
+type ApplyOnboardingTemplateInput struct {
+	TemplateID string `json:"template_id" validate:"required,uuid"`
+}
+
+type UpdateOnboardingTaskInput struct {
+	Status string `json:"status" validate:"required,oneof=PENDING DONE SKIPPED"`
+	Note   string `json:"note" validate:"max=500"`
+}
+
+// ----------------- SALARY REVISION -----------------
+
+// SalaryRevision is an employee's salary from EffectiveFrom until the next
+// revision, see tbl_salary_revision
+type SalaryRevision struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	EmployeeID     uuid.UUID  `json:"employee_id" db:"employee_id"`
+	Salary         float64    `json:"salary" db:"salary"`
+	EffectiveFrom  time.Time  `json:"effective_from" db:"effective_from"`
+	Reason         string     `json:"reason" db:"reason"`
+	ApprovedBy     *uuid.UUID `json:"approved_by,omitempty" db:"approved_by"`
+	ApprovedByName *string    `json:"approved_by_name,omitempty" db:"approved_by_name"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// SalaryArrear is the net pay a finalized payslip owes after a backdated revision
+type SalaryArrear struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EmployeeID    uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName  string     `json:"employee_name" db:"employee_name"`
+	RevisionID    *uuid.UUID `json:"revision_id,omitempty" db:"revision_id"`
+	PayslipID     uuid.UUID  `json:"payslip_id" db:"payslip_id"`
+	Month         int        `json:"month" db:"month"`
+	Year          int        `json:"year" db:"year"`
+	PaidSalary    float64    `json:"paid_salary" db:"paid_salary"`       // basic salary on the payslip
+	RevisedSalary float64    `json:"revised_salary" db:"revised_salary"` // basic salary with the revision
+	Amount        float64    `json:"amount" db:"amount"`                 // net difference, negative is recovered
+	Status        string     `json:"status" db:"status"`                 // PENDING or PAID
+	PaidPayslipID *uuid.UUID `json:"paid_payslip_id,omitempty" db:"paid_payslip_id"`
+	PaidAt        *time.Time `json:"paid_at,omitempty" db:"paid_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type SalaryRevisionInput struct {
+	Salary        *float64 `json:"salary" validate:"required,gte=0"`
+	EffectiveFrom string   `json:"effective_from" validate:"required,datetime=2006-01-02"` // past dates are backdated
+	Reason        string   `json:"reason" validate:"required,max=500"`
+}
+
+// ----------------- EMPLOYMENT TYPE -----------------
+
+// EmploymentType is a kind of employment with its payroll and probation
+// settings, see tbl_employment_type
+type EmploymentType struct {
+	Code             string    `json:"code" db:"code"`
+	Name             string    `json:"name" db:"name"`
+	IncludeInPayroll bool      `json:"include_in_payroll" db:"include_in_payroll"` // payroll runs skip the type when off
+	DeductAbsences   bool      `json:"deduct_absences" db:"deduct_absences"`       // absent days are paid when off
+	ProbationMonths  int       `json:"probation_months" db:"probation_months"`     // default probation of new hires
+	EmployeeCount    int       `json:"employee_count" db:"employee_count"`         // active employees of the type
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type EmploymentTypeInput struct {
+	Code             string `json:"code" validate:"required,max=30"` // upper case letters and underscores, taken from the URL on update
+	Name             string `json:"name" validate:"required,max=100"`
+	IncludeInPayroll *bool  `json:"include_in_payroll"` // default true
+	DeductAbsences   *bool  `json:"deduct_absences"`    // default true
+	ProbationMonths  int    `json:"probation_months" validate:"min=0,max=24"`
+}
+
+type UpdateEmploymentInput struct {
+	EmploymentType string `json:"employment_type" validate:"required"`
+	// Starts or moves the probation; omitted keeps the current one
+	ProbationEndDate *string `json:"probation_end_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// ProbationDecision confirms, extends or terminates a probation, see tbl_probation_decision
+type ProbationDecision struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	EmployeeID      uuid.UUID  `json:"employee_id" db:"employee_id"`
+	Decision        string     `json:"decision" db:"decision"` // CONFIRMED, EXTENDED or TERMINATED
+	PreviousEndDate *time.Time `json:"previous_end_date,omitempty" db:"previous_end_date"`
+	NewEndDate      *time.Time `json:"new_end_date,omitempty" db:"new_end_date"` // EXTENDED only
+	Note            string     `json:"note" db:"note"`
+	DecidedBy       *uuid.UUID `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedByName   *string    `json:"decided_by_name,omitempty" db:"decided_by_name"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+type ProbationDecisionInput struct {
+	Decision   string  `json:"decision" validate:"required,oneof=CONFIRMED EXTENDED TERMINATED"`
+	NewEndDate *string `json:"new_end_date" validate:"required_if=Decision EXTENDED,omitempty,datetime=2006-01-02"`
+	Note       string  `json:"note" validate:"required,max=1000"`
+}
+
+// ProbationDue is an employee whose probation ends soon or has ended without a decision
+type ProbationDue struct {
+	EmployeeID       uuid.UUID `json:"employee_id" db:"employee_id"`
+	FullName         string    `json:"full_name" db:"full_name"`
+	Email            string    `json:"email" db:"email"`
+	EmploymentType   string    `json:"employment_type" db:"employment_type"`
+	ManagerName      *string   `json:"manager_name,omitempty" db:"manager_name"`
+	ProbationEndDate time.Time `json:"probation_end_date" db:"probation_end_date"`
+	DaysLeft         int       `json:"days_left" db:"days_left"` // negative once overdue
+}
+
+// LeaveTypeRule replaces a leave type's policy for one employment type, see tbl_leave_type_rule
+type LeaveTypeRule struct {
+	LeaveTypeID        int    `json:"leave_type_id" db:"leave_type_id"`
+	EmploymentType     string `json:"employment_type" db:"employment_type"`
+	Entitlement        int    `json:"entitlement" db:"entitlement"` // yearly, 0 closes the leave type
+	AllowedOnProbation bool   `json:"allowed_on_probation" db:"allowed_on_probation"`
+}
+
+type LeaveTypeRuleInput struct {
+	EmploymentType     string `json:"employment_type" validate:"required"`
+	Entitlement        *int   `json:"entitlement" validate:"required,min=0"`
+	AllowedOnProbation *bool  `json:"allowed_on_probation"` // default true
 }
 
 // ----------------- LEAVE TYPE -----------------
@@ -118,6 +495,90 @@ type LoginInput struct {
 	Password string `json:"password" validate:"required,min=6"`
 }
 
+type OIDCCallbackInput struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// ----------------- PASSWORD -----------------
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// ----------------- PERMISSION -----------------
+type Permission struct {
+	ID          int    `db:"id" json:"id"`
+	Code        string `db:"code" json:"code"`
+	Description string `db:"description" json:"description"`
+}
+
+type RolePermission struct {
+	Role       string `db:"role" json:"role"`
+	Permission string `db:"permission" json:"permission"`
+}
+
+type RolePermissionInput struct {
+	Permission string `json:"permission" binding:"required"`
+}
+
+// ----------------- API KEY -----------------
+type APIKey struct {
+	ID            uuid.UUID      `db:"id" json:"id"`
+	Name          string         `db:"name" json:"name"`
+	Prefix        string         `db:"key_prefix" json:"prefix"`
+	Scopes        pq.StringArray `db:"scopes" json:"scopes"`
+	CreatedBy     uuid.UUID      `db:"created_by" json:"created_by"`
+	CreatedByName string         `db:"created_by_name" json:"created_by_name"`
+	ExpiresAt     time.Time      `db:"expires_at" json:"expires_at"`
+	LastUsedAt    *time.Time     `db:"last_used_at" json:"last_used_at"`
+	LastUsedIP    *string        `db:"last_used_ip" json:"last_used_ip"`
+	RevokedAt     *time.Time     `db:"revoked_at" json:"revoked_at"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+}
+
+type CreateAPIKeyInput struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"` // default 90
+}
+
+// ----------------- IMPERSONATION -----------------
+type ImpersonateInput struct {
+	EmployeeID  uuid.UUID `json:"employee_id" binding:"required"`
+	AllowWrites bool      `json:"allow_writes"` // read-only unless set
+}
+
+// ----------------- SESSION -----------------
+type Session struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	Device     string    `db:"device" json:"device"`
+	IPAddress  string    `db:"ip_address" json:"ip_address"`
+	UserAgent  string    `db:"user_agent" json:"user_agent"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	LastSeenAt time.Time `db:"last_seen_at" json:"last_seen_at"`
+	Current    bool      `db:"-" json:"current"` // the session making the request
+}
+
+// ----------------- MFA -----------------
+type MFAVerifyInput struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // 6-digit TOTP code or a recovery code
+}
+
+type MFACodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
 // ----------------- AUDIT -----------------
 type AuditInput struct {
 	ActorID  uuid.UUID  `json:"actor_id" validate:"required"`
@@ -186,27 +647,77 @@ type DesignationInput struct {
 	Description     *string `json:"description,omitempty"`
 }
 
+// ----------------- DEPARTMENT -----------------
+
+// Department is an organisational unit, optionally under ParentID and led by
+// HeadID, see tbl_department
+type Department struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Description string     `json:"description" db:"description"`
+	ParentID    *uuid.UUID `json:"parent_id" db:"parent_id"`
+	ParentName  *string    `json:"parent_name,omitempty" db:"parent_name"`
+	HeadID      *uuid.UUID `json:"head_id" db:"head_id"`
+	HeadName    *string    `json:"head_name,omitempty" db:"head_name"`
+	MemberCount int        `json:"member_count" db:"member_count"` // active members, sub-departments excluded
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type DepartmentInput struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description string  `json:"description" validate:"max=500"`
+	ParentID    *string `json:"parent_id" validate:"omitempty,uuid"` // nil or empty for a top-level department
+	HeadID      *string `json:"head_id" validate:"omitempty,uuid"`
+}
+
+// DepartmentReport is one department's figures for a year or month. Members
+// are counted by their current department; sub-departments have their own row.
+type DepartmentReport struct {
+	DepartmentID *uuid.UUID `json:"department_id" db:"department_id"` // nil for employees without a department
+	Name         string     `json:"name" db:"name"`
+	ParentID     *uuid.UUID `json:"parent_id" db:"parent_id"`
+	Headcount    int        `json:"headcount" db:"headcount"` // active members
+	Joiners      int        `json:"joiners" db:"joiners"`     // joining date in the period
+	Leavers      int        `json:"leavers" db:"leavers"`     // ending date in the period
+	OnLeaveToday int        `json:"on_leave_today" db:"on_leave_today"`
+	LeaveDays    float64    `json:"leave_days" db:"leave_days"` // approved leave starting in the period
+	// Finalized payslips of the period, only shown with payroll.view_all
+	PayrollNet        *float64 `json:"payroll_net,omitempty" db:"payroll_net"`
+	PayrollDeductions *float64 `json:"payroll_deductions,omitempty" db:"payroll_deductions"`
+}
+
 // CompanySettings struct mapping the DB table
 type CompanySettings struct {
-	ID                   uuid.UUID `db:"id" json:"id"`
-	WorkingDaysPerMonth  int       `db:"working_days_per_month" json:"working_days_per_month"`
-	AllowManagerAddLeave bool      `db:"allow_manager_add_leave" json:"allow_manager_add_leave"`
-	CreatedAt            string    `db:"created_at" json:"created_at"`
-	UpdatedAt            string    `db:"updated_at" json:"updated_at"`
+	ID                   uuid.UUID      `db:"id" json:"id"`
+	WorkingDaysPerMonth  int            `db:"working_days_per_month" json:"working_days_per_month"`
+	AllowManagerAddLeave bool           `db:"allow_manager_add_leave" json:"allow_manager_add_leave"`
+	CreatedAt            string         `db:"created_at" json:"created_at"`
+	UpdatedAt            string         `db:"updated_at" json:"updated_at"`
+	MFARequiredRoles     pq.StringArray `db:"mfa_required_roles" json:"mfa_required_roles"`
+	AllowedEmailDomains  pq.StringArray `db:"allowed_email_domains" json:"allowed_email_domains"`
 }
 
 type CompanyField struct {
-	WorkingDaysPerMonth  int  `json:"working_days_per_month" binding:"required"`
-	AllowManagerAddLeave bool `json:"allow_manager_add_leave"`
+	WorkingDaysPerMonth  int       `json:"working_days_per_month" binding:"required"`
+	AllowManagerAddLeave bool      `json:"allow_manager_add_leave"`
+	MFARequiredRoles     *[]string `json:"mfa_required_roles"`    // nil keeps the current value
+	AllowedEmailDomains  *[]string `json:"allowed_email_domains"` // nil keeps the current value, empty allows any domain
 }
 
 // ----------------- LOG -----------------
 type LogResponse struct {
-	ID        int       `json:"id" db:"id"`
-	UserName  string    `json:"user_name" db:"user_name"`
-	Action    string    `json:"action" db:"action"`
-	Component string    `json:"component" db:"component"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID        int     `json:"id" db:"id"`
+	UserName  string  `json:"user_name" db:"user_name"`
+	Action    string  `json:"action" db:"action"`
+	Component string  `json:"component" db:"component"`
+	APIKey    *string `json:"api_key,omitempty" db:"api_key"` // name of the API key used, if any
+	// ImpersonatedBy is the SUPERADMIN who acted as UserName, if any
+	ImpersonatedBy *string   `json:"impersonated_by,omitempty" db:"impersonated_by"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	// Target is the employee the action was taken on, if recorded
+	TargetID   *uuid.UUID `json:"target_id,omitempty" db:"target_id"`
+	TargetName *string    `json:"target_name,omitempty" db:"target_name"`
 }
 
 type Leave struct {
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTLCache is a small thread-safe in-memory cache whose entries expire
+// after a fixed TTL. It is used for hot lookups done on every request.
+type TTLCache[K comparable, V any] struct {
+	ttl   time.Duration
+	mu    sync.RWMutex
+	items map[K]entry[V]
+}
+
+// New creates a TTLCache with the given entry lifetime
+func New[K comparable, V any](ttl time.Duration) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		ttl:   ttl,
+		items: make(map[K]entry[V]),
+	}
+}
+
+// Get returns the cached value if present and not expired
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	e, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(e.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores a value for the configured TTL
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Opportunistically drop expired entries so the map does not grow forever
+	now := time.Now()
+	for k, e := range c.items {
+		if now.After(e.expiresAt) {
+			delete(c.items, k)
+		}
+	}
+
+	c.items[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Invalidate removes a single key
+func (c *TTLCache[K, V]) Invalidate(key K) {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+}
+
+// Clear removes every key
+func (c *TTLCache[K, V]) Clear() {
+	c.mu.Lock()
+	c.items = make(map[K]entry[V])
+	c.mu.Unlock()
+}
@@ -1,20 +1,67 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/ratelimit"
 )
 
 // ENV holds all application environment variables in a structured way
 type ENV struct {
-	DB_URL            string
-	APP_PORT          string
-	SERACT_KEY        string
-	FRONTEND_SERVER   string
-	GOOGLE_SCRIPT_URL string
+	DB_URL        string
+	APP_PORT      string
+	SERACT_KEY    string // Legacy HMAC secret, verifies tokens without a kid header
+	JWT_KEYS_FILE string // Optional JSON keyring (see pkg/keyring) for key rotation
+	// Keys encrypting bank account and ID numbers (see fieldcrypt.New)
+	FIELD_ENCRYPTION_KEYS string
+	FRONTEND_SERVER       string
+	// Origins allowed by CORS. Defaults to FRONTEND_SERVER, or "*" (without
+	// credentials) when that is not set either.
+	CORS_ALLOWED_ORIGINS []string
+	GOOGLE_SCRIPT_URL    string
+	ACCESS_TOKEN_TTL     time.Duration // Lifetime of JWT access tokens
+	REFRESH_TOKEN_TTL    time.Duration // Lifetime of opaque refresh tokens
+	RESET_TOKEN_TTL      time.Duration // Lifetime of password reset links
+
+	PRINCIPAL_CACHE_TTL time.Duration // How long an employee's role/status is cached by AuthMiddleware
+
+	OFFBOARDING_CHECK_INTERVAL     time.Duration // How often employees past their ending date are deactivated
+	SALARY_REVISION_CHECK_INTERVAL time.Duration // How often scheduled salary revisions are applied to Tbl_Employee.salary
+	PROBATION_REMINDER_INTERVAL    time.Duration // How often probations ending soon are looked for
+	PROBATION_REMINDER_DAYS        int           // Days before the probation end date its reminder is sent
+
+	RATE_LIMITS map[string]ratelimit.Limit // Token-bucket limit per route group (see ratelimit.ParseLimits)
+	// Reverse proxies (IPs or CIDRs) whose X-Forwarded-For is believed. Empty
+	// means the header is ignored and the client IP is the connection's peer.
+	TRUSTED_PROXIES []string
+
+	// OpenID Connect single sign-on, disabled while OIDC_ISSUER is empty
+	OIDC_ISSUER                 string
+	OIDC_CLIENT_ID              string
+	OIDC_CLIENT_SECRET          string
+	OIDC_REDIRECT_URL           string // Frontend page that receives ?code=&state= and posts them to the callback
+	OIDC_SCOPES                 []string
+	OIDC_REQUIRE_VERIFIED_EMAIL bool // Reject ID tokens without email_verified=true
+
+	// Employee document storage: "local" keeps files in STORAGE_LOCAL_DIR,
+	// "s3" in an S3-compatible bucket
+	STORAGE_BACKEND      string
+	STORAGE_LOCAL_DIR    string // Documents are disabled while empty; keep it outside the source tree
+	S3_ENDPOINT          string // host[:port] without scheme
+	S3_REGION            string
+	S3_BUCKET            string
+	S3_ACCESS_KEY        string
+	S3_SECRET_KEY        string
+	S3_USE_SSL           bool
+	DOCUMENT_MAX_SIZE_MB int // Largest accepted upload
 }
 
 var (
@@ -40,13 +87,173 @@ func LoadENV() *ENV {
 
 		// Populate ENV struct with environment variables
 		cfg = &ENV{
-			DB_URL:            os.Getenv("DB_URL"),   // Required: PostgreSQL URL
-			APP_PORT:          os.Getenv("APP_PORT"), // Optional: server port
-			SERACT_KEY:        os.Getenv("SECRATE_KEY"),
-			FRONTEND_SERVER:   os.Getenv("F_SERVER"),
-			GOOGLE_SCRIPT_URL: os.Getenv("GOOGLE_SCRIPT_URL"),
+			DB_URL:                os.Getenv("DB_URL"),   // Required: PostgreSQL URL
+			APP_PORT:              os.Getenv("APP_PORT"), // Optional: server port
+			SERACT_KEY:            os.Getenv("SECRATE_KEY"),
+			JWT_KEYS_FILE:         os.Getenv("JWT_KEYS_FILE"),
+			FIELD_ENCRYPTION_KEYS: os.Getenv("FIELD_ENCRYPTION_KEYS"),
+			FRONTEND_SERVER:       os.Getenv("F_SERVER"),
+			CORS_ALLOWED_ORIGINS:  getList("CORS_ALLOWED_ORIGINS", getString("F_SERVER", "*")),
+			GOOGLE_SCRIPT_URL:     os.Getenv("GOOGLE_SCRIPT_URL"),
+			ACCESS_TOKEN_TTL:      getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			REFRESH_TOKEN_TTL:     getDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+			RESET_TOKEN_TTL:       getDuration("RESET_TOKEN_TTL", 30*time.Minute),
+
+			PRINCIPAL_CACHE_TTL: getDuration("PRINCIPAL_CACHE_TTL", 30*time.Second),
+
+			OFFBOARDING_CHECK_INTERVAL:     getDuration("OFFBOARDING_CHECK_INTERVAL", time.Hour),
+			SALARY_REVISION_CHECK_INTERVAL: getDuration("SALARY_REVISION_CHECK_INTERVAL", time.Hour),
+			PROBATION_REMINDER_INTERVAL:    getDuration("PROBATION_REMINDER_INTERVAL", 6*time.Hour),
+			PROBATION_REMINDER_DAYS:        getInt("PROBATION_REMINDER_DAYS", 14),
+
+			RATE_LIMITS:     getRateLimits("RATE_LIMITS", defaultRateLimits),
+			TRUSTED_PROXIES: getList("TRUSTED_PROXIES", ""),
+
+			OIDC_ISSUER:                 os.Getenv("OIDC_ISSUER"),
+			OIDC_CLIENT_ID:              os.Getenv("OIDC_CLIENT_ID"),
+			OIDC_CLIENT_SECRET:          os.Getenv("OIDC_CLIENT_SECRET"),
+			OIDC_REDIRECT_URL:           os.Getenv("OIDC_REDIRECT_URL"),
+			OIDC_SCOPES:                 strings.Fields(getString("OIDC_SCOPES", "openid email profile")),
+			OIDC_REQUIRE_VERIFIED_EMAIL: getBool("OIDC_REQUIRE_VERIFIED_EMAIL", true),
+
+			STORAGE_BACKEND:      getString("STORAGE_BACKEND", "local"),
+			STORAGE_LOCAL_DIR:    os.Getenv("STORAGE_LOCAL_DIR"),
+			S3_ENDPOINT:          os.Getenv("S3_ENDPOINT"),
+			S3_REGION:            os.Getenv("S3_REGION"),
+			S3_BUCKET:            os.Getenv("S3_BUCKET"),
+			S3_ACCESS_KEY:        os.Getenv("S3_ACCESS_KEY"),
+			S3_SECRET_KEY:        os.Getenv("S3_SECRET_KEY"),
+			S3_USE_SSL:           getBool("S3_USE_SSL", true),
+			DOCUMENT_MAX_SIZE_MB: getInt("DOCUMENT_MAX_SIZE_MB", 10),
 		}
 	})
 	log.Println(" Environment variables loaded successfully")
 	return cfg
 }
+
+// getDuration reads a Go duration string (e.g. "15m", "168h") from the
+// environment, falling back to def when unset or invalid.
+func getDuration(key string, def time.Duration) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		log.Printf("⚠ Invalid %s=%q, using default %s", key, val, def)
+		return def
+	}
+	return d
+}
+
+// defaultRateLimits are used for every group RATE_LIMITS does not mention
+const defaultRateLimits = "default=600/m:100,auth=20/m:10,payroll=5/m:2,pdf=30/m:10"
+
+// getRateLimits reads per-group rate limits, falling back to def for
+// groups that are not set and when the value is invalid.
+func getRateLimits(key, def string) map[string]ratelimit.Limit {
+	limits, err := ratelimit.ParseLimits(def)
+	if err != nil {
+		log.Fatalf("invalid default rate limits: %v", err)
+	}
+
+	val := os.Getenv(key)
+	if val == "" {
+		return limits
+	}
+	custom, err := ratelimit.ParseLimits(val)
+	if err != nil {
+		log.Printf("⚠ Invalid %s=%q (%v), using default %s", key, val, err, def)
+		return limits
+	}
+	for group, limit := range custom {
+		limits[group] = limit
+	}
+	return limits
+}
+
+// Validate checks settings that would otherwise fail late or silently.
+// It is called once at startup.
+func (e *ENV) Validate() error {
+	if len(e.CORS_ALLOWED_ORIGINS) == 0 {
+		return fmt.Errorf("CORS_ALLOWED_ORIGINS is empty")
+	}
+	for _, origin := range e.CORS_ALLOWED_ORIGINS {
+		if origin == "*" {
+			if len(e.CORS_ALLOWED_ORIGINS) > 1 {
+				return fmt.Errorf("CORS_ALLOWED_ORIGINS: \"*\" cannot be combined with other origins")
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			(u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS: %q is not an origin like https://app.example.com", origin)
+		}
+	}
+
+	if e.OIDC_ISSUER != "" && (e.OIDC_CLIENT_ID == "" || e.OIDC_REDIRECT_URL == "") {
+		return fmt.Errorf("OIDC_ISSUER is set but OIDC_CLIENT_ID or OIDC_REDIRECT_URL is missing")
+	}
+
+	switch e.STORAGE_BACKEND {
+	case "local":
+		// Without STORAGE_LOCAL_DIR the document endpoints are disabled
+	case "s3":
+		if e.S3_ENDPOINT == "" || e.S3_BUCKET == "" {
+			return fmt.Errorf("STORAGE_BACKEND=s3 needs S3_ENDPOINT and S3_BUCKET")
+		}
+	default:
+		return fmt.Errorf("STORAGE_BACKEND must be local or s3, got %q", e.STORAGE_BACKEND)
+	}
+	return nil
+}
+
+// getList reads a comma separated list, falling back to def when unset
+func getList(key, def string) []string {
+	var list []string
+	for _, item := range strings.Split(getString(key, def), ",") {
+		if item = strings.TrimRight(strings.TrimSpace(item), "/"); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getString reads an environment variable, falling back to def when unset
+func getString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
+
+// getInt reads a positive integer from the environment, falling back to def
+// when unset or invalid.
+func getInt(key string, def int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n <= 0 {
+		log.Printf("⚠ Invalid %s=%q, using default %d", key, val, def)
+		return def
+	}
+	return n
+}
+
+// getBool reads a boolean ("true", "false", "1", "0") from the environment,
+// falling back to def when unset or invalid.
+func getBool(key string, def bool) bool {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Printf("⚠ Invalid %s=%q, using default %t", key, val, def)
+		return def
+	}
+	return b
+}
@@ -0,0 +1,112 @@
+// Package fieldcrypt encrypts single database fields (bank account and ID
+// numbers) with AES-256-GCM. Values carry the id of the key that encrypted
+// them, so keys can be rotated without re-encrypting existing rows at once.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDisabled is returned when no encryption key is configured
+var ErrDisabled = errors.New("field encryption is not configured")
+
+// Cipher is safe for concurrent use, it is never modified after New
+type Cipher struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// New parses a comma separated list of "<id>=<base64 32-byte key>". The
+// first key encrypts new values; all keys decrypt. An empty spec gives a
+// disabled Cipher.
+//
+//	FIELD_ENCRYPTION_KEYS=2026-01=q3Jx...,2025-06=Zk9a...
+func New(spec string) (*Cipher, error) {
+	c := &Cipher{keys: map[string]cipher.AEAD{}}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(part, "=")
+		if !ok || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid field encryption key %q, expected <id>=<base64 key>", id)
+		}
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("field encryption key %q must be 32 bytes, base64 encoded", id)
+		}
+		if _, dup := c.keys[id]; dup {
+			return nil, fmt.Errorf("duplicate field encryption key id %q", id)
+		}
+
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[id] = aead
+		if c.active == "" {
+			c.active = id
+		}
+	}
+	return c, nil
+}
+
+// Enabled reports whether a key is configured
+func (c *Cipher) Enabled() bool {
+	return c != nil && c.active != ""
+}
+
+// Encrypt returns "<key id>:<base64 nonce+ciphertext>". The context (e.g.
+// employee id and column) is authenticated, so a value copied to another
+// row or column fails to decrypt.
+func (c *Cipher) Encrypt(plaintext, context string) (string, error) {
+	if !c.Enabled() {
+		return "", ErrDisabled
+	}
+	aead := c.keys[c.active]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(context))
+	return c.active + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt with the same context
+func (c *Cipher) Decrypt(value, context string) (string, error) {
+	if !c.Enabled() {
+		return "", ErrDisabled
+	}
+	id, encoded, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, ok := c.keys[id]
+	if !ok {
+		return "", fmt.Errorf("unknown field encryption key %q", id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(context))
+	if err != nil {
+		return "", errors.New("failed to decrypt value")
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,305 @@
+// Package keyring holds the keys used to sign and verify our JWTs. One key
+// signs new tokens; older keys keep verifying tokens until they are retired.
+package keyring
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// LegacyKeyID identifies the SECRATE_KEY secret. Tokens without a kid
+// header were signed with it before the keyring existed.
+const LegacyKeyID = "legacy"
+
+// Key is one signing or verification key
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// RetireAt stops the key from verifying tokens; zero means never
+	RetireAt time.Time
+
+	signKey   any // nil for verification-only keys
+	verifyKey any
+}
+
+func (k *Key) retired() bool {
+	return !k.RetireAt.IsZero() && time.Now().After(k.RetireAt)
+}
+
+// Keyring is safe for concurrent use, it is never modified after Load
+type Keyring struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+// keyConfig is one entry of the JWT_KEYS_FILE "keys" array
+type keyConfig struct {
+	KID            string `json:"kid"`
+	Alg            string `json:"alg"`                        // HS256, RS256 or EdDSA
+	Secret         string `json:"secret,omitempty"`           // HS256
+	PrivateKey     string `json:"private_key,omitempty"`      // PEM (RS256/EdDSA)
+	PrivateKeyFile string `json:"private_key_file,omitempty"` // path to PEM
+	PublicKey      string `json:"public_key,omitempty"`       // PEM, for verification-only keys
+	PublicKeyFile  string `json:"public_key_file,omitempty"`
+	RetireAt       string `json:"retire_at,omitempty"` // RFC 3339
+}
+
+type fileConfig struct {
+	Active string      `json:"active"`
+	Keys   []keyConfig `json:"keys"`
+}
+
+// Load builds the keyring from the JSON file at path and the legacy HMAC
+// secret. Without a file the legacy secret is the only (and active) key.
+//
+//	{
+//	  "active": "2026-01",
+//	  "keys": [
+//	    {"kid": "2026-01", "alg": "RS256", "private_key_file": "keys/2026-01.pem"},
+//	    {"kid": "2025-10", "alg": "HS256", "secret": "...", "retire_at": "2026-01-08T00:00:00Z"}
+//	  ]
+//	}
+func Load(path, legacySecret string) (*Keyring, error) {
+	ring := &Keyring{keys: map[string]*Key{}}
+
+	if legacySecret != "" {
+		ring.keys[LegacyKeyID] = &Key{
+			ID:        LegacyKeyID,
+			Method:    jwt.SigningMethodHS256,
+			signKey:   []byte(legacySecret),
+			verifyKey: []byte(legacySecret),
+		}
+	}
+
+	if path == "" {
+		ring.active = ring.keys[LegacyKeyID]
+		if ring.active == nil {
+			return nil, errors.New("no JWT signing key: set SECRATE_KEY or JWT_KEYS_FILE")
+		}
+		return ring, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWT keys: %w", err)
+	}
+	var cfg fileConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parse JWT keys: %w", err)
+	}
+
+	for _, kc := range cfg.Keys {
+		key, err := parseKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", kc.KID, err)
+		}
+		if _, dup := ring.keys[key.ID]; dup {
+			return nil, fmt.Errorf("duplicate JWT key id %q", key.ID)
+		}
+		ring.keys[key.ID] = key
+	}
+
+	active := cfg.Active
+	if active == "" {
+		active = LegacyKeyID
+	}
+	ring.active = ring.keys[active]
+	switch {
+	case ring.active == nil:
+		return nil, fmt.Errorf("active JWT key %q not found", active)
+	case ring.active.signKey == nil:
+		return nil, fmt.Errorf("active JWT key %q has no private key", active)
+	case !ring.active.RetireAt.IsZero():
+		return nil, fmt.Errorf("active JWT key %q cannot have retire_at", active)
+	}
+	return ring, nil
+}
+
+func parseKey(kc keyConfig) (*Key, error) {
+	if kc.KID == "" {
+		return nil, errors.New("kid is required")
+	}
+	if kc.KID == LegacyKeyID {
+		return nil, fmt.Errorf("kid %q is reserved for SECRATE_KEY", LegacyKeyID)
+	}
+	key := &Key{ID: kc.KID}
+
+	if kc.RetireAt != "" {
+		t, err := time.Parse(time.RFC3339, kc.RetireAt)
+		if err != nil {
+			return nil, fmt.Errorf("retire_at: %w", err)
+		}
+		key.RetireAt = t
+	}
+
+	switch kc.Alg {
+	case "HS256":
+		if len(kc.Secret) < 32 {
+			return nil, errors.New("HS256 secret must be at least 32 characters")
+		}
+		key.Method = jwt.SigningMethodHS256
+		key.signKey = []byte(kc.Secret)
+		key.verifyKey = []byte(kc.Secret)
+		return key, nil
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+	case "EdDSA":
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported alg %q", kc.Alg)
+	}
+
+	privDER, err := readPEM(kc.PrivateKey, kc.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	pubDER, err := readPEM(kc.PublicKey, kc.PublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case privDER != nil:
+		priv, err := parsePrivateKey(privDER)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey = priv
+		key.verifyKey = priv.Public()
+	case pubDER != nil:
+		pub, err := parsePublicKey(pubDER)
+		if err != nil {
+			return nil, err
+		}
+		key.verifyKey = pub
+	default:
+		return nil, errors.New("private_key or public_key is required")
+	}
+
+	// The key type has to match alg
+	switch key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		if key.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("RSA key cannot be used with %s", kc.Alg)
+		}
+	case ed25519.PublicKey:
+		if key.Method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("Ed25519 key cannot be used with %s", kc.Alg)
+		}
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+	return key, nil
+}
+
+// readPEM returns the DER bytes of an inline or file PEM, nil if neither is set
+func readPEM(inline, file string) ([]byte, error) {
+	var data []byte
+	switch {
+	case inline != "":
+		data = []byte(inline)
+	case file != "":
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM")
+	}
+	return block.Bytes, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key")
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("private key must be PKCS#8 or PKCS#1")
+}
+
+func parsePublicKey(der []byte) (crypto.PublicKey, error) {
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("public key must be PKIX or PKCS#1")
+}
+
+// Sign signs claims with the active key and sets the kid header
+func (r *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.active.Method, claims)
+	token.Header["kid"] = r.active.ID
+	return token.SignedString(r.active.signKey)
+}
+
+// Keyfunc picks the verification key by kid for jwt.Parse. Tokens without
+// a kid are legacy tokens signed with SECRATE_KEY.
+func (r *Keyring) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = LegacyKeyID
+	}
+
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if key.retired() {
+		return nil, fmt.Errorf("signing key %q is retired", kid)
+	}
+	// Never let the token choose the algorithm (e.g. HS256 keyed with an RSA public key)
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+	return key.verifyKey, nil
+}
+
+// JWKS returns the public keys so other services can verify our tokens.
+// HMAC secrets are never published.
+func (r *Keyring) JWKS() jose.JSONWebKeySet {
+	set := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{}}
+	for _, key := range r.keys {
+		if key.Method == jwt.SigningMethodHS256 || key.retired() {
+			continue
+		}
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       key.verifyKey,
+			KeyID:     key.ID,
+			Algorithm: key.Method.Alg(),
+			Use:       "sig",
+		})
+	}
+	// Stable order, active key first
+	sort.Slice(set.Keys, func(i, j int) bool {
+		if (set.Keys[i].KeyID == r.active.ID) != (set.Keys[j].KeyID == r.active.ID) {
+			return set.Keys[i].KeyID == r.active.ID
+		}
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+	return set
+}
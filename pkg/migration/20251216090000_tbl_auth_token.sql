@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ===============================
+-- Refresh Tokens (rotated on every use)
+-- ===============================
+CREATE TABLE IF NOT EXISTS tbl_refresh_token (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL
+        REFERENCES tbl_employee(id)
+        ON DELETE CASCADE,
+    -- SHA-256 of the opaque token, the token itself is never stored
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by UUID REFERENCES tbl_refresh_token(id),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_employee ON tbl_refresh_token(employee_id);
+
+-- ===============================
+-- Revoked Access Tokens (by jti, kept until the token expires)
+-- ===============================
+CREATE TABLE IF NOT EXISTS tbl_revoked_token (
+    jti UUID PRIMARY KEY,
+    employee_id UUID NOT NULL
+        REFERENCES tbl_employee(id)
+        ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Every access token issued before this instant is rejected
+ALTER TABLE tbl_employee ADD COLUMN IF NOT EXISTS tokens_revoked_at TIMESTAMP;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tbl_employee DROP COLUMN IF EXISTS tokens_revoked_at;
+DROP TABLE IF EXISTS tbl_revoked_token;
+DROP TABLE IF EXISTS tbl_refresh_token;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Failed login counters per account (email) and per client IP
+CREATE TABLE IF NOT EXISTS tbl_login_throttle (
+    id SERIAL PRIMARY KEY,
+    key_type VARCHAR(10) NOT NULL CHECK (key_type IN ('email', 'ip')),
+    key TEXT NOT NULL,
+    failed_count INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    last_failed_at TIMESTAMP NOT NULL DEFAULT now(),
+    -- Client IP of the latest failure, so an admin unlock of an account can
+    -- also lift the IP lockout it ran into
+    last_failed_ip TEXT,
+    UNIQUE (key_type, key)
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tbl_login_throttle;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ===============================
+-- TOTP enrollment (one row per employee)
+-- ===============================
+CREATE TABLE IF NOT EXISTS tbl_employee_mfa (
+    employee_id UUID PRIMARY KEY
+        REFERENCES tbl_employee(id)
+        ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Last accepted TOTP time step, used to reject code replays
+    last_used_step BIGINT,
+    enabled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- ===============================
+-- Single-use recovery codes (hashed)
+-- ===============================
+CREATE TABLE IF NOT EXISTS tbl_mfa_recovery_code (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL
+        REFERENCES tbl_employee(id)
+        ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_employee ON tbl_mfa_recovery_code(employee_id);
+
+-- Roles that must use MFA to login
+ALTER TABLE Tbl_Company_Settings
+ADD COLUMN IF NOT EXISTS mfa_required_roles TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS mfa_required_roles;
+DROP TABLE IF EXISTS tbl_mfa_recovery_code;
+DROP TABLE IF EXISTS tbl_employee_mfa;
+-- +goose StatementEnd
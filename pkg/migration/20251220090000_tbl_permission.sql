@@ -0,0 +1,123 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ===============================
+-- Permission catalog
+-- ===============================
+CREATE TABLE IF NOT EXISTS tbl_permission (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- ===============================
+-- Role -> permission grants
+-- ===============================
+CREATE TABLE IF NOT EXISTS tbl_role_permission (
+    role_id INT NOT NULL
+        REFERENCES Tbl_Role(id)
+        ON DELETE CASCADE,
+    permission_id INT NOT NULL
+        REFERENCES tbl_permission(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('employee.view_all',          'List all employees'),
+    ('employee.view_team',         'List own direct reports'),
+    ('employee.create',            'Create employees'),
+    ('employee.update',            'Update name, email, salary and dates of any employee'),
+    ('employee.password',          'Set the password of another employee'),
+    ('employee.role',              'Change employee roles'),
+    ('employee.manager',           'Assign employee managers'),
+    ('employee.designation',       'Assign employee designations'),
+    ('employee.status',            'Activate and deactivate employees'),
+    ('employee.unlock',            'Clear login lockouts'),
+    ('employee.mfa_reset',         'Reset two-factor authentication of an employee'),
+    ('employee.manage_superadmin', 'Create or modify SUPERADMIN users and change own role'),
+    ('leave.view_all',             'View leaves of all employees'),
+    ('leave.view_team',            'View leaves of direct reports'),
+    ('leave.approve',              'Final approval or rejection of any leave'),
+    ('leave.approve_team',         'First level approval or rejection of direct reports leaves'),
+    ('leave.withdraw',             'Final withdrawal of approved leaves'),
+    ('leave.withdraw_team',        'Request withdrawal of direct reports approved leaves'),
+    ('leave.cancel_any',           'Cancel pending leaves of other employees'),
+    ('leave.policy.create',        'Create leave policies'),
+    ('leave.policy.update',        'Update leave policies'),
+    ('leave.policy.delete',        'Delete leave policies'),
+    ('leave.timing.manage',        'View and update leave timing variants'),
+    ('leave_balance.view_all',     'View leave balances of other employees'),
+    ('leave_balance.adjust',       'Adjust leave balances'),
+    ('payroll.run',                'Run payroll'),
+    ('payroll.finalize',           'Finalize payroll'),
+    ('payroll.view_all',           'View payslips of all employees'),
+    ('settings.view',              'View company settings'),
+    ('settings.update',            'Update company settings'),
+    ('holiday.manage',             'Add and delete holidays'),
+    ('designation.manage',         'Create, update and delete designations'),
+    ('log.view',                   'View activity logs'),
+    ('equipment.category.manage',  'Manage equipment categories'),
+    ('equipment.view',             'List equipment'),
+    ('equipment.manage',           'Create, update and delete equipment'),
+    ('equipment.assign',           'Assign and return equipment'),
+    ('permission.manage',          'Manage role permissions')
+ON CONFLICT (code) DO NOTHING;
+
+-- Seed grants that match the previous hardcoded role checks
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM (VALUES
+    ('SUPERADMIN', 'employee.view_all'), ('ADMIN', 'employee.view_all'), ('HR', 'employee.view_all'),
+    ('MANAGER', 'employee.view_team'),
+    ('SUPERADMIN', 'employee.create'), ('ADMIN', 'employee.create'), ('HR', 'employee.create'),
+    ('SUPERADMIN', 'employee.update'), ('ADMIN', 'employee.update'),
+    ('SUPERADMIN', 'employee.password'), ('ADMIN', 'employee.password'), ('HR', 'employee.password'),
+    ('SUPERADMIN', 'employee.role'), ('ADMIN', 'employee.role'), ('HR', 'employee.role'),
+    ('SUPERADMIN', 'employee.manager'), ('ADMIN', 'employee.manager'), ('HR', 'employee.manager'),
+    ('SUPERADMIN', 'employee.designation'), ('ADMIN', 'employee.designation'), ('HR', 'employee.designation'),
+    ('SUPERADMIN', 'employee.status'), ('ADMIN', 'employee.status'), ('HR', 'employee.status'),
+    ('SUPERADMIN', 'employee.unlock'), ('ADMIN', 'employee.unlock'),
+    ('SUPERADMIN', 'employee.mfa_reset'),
+    ('SUPERADMIN', 'employee.manage_superadmin'),
+    ('SUPERADMIN', 'leave.view_all'), ('ADMIN', 'leave.view_all'), ('HR', 'leave.view_all'),
+    ('MANAGER', 'leave.view_team'),
+    ('SUPERADMIN', 'leave.approve'), ('ADMIN', 'leave.approve'),
+    ('MANAGER', 'leave.approve_team'),
+    ('SUPERADMIN', 'leave.withdraw'), ('ADMIN', 'leave.withdraw'),
+    ('MANAGER', 'leave.withdraw_team'),
+    ('SUPERADMIN', 'leave.cancel_any'), ('ADMIN', 'leave.cancel_any'), ('HR', 'leave.cancel_any'), ('MANAGER', 'leave.cancel_any'),
+    ('SUPERADMIN', 'leave.policy.create'),
+    ('SUPERADMIN', 'leave.policy.update'), ('ADMIN', 'leave.policy.update'), ('HR', 'leave.policy.update'),
+    ('SUPERADMIN', 'leave.policy.delete'), ('ADMIN', 'leave.policy.delete'), ('HR', 'leave.policy.delete'),
+    ('SUPERADMIN', 'leave.timing.manage'), ('ADMIN', 'leave.timing.manage'),
+    ('SUPERADMIN', 'leave_balance.view_all'), ('ADMIN', 'leave_balance.view_all'), ('HR', 'leave_balance.view_all'), ('MANAGER', 'leave_balance.view_all'),
+    ('SUPERADMIN', 'leave_balance.adjust'), ('ADMIN', 'leave_balance.adjust'), ('HR', 'leave_balance.adjust'),
+    ('SUPERADMIN', 'payroll.run'), ('ADMIN', 'payroll.run'), ('HR', 'payroll.run'),
+    ('SUPERADMIN', 'payroll.finalize'),
+    ('SUPERADMIN', 'payroll.view_all'), ('ADMIN', 'payroll.view_all'), ('HR', 'payroll.view_all'),
+    ('SUPERADMIN', 'settings.view'), ('ADMIN', 'settings.view'), ('HR', 'settings.view'),
+    ('SUPERADMIN', 'settings.update'), ('ADMIN', 'settings.update'), ('HR', 'settings.update'),
+    ('SUPERADMIN', 'holiday.manage'),
+    ('SUPERADMIN', 'designation.manage'), ('ADMIN', 'designation.manage'), ('HR', 'designation.manage'),
+    ('SUPERADMIN', 'log.view'),
+    ('SUPERADMIN', 'equipment.category.manage'), ('ADMIN', 'equipment.category.manage'), ('HR', 'equipment.category.manage'),
+    ('SUPERADMIN', 'equipment.view'), ('ADMIN', 'equipment.view'),
+    ('SUPERADMIN', 'equipment.manage'), ('ADMIN', 'equipment.manage'), ('HR', 'equipment.manage'),
+    ('SUPERADMIN', 'equipment.assign'), ('ADMIN', 'equipment.assign'), ('HR', 'equipment.assign'),
+    ('SUPERADMIN', 'permission.manage')
+) AS g(role, code)
+JOIN Tbl_Role r ON r.type = g.role
+JOIN tbl_permission p ON p.code = g.code
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tbl_role_permission;
+DROP TABLE IF EXISTS tbl_permission;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ===============================
+-- API keys for machine-to-machine access
+-- ===============================
+CREATE TABLE IF NOT EXISTS tbl_api_key (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    -- First characters of the key, shown in listings to identify it
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    -- Permission codes the key may use (see tbl_permission)
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID NOT NULL
+        REFERENCES tbl_employee(id)
+        ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(64),
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Requests made with an API key are logged against the key's creator and the key
+ALTER TABLE tbl_log
+ADD COLUMN IF NOT EXISTS api_key_id UUID REFERENCES tbl_api_key(id) ON DELETE SET NULL;
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('apikey.manage', 'Issue, list and revoke API keys')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM Tbl_Role r, tbl_permission p
+WHERE r.type = 'SUPERADMIN' AND p.code = 'apikey.manage'
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM tbl_permission WHERE code = 'apikey.manage';
+ALTER TABLE tbl_log DROP COLUMN IF EXISTS api_key_id;
+DROP TABLE IF EXISTS tbl_api_key;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ===============================
+-- Login sessions (one per sign-in, shared by its rotated refresh tokens)
+-- ===============================
+CREATE TABLE IF NOT EXISTS tbl_session (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL
+        REFERENCES tbl_employee(id)
+        ON DELETE CASCADE,
+    device VARCHAR(100) NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_employee ON tbl_session(employee_id);
+
+ALTER TABLE tbl_refresh_token
+ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES tbl_session(id) ON DELETE CASCADE;
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('employee.session_revoke', 'List sessions of an employee and sign them out everywhere')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM Tbl_Role r, tbl_permission p
+WHERE r.type IN ('SUPERADMIN', 'ADMIN') AND p.code = 'employee.session_revoke'
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM tbl_permission WHERE code = 'employee.session_revoke';
+ALTER TABLE tbl_refresh_token DROP COLUMN IF EXISTS session_id;
+DROP TABLE IF EXISTS tbl_session;
+-- +goose StatementEnd
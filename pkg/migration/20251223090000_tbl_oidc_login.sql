@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ===============================
+-- Pending SSO logins (between redirect to the provider and callback)
+-- ===============================
+CREATE TABLE IF NOT EXISTS tbl_oidc_login (
+    -- SHA-256 of the state parameter
+    state_hash VARCHAR(64) PRIMARY KEY,
+    -- PKCE verifier and nonce never leave the server
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tbl_oidc_login;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Actions taken while a SUPERADMIN impersonates an employee are logged
+-- against the employee (from_user_id) and the real actor
+ALTER TABLE tbl_log
+ADD COLUMN IF NOT EXISTS impersonator_id UUID REFERENCES tbl_employee(id) ON DELETE SET NULL;
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('employee.impersonate', 'Act as another employee for support')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM Tbl_Role r, tbl_permission p
+WHERE r.type = 'SUPERADMIN' AND p.code = 'employee.impersonate'
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM tbl_permission WHERE code = 'employee.impersonate';
+ALTER TABLE tbl_log DROP COLUMN IF EXISTS impersonator_id;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Email domains employees may be created with (empty = any domain)
+ALTER TABLE Tbl_Company_Settings
+ADD COLUMN IF NOT EXISTS allowed_email_domains TEXT[] NOT NULL DEFAULT '{zenithive.com}';
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS allowed_email_domains;
+-- +goose StatementEnd
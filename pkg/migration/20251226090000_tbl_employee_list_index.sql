@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Employee list: keyset pagination by (sort value, id). The indexes are on
+-- the exact sort expressions of employeeSortColumns, or they are never used.
+CREATE INDEX IF NOT EXISTS idx_employee_name_id ON Tbl_Employee(full_name, id);
+CREATE INDEX IF NOT EXISTS idx_employee_created_id
+    ON Tbl_Employee((COALESCE(created_at, TIMESTAMP 'epoch')), id);
+CREATE INDEX IF NOT EXISTS idx_employee_joining_id
+    ON Tbl_Employee((COALESCE(joining_date, DATE '0001-01-01')), id);
+CREATE INDEX IF NOT EXISTS idx_employee_status ON Tbl_Employee(status);
+
+-- Full-text search (?q=) over the name and the email split at "@" and ".",
+-- the same expression as employeeSearchDocument
+CREATE INDEX IF NOT EXISTS idx_employee_search ON Tbl_Employee USING GIN (
+    to_tsvector('simple', COALESCE(full_name, '') || ' ' || translate(COALESCE(email, ''), '@.', '  '))
+);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_employee_search;
+DROP INDEX IF EXISTS idx_employee_name_id;
+DROP INDEX IF EXISTS idx_employee_created_id;
+DROP INDEX IF EXISTS idx_employee_joining_id;
+DROP INDEX IF EXISTS idx_employee_status;
+-- +goose StatementEnd
@@ -0,0 +1,85 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Contact, personal and bank details of an employee. Columns ending in _enc
+-- hold values encrypted by the application (pkg/fieldcrypt).
+CREATE TABLE IF NOT EXISTS tbl_employee_profile (
+    employee_id UUID PRIMARY KEY REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+
+    -- Contact (editable by the employee)
+    phone TEXT,
+    personal_email TEXT,
+    address_line1 TEXT,
+    address_line2 TEXT,
+    city TEXT,
+    state TEXT,
+    postal_code TEXT,
+    country TEXT,
+
+    -- Personal
+    date_of_birth DATE,
+    gender TEXT,
+    blood_group TEXT,
+
+    -- Bank and government IDs
+    bank_account_holder TEXT,
+    bank_name TEXT,
+    bank_ifsc TEXT,
+    bank_account_number_enc TEXT,
+    pan_number_enc TEXT,
+    aadhaar_number_enc TEXT,
+    passport_number_enc TEXT,
+
+    updated_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS tbl_emergency_contact (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    relationship TEXT NOT NULL,
+    phone TEXT NOT NULL,
+    email TEXT,
+    position INT NOT NULL DEFAULT 0, -- order in which the employee listed them
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_emergency_contact_employee ON tbl_emergency_contact(employee_id);
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('profile.view_all', 'View contact and personal details of any employee'),
+    ('profile.update', 'Edit contact and personal details of any employee'),
+    ('profile.sensitive', 'View and edit bank account and government ID numbers')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM (VALUES
+    ('SUPERADMIN', 'profile.view_all'), ('ADMIN', 'profile.view_all'), ('HR', 'profile.view_all'),
+    ('SUPERADMIN', 'profile.update'), ('ADMIN', 'profile.update'), ('HR', 'profile.update'),
+    ('SUPERADMIN', 'profile.sensitive'), ('HR', 'profile.sensitive')
+) AS g(role, code)
+JOIN Tbl_Role r ON r.type = g.role
+JOIN tbl_permission p ON p.code = g.code
+ON CONFLICT DO NOTHING;
+
+-- The employee an action was taken on, when it matters for an audit (e.g.
+-- whose bank and ID numbers were revealed)
+ALTER TABLE tbl_log
+ADD COLUMN IF NOT EXISTS target_id UUID REFERENCES tbl_employee(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_log_target ON tbl_log(target_id) WHERE target_id IS NOT NULL;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_log_target;
+ALTER TABLE tbl_log DROP COLUMN IF EXISTS target_id;
+DELETE FROM tbl_permission WHERE code IN ('profile.view_all', 'profile.update', 'profile.sensitive');
+DROP TABLE IF EXISTS tbl_emergency_contact;
+DROP TABLE IF EXISTS tbl_employee_profile;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Files uploaded for an employee (offer letters, ID proofs, NDAs, ...). The
+-- content lives in the configured storage backend under storage_key.
+CREATE TABLE IF NOT EXISTS tbl_employee_document (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+    category TEXT NOT NULL
+        CHECK (category IN ('offer_letter', 'contract', 'nda', 'id_proof', 'certificate', 'other')),
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 TEXT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    uploaded_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_document_employee ON tbl_employee_document(employee_id);
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('document.view_all', 'List and download documents of any employee'),
+    ('document.manage', 'Upload and delete documents of any employee')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM Tbl_Role r, tbl_permission p
+WHERE r.type IN ('SUPERADMIN', 'ADMIN', 'HR') AND p.code IN ('document.view_all', 'document.manage')
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM tbl_permission WHERE code IN ('document.view_all', 'document.manage');
+DROP TABLE IF EXISTS tbl_employee_document;
+-- +goose StatementEnd
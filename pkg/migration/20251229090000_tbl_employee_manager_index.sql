@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Org chart: the recursive reports query walks down by manager_id
+CREATE INDEX IF NOT EXISTS idx_employee_manager_id ON Tbl_Employee(manager_id);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_employee_manager_id;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- An employee cannot be their own manager; clear any such row first
+UPDATE Tbl_Employee SET manager_id = NULL, updated_at = NOW() WHERE manager_id = id;
+
+ALTER TABLE Tbl_Employee
+    ADD CONSTRAINT chk_employee_not_own_manager CHECK (manager_id IS NULL OR manager_id <> id);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Employee DROP CONSTRAINT IF EXISTS chk_employee_not_own_manager;
+-- +goose StatementEnd
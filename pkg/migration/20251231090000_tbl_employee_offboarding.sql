@@ -0,0 +1,71 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- One exit per employee, started when an ending date is set. The employee is
+-- deactivated the day after ending_date; the exit is CLEARED once every
+-- checklist item is DONE or WAIVED.
+CREATE TABLE IF NOT EXISTS tbl_employee_offboarding (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+    ending_date DATE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'OPEN'
+        CHECK (status IN ('OPEN', 'CLEARED', 'CANCELLED')),
+    deactivated_at TIMESTAMP,
+    cleared_at TIMESTAMP,
+    created_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_employee_offboarding_current
+    ON tbl_employee_offboarding(employee_id) WHERE status <> 'CANCELLED';
+CREATE INDEX IF NOT EXISTS idx_employee_offboarding_ending_date
+    ON tbl_employee_offboarding(ending_date) WHERE status <> 'CANCELLED';
+
+-- Checklist. reference_id points at the equipment assignment or leave the
+-- item is about; those items close by themselves once it is resolved.
+CREATE TABLE IF NOT EXISTS tbl_offboarding_item (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    offboarding_id UUID NOT NULL REFERENCES tbl_employee_offboarding(id) ON DELETE CASCADE,
+    item_type TEXT NOT NULL
+        CHECK (item_type IN ('equipment_return', 'leave_cancellation', 'final_settlement', 'access_revocation')),
+    reference_id UUID,
+    title TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'DONE', 'WAIVED')),
+    note TEXT NOT NULL DEFAULT '',
+    completed_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_offboarding_item
+    ON tbl_offboarding_item(offboarding_id, item_type, COALESCE(reference_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+-- Active employees who already have an ending date get an exit; the
+-- checklist is filled in when it is first read or processed
+INSERT INTO tbl_employee_offboarding (employee_id, ending_date)
+SELECT id, ending_date FROM Tbl_Employee
+WHERE ending_date IS NOT NULL AND status = 'active'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('offboarding.manage', 'Start, track and cancel employee exits')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM Tbl_Role r, tbl_permission p
+WHERE r.type IN ('SUPERADMIN', 'ADMIN', 'HR') AND p.code = 'offboarding.manage'
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM tbl_permission WHERE code = 'offboarding.manage';
+DROP TABLE IF EXISTS tbl_offboarding_item;
+DROP TABLE IF EXISTS tbl_employee_offboarding;
+-- +goose StatementEnd
@@ -0,0 +1,78 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Checklist templates for new hires. Active templates for the new hire's role
+-- (or for every role when role is NULL) are copied to them when they are created.
+CREATE TABLE IF NOT EXISTS tbl_onboarding_template (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    role TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- owner_role is who completes the task: HR (onboarding.manage), the new
+-- hire's MANAGER or the EMPLOYEE themselves. due_offset_days counts from the
+-- joining date and may be negative for tasks done before the first day.
+CREATE TABLE IF NOT EXISTS tbl_onboarding_template_task (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_id UUID NOT NULL REFERENCES tbl_onboarding_template(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    owner_role TEXT NOT NULL CHECK (owner_role IN ('HR', 'MANAGER', 'EMPLOYEE')),
+    due_offset_days INT NOT NULL DEFAULT 0 CHECK (due_offset_days BETWEEN -365 AND 365),
+    position INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_onboarding_template_task_template
+    ON tbl_onboarding_template_task(template_id);
+
+-- A new hire's copy of the template tasks; editing or deleting the template
+-- later does not change checklists already handed out
+CREATE TABLE IF NOT EXISTS tbl_onboarding_task (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+    template_id UUID REFERENCES tbl_onboarding_template(id) ON DELETE SET NULL,
+    template_task_id UUID REFERENCES tbl_onboarding_template_task(id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    owner_role TEXT NOT NULL CHECK (owner_role IN ('HR', 'MANAGER', 'EMPLOYEE')),
+    due_offset_days INT NOT NULL DEFAULT 0,
+    due_date DATE NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'DONE', 'SKIPPED')),
+    note TEXT NOT NULL DEFAULT '',
+    completed_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_onboarding_task
+    ON tbl_onboarding_task(employee_id, template_task_id);
+CREATE INDEX IF NOT EXISTS idx_onboarding_task_due_date
+    ON tbl_onboarding_task(due_date) WHERE status = 'PENDING';
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('onboarding.manage', 'Manage onboarding templates and complete any onboarding task')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM Tbl_Role r, tbl_permission p
+WHERE r.type IN ('SUPERADMIN', 'ADMIN', 'HR') AND p.code = 'onboarding.manage'
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM tbl_permission WHERE code = 'onboarding.manage';
+DROP TABLE IF EXISTS tbl_onboarding_task;
+DROP TABLE IF EXISTS tbl_onboarding_template_task;
+DROP TABLE IF EXISTS tbl_onboarding_template;
+-- +goose StatementEnd
@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Salary history. The revision with the latest effective_from on or before a
+-- day is the salary in force that day; Tbl_Employee.salary mirrors today's.
+CREATE TABLE IF NOT EXISTS tbl_salary_revision (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+    salary NUMERIC(12, 2) NOT NULL CHECK (salary >= 0),
+    effective_from DATE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    approved_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (employee_id, effective_from)
+);
+
+-- Difference between what a finalized payslip paid and what it would have paid
+-- with a revision backdated into its month. PENDING arrears are added to the
+-- employee's next finalized payslip; negative amounts are recovered the same way.
+CREATE TABLE IF NOT EXISTS tbl_salary_arrear (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+    revision_id UUID REFERENCES tbl_salary_revision(id) ON DELETE SET NULL,
+    payslip_id UUID NOT NULL REFERENCES Tbl_Payslip(id) ON DELETE CASCADE,
+    month INT NOT NULL,
+    year INT NOT NULL,
+    paid_salary NUMERIC(12, 2) NOT NULL,
+    revised_salary NUMERIC(12, 2) NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'PAID')),
+    paid_payslip_id UUID REFERENCES Tbl_Payslip(id) ON DELETE SET NULL,
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_salary_arrear_pending
+    ON tbl_salary_arrear(employee_id) WHERE status = 'PENDING';
+
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS arrears_amount NUMERIC NOT NULL DEFAULT 0;
+
+-- Opening revision with the current salary, from the joining date
+INSERT INTO tbl_salary_revision (employee_id, salary, effective_from, reason)
+SELECT id, COALESCE(salary, 0), COALESCE(joining_date, created_at::date, CURRENT_DATE), 'Opening salary'
+FROM Tbl_Employee
+ON CONFLICT DO NOTHING;
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('salary.revise', 'Schedule and cancel salary revisions')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM Tbl_Role r, tbl_permission p
+WHERE r.type IN ('SUPERADMIN', 'ADMIN', 'HR') AND p.code = 'salary.revise'
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM tbl_permission WHERE code = 'salary.revise';
+ALTER TABLE Tbl_Payslip DROP COLUMN IF EXISTS arrears_amount;
+DROP TABLE IF EXISTS tbl_salary_arrear;
+DROP TABLE IF EXISTS tbl_salary_revision;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Organisational units. A department may sit under a parent department and
+-- have a head; designations stay job titles.
+CREATE TABLE IF NOT EXISTS tbl_department (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    parent_id UUID REFERENCES tbl_department(id) ON DELETE SET NULL,
+    head_id UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_department_name ON tbl_department(LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_department_parent_id ON tbl_department(parent_id);
+
+-- Every employee belongs to at most one department
+ALTER TABLE Tbl_Employee ADD COLUMN IF NOT EXISTS department_id UUID
+    REFERENCES tbl_department(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_employee_department_id ON Tbl_Employee(department_id);
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('department.manage', 'Create, edit and delete departments, assign members and view department reports')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM Tbl_Role r, tbl_permission p
+WHERE r.type IN ('SUPERADMIN', 'ADMIN', 'HR') AND p.code = 'department.manage'
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM tbl_permission WHERE code = 'department.manage';
+DROP INDEX IF EXISTS idx_employee_department_id;
+ALTER TABLE Tbl_Employee DROP COLUMN IF EXISTS department_id;
+DROP TABLE IF EXISTS tbl_department;
+-- +goose StatementEnd
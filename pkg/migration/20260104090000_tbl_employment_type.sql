@@ -0,0 +1,84 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Kinds of employment. Payroll skips types not included and pays absences in
+-- full where deduct_absences is off; probation_months is the default probation
+-- of new hires of the type (0 = none).
+CREATE TABLE IF NOT EXISTS tbl_employment_type (
+    code TEXT PRIMARY KEY CHECK (code ~ '^[A-Z][A-Z_]*$'),
+    name TEXT NOT NULL,
+    include_in_payroll BOOLEAN NOT NULL DEFAULT TRUE,
+    deduct_absences BOOLEAN NOT NULL DEFAULT TRUE,
+    probation_months INT NOT NULL DEFAULT 0 CHECK (probation_months BETWEEN 0 AND 24),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO tbl_employment_type (code, name, probation_months) VALUES
+    ('PERMANENT', 'Permanent', 6),
+    ('CONTRACT', 'Contractor', 0),
+    ('INTERN', 'Intern', 0)
+ON CONFLICT (code) DO NOTHING;
+
+-- probation_status is NULL for employees hired without probation
+ALTER TABLE Tbl_Employee
+    ADD COLUMN IF NOT EXISTS employment_type TEXT NOT NULL DEFAULT 'PERMANENT'
+        REFERENCES tbl_employment_type(code) ON UPDATE CASCADE,
+    ADD COLUMN IF NOT EXISTS probation_end_date DATE,
+    ADD COLUMN IF NOT EXISTS probation_status TEXT
+        CHECK (probation_status IN ('ON_PROBATION', 'CONFIRMED', 'TERMINATED')),
+    ADD COLUMN IF NOT EXISTS probation_reminder_sent_for DATE; -- end date the last reminder was about
+
+CREATE INDEX IF NOT EXISTS idx_employee_probation_end
+    ON Tbl_Employee(probation_end_date) WHERE probation_status = 'ON_PROBATION';
+
+-- Every confirmation, extension or termination of a probation
+CREATE TABLE IF NOT EXISTS tbl_probation_decision (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+    decision TEXT NOT NULL CHECK (decision IN ('CONFIRMED', 'EXTENDED', 'TERMINATED')),
+    previous_end_date DATE,
+    new_end_date DATE,
+    note TEXT NOT NULL DEFAULT '',
+    decided_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_probation_decision_employee ON tbl_probation_decision(employee_id);
+
+-- Leave policy per employment type: the yearly entitlement replaces the leave
+-- type's default_entitlement, and the leave can be closed during probation
+CREATE TABLE IF NOT EXISTS tbl_leave_type_rule (
+    leave_type_id INT NOT NULL REFERENCES Tbl_Leave_Type(id) ON DELETE CASCADE,
+    employment_type TEXT NOT NULL REFERENCES tbl_employment_type(code) ON UPDATE CASCADE ON DELETE CASCADE,
+    entitlement INT NOT NULL CHECK (entitlement >= 0),
+    allowed_on_probation BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (leave_type_id, employment_type)
+);
+
+INSERT INTO tbl_permission (code, description) VALUES
+    ('employment.manage', 'Configure employment types, change employment type and probation and record probation decisions')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO tbl_role_permission (role_id, permission_id)
+SELECT r.id, p.id
+FROM Tbl_Role r, tbl_permission p
+WHERE r.type IN ('SUPERADMIN', 'ADMIN', 'HR') AND p.code = 'employment.manage'
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM tbl_permission WHERE code = 'employment.manage';
+DROP TABLE IF EXISTS tbl_leave_type_rule;
+DROP TABLE IF EXISTS tbl_probation_decision;
+DROP INDEX IF EXISTS idx_employee_probation_end;
+ALTER TABLE Tbl_Employee
+    DROP COLUMN IF EXISTS probation_reminder_sent_for,
+    DROP COLUMN IF EXISTS probation_status,
+    DROP COLUMN IF EXISTS probation_end_date,
+    DROP COLUMN IF EXISTS employment_type;
+DROP TABLE IF EXISTS tbl_employment_type;
+-- +goose StatementEnd
@@ -0,0 +1,166 @@
+// Package ratelimit implements token-bucket rate limiting behind a Store
+// interface, so the in-memory buckets can be replaced by a shared store
+// when the API runs on more than one instance.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Burst requests at once, refilled at Rate requests per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Disabled reports whether the limit lets everything through
+func (l Limit) Disabled() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Result is the outcome of taking one token
+type Result struct {
+	Allowed    bool
+	Remaining  int           // tokens left after this request
+	RetryAfter time.Duration // when the next token is available (if not allowed)
+	ResetAfter time.Duration // when the bucket is full again
+}
+
+// Store keeps the buckets. Implementations must be safe for concurrent use.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// ParseLimits parses a comma separated list of "<group>=<count>/<s|m|h>[:<burst>]",
+// e.g. "default=300/m,auth=10/m:5,payroll=5/m". The burst defaults to count
+// and "<group>=0" disables a group.
+func ParseLimits(spec string) (map[string]Limit, error) {
+	limits := map[string]Limit{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		group, value, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(group) == "" {
+			return nil, fmt.Errorf("invalid rate limit %q", part)
+		}
+		limit, err := parseLimit(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit %q: %w", part, err)
+		}
+		limits[strings.TrimSpace(group)] = limit
+	}
+	return limits, nil
+}
+
+func parseLimit(value string) (Limit, error) {
+	if value == "0" {
+		return Limit{}, nil
+	}
+
+	rate, burstStr, hasBurst := strings.Cut(value, ":")
+	countStr, unit, ok := strings.Cut(rate, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("expected <count>/<unit>")
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("count must be a positive integer")
+	}
+
+	var per time.Duration
+	switch unit {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("unit must be s, m or h")
+	}
+
+	burst := count
+	if hasBurst {
+		if burst, err = strconv.Atoi(burstStr); err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("burst must be a positive integer")
+		}
+	}
+	return Limit{Rate: float64(count) / per.Seconds(), Burst: burst}, nil
+}
+
+// ------------------ IN-MEMORY STORE ------------------
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	fullAt time.Time // after this the bucket is full and can be forgotten
+}
+
+// MemoryStore keeps buckets in process memory. Limits are per instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, lastSweep: time.Now()}
+}
+
+// Take implements Store
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	// Refill for the time elapsed since the last request
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	res := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.ResetAfter = secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate)
+	b.fullAt = now.Add(res.ResetAfter)
+	return res, nil
+}
+
+// sweep drops buckets that are full again, at most once a minute
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.After(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
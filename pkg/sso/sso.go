@@ -0,0 +1,150 @@
+// Package sso implements the OpenID Connect authorization code flow with
+// PKCE against the company identity provider.
+package sso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// httpClient bounds calls to the provider (discovery, JWKS, token endpoint)
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// ErrDisabled is returned when no issuer is configured
+var ErrDisabled = errors.New("single sign-on is not configured")
+
+// Identity is what we take from a verified ID token
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Client talks to one OIDC provider. Discovery runs on first use and is
+// retried on the next request if the provider was unreachable.
+type Client struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func New(issuer, clientID, clientSecret, redirectURL string, scopes []string) *Client {
+	return &Client{
+		issuer:       issuer,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+	}
+}
+
+// Enabled reports whether an identity provider is configured
+func (c *Client) Enabled() bool {
+	return c.issuer != "" && c.clientID != ""
+}
+
+// discover fetches /.well-known/openid-configuration once. The provider keeps
+// its own background context to refresh the JWKS.
+func (c *Client) discover() (*oidc.Provider, error) {
+	if !c.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.provider != nil {
+		return c.provider, nil
+	}
+
+	provider, err := oidc.NewProvider(oidc.ClientContext(context.Background(), httpClient), c.issuer)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	c.provider = provider
+	return provider, nil
+}
+
+func (c *Client) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     c.clientID,
+		ClientSecret: c.clientSecret,
+		RedirectURL:  c.redirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       c.scopes,
+	}
+}
+
+// AuthCodeURL returns the provider URL the browser is sent to. The PKCE
+// challenge is derived from verifier, which must stay on the server.
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	provider, err := c.discover()
+	if err != nil {
+		return "", err
+	}
+	return c.oauth2Config(provider).AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(verifier),
+	), nil
+}
+
+// Exchange redeems the authorization code and verifies the returned ID
+// token: signature against the provider JWKS, issuer, audience, expiry and nonce.
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	provider, err := c.discover()
+	if err != nil {
+		return Identity{}, err
+	}
+
+	ctx = oidc.ClientContext(ctx, httpClient)
+	token, err := c.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return Identity{}, fmt.Errorf("code exchange failed: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return Identity{}, errors.New("provider did not return an ID token")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: c.clientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return Identity{}, errors.New("ID token nonce mismatch")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified *bool  `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return Identity{}, fmt.Errorf("invalid ID token claims: %w", err)
+	}
+
+	return Identity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified != nil && *claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// NewVerifier returns a random PKCE code verifier
+func NewVerifier() string {
+	return oauth2.GenerateVerifier()
+}
@@ -0,0 +1,64 @@
+// Package storage keeps uploaded files behind a small interface so they can
+// live on local disk in development and in S3-compatible object storage in
+// production.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned when no object exists under a key
+var ErrNotFound = errors.New("object not found")
+
+// ErrDisabled is reported while no backend is configured
+var ErrDisabled = errors.New("document storage is not configured")
+
+// Storage stores objects by key. Keys are slash separated paths like
+// "employees/<id>/documents/<id>" and are chosen by the application.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Config selects and configures a backend
+type Config struct {
+	Backend  string // "local" (default) or "s3"
+	LocalDir string
+
+	S3Endpoint  string // host[:port], e.g. "s3.amazonaws.com" or "localhost:9000"
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSSL    bool
+}
+
+// New returns the configured backend
+func New(cfg Config) (Storage, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocal(cfg.LocalDir)
+	case "s3":
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// validKey rejects keys that could escape the storage root
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// APIKeyAuth is what AuthMiddleware needs to authenticate an API key
+type APIKeyAuth struct {
+	ID        uuid.UUID      `db:"id"`
+	CreatedBy uuid.UUID      `db:"created_by"`
+	Scopes    pq.StringArray `db:"scopes"`
+	Revoked   bool           `db:"revoked"`
+	Expired   bool           `db:"expired"`
+}
+
+// ------------------ CREATE API KEY ------------------
+func (r *Repository) CreateAPIKey(tx *sqlx.Tx, name, prefix, hash string, scopes []string, createdBy uuid.UUID, validDays int) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.Get(&id, `
+		INSERT INTO tbl_api_key (name, key_prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, NOW() + make_interval(days => $6))
+		RETURNING id
+	`, name, prefix, hash, pq.Array(scopes), createdBy, validDays)
+	return id, err
+}
+
+// ------------------ GET API KEY BY HASH ------------------
+func (r *Repository) GetAPIKeyByHash(hash string) (APIKeyAuth, error) {
+	var key APIKeyAuth
+	err := r.DB.Get(&key, `
+		SELECT id, created_by, scopes,
+		       revoked_at IS NOT NULL AS revoked,
+		       expires_at < NOW() AS expired
+		FROM tbl_api_key
+		WHERE key_hash = $1
+	`, hash)
+	return key, err
+}
+
+// ------------------ TRACK API KEY USAGE ------------------
+// Written at most once a minute per key to keep hot keys cheap
+func (r *Repository) TouchAPIKey(id uuid.UUID, ip string) error {
+	_, err := r.DB.Exec(`
+		UPDATE tbl_api_key
+		SET last_used_at = NOW(), last_used_ip = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`, id, ip)
+	return err
+}
+
+// ------------------ LIST API KEYS ------------------
+func (r *Repository) GetAllAPIKeys() ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	err := r.DB.Select(&keys, `
+		SELECT k.id, k.name, k.key_prefix, k.scopes, k.created_by, e.full_name AS created_by_name,
+		       k.expires_at, k.last_used_at, k.last_used_ip, k.revoked_at, k.created_at
+		FROM tbl_api_key k
+		JOIN Tbl_Employee e ON e.id = k.created_by
+		ORDER BY k.created_at DESC
+	`)
+	return keys, err
+}
+
+// ------------------ REVOKE API KEY ------------------
+// Returns false if the key does not exist or was already revoked
+func (r *Repository) RevokeAPIKey(tx *sqlx.Tx, id uuid.UUID) (bool, error) {
+	result, err := tx.Exec(`
+		UPDATE tbl_api_key SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
@@ -49,6 +49,13 @@ func (r *Repository) GetDesignationByID(id uuid.UUID) (*Designation, error) {
 	return &designation, nil
 }
 
+// GetDesignationIDByName finds a designation by name (case-insensitive)
+func (r *Repository) GetDesignationIDByName(name string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.DB.Get(&id, `SELECT id FROM Tbl_Designation WHERE LOWER(designation_name) = LOWER($1) LIMIT 1`, name)
+	return id, err
+}
+
 // UpdateDesignation updates an existing designation
 func (r *Repository) UpdateDesignation(id uuid.UUID, name string, description *string) error {
 	query := `
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ DEPARTMENT ------------------
+
+const departmentSelect = `
+	SELECT d.*, p.name AS parent_name, h.full_name AS head_name,
+		(SELECT COUNT(*) FROM Tbl_Employee e
+		 WHERE e.department_id = d.id AND e.status = 'active') AS member_count
+	FROM tbl_department d
+	LEFT JOIN tbl_department p ON p.id = d.parent_id
+	LEFT JOIN Tbl_Employee h ON h.id = d.head_id
+`
+
+// departmentSubtree selects the department bound to param and every
+// department below it
+func departmentSubtree(param string) string {
+	return `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tbl_department WHERE id = ` + param + `
+			UNION
+			SELECT d.id FROM tbl_department d JOIN subtree s ON d.parent_id = s.id
+		)
+		SELECT id FROM subtree`
+}
+
+// ListDepartments returns every department, by name
+func (r *Repository) ListDepartments() ([]models.Department, error) {
+	departments := []models.Department{}
+	err := r.DB.Select(&departments, departmentSelect+` ORDER BY d.name`)
+	return departments, err
+}
+
+// GetDepartment returns the department or sql.ErrNoRows
+func (r *Repository) GetDepartment(id uuid.UUID) (models.Department, error) {
+	var d models.Department
+	err := r.DB.Get(&d, departmentSelect+` WHERE d.id = $1`, id)
+	return d, err
+}
+
+// GetSubDepartments returns the departments directly below the department
+func (r *Repository) GetSubDepartments(id uuid.UUID) ([]models.Department, error) {
+	departments := []models.Department{}
+	err := r.DB.Select(&departments, departmentSelect+` WHERE d.parent_id = $1 ORDER BY d.name`, id)
+	return departments, err
+}
+
+// DepartmentNameExists checks the name against every department other than excludeID
+func (r *Repository) DepartmentNameExists(name string, excludeID *uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.DB.Get(&exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM tbl_department
+			WHERE LOWER(name) = LOWER($1) AND ($2::uuid IS NULL OR id <> $2)
+		)
+	`, name, excludeID)
+	return exists, err
+}
+
+func (r *Repository) DepartmentExists(tx *sqlx.Tx, id uuid.UUID) (bool, error) {
+	var exists bool
+	err := tx.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tbl_department WHERE id = $1)`, id)
+	return exists, err
+}
+
+// LockDepartmentTree serialises changes of department parents until the
+// transaction ends, so two concurrent moves cannot close a cycle between them
+func (r *Repository) LockDepartmentTree(tx *sqlx.Tx) error {
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('tbl_department.parent_id'))`)
+	return err
+}
+
+// IsDepartmentBelow reports whether deptID is ancestorID or one of the
+// departments below it
+func (r *Repository) IsDepartmentBelow(tx *sqlx.Tx, ancestorID, deptID uuid.UUID) (bool, error) {
+	var below bool
+	err := tx.Get(&below, `SELECT $2 IN (`+departmentSubtree("$1")+`)`, ancestorID, deptID)
+	return below, err
+}
+
+func (r *Repository) CreateDepartment(tx *sqlx.Tx, name, description string, parentID, headID *uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO tbl_department (name, description, parent_id, head_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, name, description, parentID, headID).Scan(&id)
+	return id, err
+}
+
+// UpdateDepartment returns false when the department does not exist
+func (r *Repository) UpdateDepartment(tx *sqlx.Tx, id uuid.UUID, name, description string, parentID, headID *uuid.UUID) (bool, error) {
+	res, err := tx.Exec(`
+		UPDATE tbl_department
+		SET name = $2, description = $3, parent_id = $4, head_id = $5, updated_at = NOW()
+		WHERE id = $1
+	`, id, name, description, parentID, headID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteDepartment moves the sub-departments up to the department's parent and
+// deletes it; its members are left without a department. Returns false when
+// the department does not exist.
+func (r *Repository) DeleteDepartment(tx *sqlx.Tx, id uuid.UUID) (bool, error) {
+	_, err := tx.Exec(`
+		UPDATE tbl_department c
+		SET parent_id = d.parent_id, updated_at = NOW()
+		FROM tbl_department d
+		WHERE d.id = $1 AND c.parent_id = d.id
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	res, err := tx.Exec(`DELETE FROM tbl_department WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// UpdateEmployeeDepartment sets the employee's department, nil removes it
+func (r *Repository) UpdateEmployeeDepartment(tx *sqlx.Tx, empID uuid.UUID, departmentID *uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Employee
+		SET department_id = $1, updated_at = NOW()
+		WHERE id = $2
+	`, departmentID, empID)
+	return err
+}
+
+// GetDepartmentReport returns headcount, leave and payroll figures of every
+// department for the year, or one month of it when month is set. Employees
+// without a department are reported under a row with no department ID.
+func (r *Repository) GetDepartmentReport(year int, month *int) ([]models.DepartmentReport, error) {
+	report := []models.DepartmentReport{}
+	err := r.DB.Select(&report, `
+		WITH period AS (
+			SELECT MAKE_DATE($1, COALESCE($2::int, 1), 1) AS starts,
+			       (MAKE_DATE($1, COALESCE($2::int, 1), 1)
+			        + CASE WHEN $2::int IS NULL THEN INTERVAL '1 year' ELSE INTERVAL '1 month' END)::date AS ends
+		),
+		units AS (
+			SELECT id, name, parent_id FROM tbl_department
+			UNION ALL
+			SELECT NULL, 'Unassigned', NULL
+		),
+		leaves AS (
+			SELECT l.employee_id,
+			       SUM(l.days) FILTER (WHERE l.start_date >= p.starts AND l.start_date < p.ends) AS days,
+			       BOOL_OR(CURRENT_DATE BETWEEN l.start_date AND l.end_date) AS on_leave_today
+			FROM Tbl_Leave l
+			CROSS JOIN period p
+			WHERE l.status = 'APPROVED'
+			GROUP BY l.employee_id
+		),
+		pay AS (
+			SELECT s.employee_id, SUM(s.net_salary) AS net, SUM(s.deduction_amount) AS deductions
+			FROM Tbl_Payslip s
+			JOIN Tbl_Payroll_Run pr ON pr.id = s.payroll_run_id
+			CROSS JOIN period p
+			WHERE pr.status = 'FINALIZED'
+			  AND MAKE_DATE(pr.year, pr.month, 1) >= p.starts
+			  AND MAKE_DATE(pr.year, pr.month, 1) < p.ends
+			GROUP BY s.employee_id
+		)
+		SELECT u.id AS department_id, u.name, u.parent_id,
+		       COUNT(e.id) FILTER (WHERE e.status = 'active') AS headcount,
+		       COUNT(e.id) FILTER (WHERE e.joining_date >= p.starts AND e.joining_date < p.ends) AS joiners,
+		       COUNT(e.id) FILTER (WHERE e.ending_date >= p.starts AND e.ending_date < p.ends) AS leavers,
+		       COUNT(e.id) FILTER (WHERE e.status = 'active' AND l.on_leave_today) AS on_leave_today,
+		       COALESCE(SUM(l.days), 0)::float8 AS leave_days,
+		       COALESCE(SUM(pay.net), 0)::float8 AS payroll_net,
+		       COALESCE(SUM(pay.deductions), 0)::float8 AS payroll_deductions
+		FROM units u
+		CROSS JOIN period p
+		LEFT JOIN Tbl_Employee e ON e.department_id IS NOT DISTINCT FROM u.id
+		LEFT JOIN leaves l ON l.employee_id = e.id
+		LEFT JOIN pay ON pay.employee_id = e.id
+		WHERE u.id IS NOT NULL OR e.id IS NOT NULL -- no Unassigned row when everyone has a department
+		GROUP BY u.id, u.name, u.parent_id
+		ORDER BY u.id IS NULL, u.name
+	`, year, month)
+	return report, err
+}
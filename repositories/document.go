@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ EMPLOYEE DOCUMENTS ------------------
+
+// CreateEmployeeDocument inserts doc and sets its CreatedAt
+func (r *Repository) CreateEmployeeDocument(tx *sqlx.Tx, doc *models.EmployeeDocument) error {
+	return tx.QueryRow(`
+		INSERT INTO tbl_employee_document
+			(id, employee_id, category, file_name, content_type, size_bytes, sha256, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, doc.ID, doc.EmployeeID, doc.Category, doc.FileName, doc.ContentType, doc.SizeBytes, doc.SHA256, doc.StorageKey, doc.UploadedBy).Scan(&doc.CreatedAt)
+}
+
+// GetEmployeeDocuments lists an employee's documents, newest first, optionally of one category
+func (r *Repository) GetEmployeeDocuments(empID uuid.UUID, category string) ([]models.EmployeeDocument, error) {
+	docs := []models.EmployeeDocument{}
+	err := r.DB.Select(&docs, `
+		SELECT * FROM tbl_employee_document
+		WHERE employee_id = $1 AND ($2 = '' OR category = $2)
+		ORDER BY created_at DESC
+	`, empID, category)
+	return docs, err
+}
+
+// GetEmployeeDocument returns sql.ErrNoRows unless the document belongs to the employee
+func (r *Repository) GetEmployeeDocument(empID, docID uuid.UUID) (models.EmployeeDocument, error) {
+	var doc models.EmployeeDocument
+	err := r.DB.Get(&doc, `SELECT * FROM tbl_employee_document WHERE id = $1 AND employee_id = $2`, docID, empID)
+	return doc, err
+}
+
+func (r *Repository) DeleteEmployeeDocument(tx *sqlx.Tx, docID uuid.UUID) error {
+	_, err := tx.Exec(`DELETE FROM tbl_employee_document WHERE id = $1`, docID)
+	return err
+}
@@ -10,3 +10,21 @@ func (r *Repository) GetEmployeeStatus(employeeID uuid.UUID) (string, error) {
 	`, employeeID)
 	return status, err
 }
+
+// EmployeePrincipal is the authorization relevant state of an employee
+type EmployeePrincipal struct {
+	Role   string `db:"role"`
+	Status string `db:"status"`
+}
+
+// 2. Get current role and status
+func (r *Repository) GetEmployeePrincipal(employeeID uuid.UUID) (EmployeePrincipal, error) {
+	var p EmployeePrincipal
+	err := r.DB.Get(&p, `
+		SELECT r.type AS role, e.status
+		FROM Tbl_Employee e
+		JOIN Tbl_Role r ON e.role_id = r.id
+		WHERE e.id = $1
+	`, employeeID)
+	return p, err
+}
@@ -0,0 +1,245 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ EMPLOYMENT TYPE ------------------
+
+const employmentTypeSelect = `
+	SELECT t.*,
+		(SELECT COUNT(*) FROM Tbl_Employee e
+		 WHERE e.employment_type = t.code AND e.status = 'active') AS employee_count
+	FROM tbl_employment_type t
+`
+
+// EmployeeProbation is the employment state of an employee
+type EmployeeProbation struct {
+	EmploymentType   string     `db:"employment_type"`
+	ProbationEndDate *time.Time `db:"probation_end_date"`
+	ProbationStatus  *string    `db:"probation_status"`
+}
+
+func (r *Repository) ListEmploymentTypes() ([]models.EmploymentType, error) {
+	types := []models.EmploymentType{}
+	err := r.DB.Select(&types, employmentTypeSelect+` ORDER BY t.name`)
+	return types, err
+}
+
+// GetEmploymentType returns the type or sql.ErrNoRows
+func (r *Repository) GetEmploymentType(q sqlx.Queryer, code string) (models.EmploymentType, error) {
+	var t models.EmploymentType
+	err := sqlx.Get(q, &t, employmentTypeSelect+` WHERE t.code = $1`, code)
+	return t, err
+}
+
+func (r *Repository) CreateEmploymentType(tx *sqlx.Tx, input models.EmploymentTypeInput, includeInPayroll, deductAbsences bool) error {
+	_, err := tx.Exec(`
+		INSERT INTO tbl_employment_type (code, name, include_in_payroll, deduct_absences, probation_months)
+		VALUES ($1, $2, $3, $4, $5)
+	`, input.Code, input.Name, includeInPayroll, deductAbsences, input.ProbationMonths)
+	return err
+}
+
+// UpdateEmploymentType returns false when the type does not exist
+func (r *Repository) UpdateEmploymentType(tx *sqlx.Tx, code string, input models.EmploymentTypeInput, includeInPayroll, deductAbsences bool) (bool, error) {
+	res, err := tx.Exec(`
+		UPDATE tbl_employment_type
+		SET name = $2, include_in_payroll = $3, deduct_absences = $4, probation_months = $5, updated_at = NOW()
+		WHERE code = $1
+	`, code, input.Name, includeInPayroll, deductAbsences, input.ProbationMonths)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// EmploymentTypeInUse reports whether any employee, active or not, has the type
+func (r *Repository) EmploymentTypeInUse(tx *sqlx.Tx, code string) (bool, error) {
+	var used bool
+	err := tx.Get(&used, `SELECT EXISTS (SELECT 1 FROM Tbl_Employee WHERE employment_type = $1)`, code)
+	return used, err
+}
+
+// DeleteEmploymentType returns false when the type does not exist; its leave
+// rules go with it
+func (r *Repository) DeleteEmploymentType(tx *sqlx.Tx, code string) (bool, error) {
+	res, err := tx.Exec(`DELETE FROM tbl_employment_type WHERE code = $1`, code)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ------------------ PROBATION ------------------
+
+// GetEmployeeProbation returns the employee's employment state and locks the
+// employee for the transaction
+func (r *Repository) GetEmployeeProbation(tx *sqlx.Tx, empID uuid.UUID) (EmployeeProbation, error) {
+	var p EmployeeProbation
+	err := tx.Get(&p, `
+		SELECT employment_type, probation_end_date, probation_status
+		FROM Tbl_Employee WHERE id = $1
+		FOR UPDATE
+	`, empID)
+	return p, err
+}
+
+// GetEmployeeProbationMonths returns the default probation of the employee's type
+func (r *Repository) GetEmployeeProbationMonths(tx *sqlx.Tx, empID uuid.UUID) (int, error) {
+	var months int
+	err := tx.Get(&months, `
+		SELECT t.probation_months
+		FROM Tbl_Employee e
+		JOIN tbl_employment_type t ON t.code = e.employment_type
+		WHERE e.id = $1
+	`, empID)
+	return months, err
+}
+
+func (r *Repository) SetEmployeeEmploymentType(tx *sqlx.Tx, empID uuid.UUID, code string) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Employee SET employment_type = $2, updated_at = NOW() WHERE id = $1
+	`, empID, code)
+	return err
+}
+
+// SetProbation sets the probation status and end date of the employee. A new
+// end date is reminded about again.
+func (r *Repository) SetProbation(tx *sqlx.Tx, empID uuid.UUID, status string, endDate *time.Time) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Employee
+		SET probation_status = $2, probation_end_date = $3, updated_at = NOW(),
+		    probation_reminder_sent_for = CASE WHEN probation_end_date IS DISTINCT FROM $3
+		                                       THEN NULL ELSE probation_reminder_sent_for END
+		WHERE id = $1
+	`, empID, status, endDate)
+	return err
+}
+
+func (r *Repository) CreateProbationDecision(tx *sqlx.Tx, d *models.ProbationDecision) error {
+	return tx.QueryRow(`
+		INSERT INTO tbl_probation_decision (employee_id, decision, previous_end_date, new_end_date, note, decided_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, d.EmployeeID, d.Decision, d.PreviousEndDate, d.NewEndDate, d.Note, d.DecidedBy).Scan(&d.ID, &d.CreatedAt)
+}
+
+// GetProbationDecisions returns the employee's probation decisions, latest first
+func (r *Repository) GetProbationDecisions(empID uuid.UUID) ([]models.ProbationDecision, error) {
+	decisions := []models.ProbationDecision{}
+	err := r.DB.Select(&decisions, `
+		SELECT d.*, b.full_name AS decided_by_name
+		FROM tbl_probation_decision d
+		LEFT JOIN Tbl_Employee b ON b.id = d.decided_by
+		WHERE d.employee_id = $1
+		ORDER BY d.created_at DESC
+	`, empID)
+	return decisions, err
+}
+
+const probationDueSelect = `
+	SELECT e.id AS employee_id, e.full_name, e.email, e.employment_type,
+		m.full_name AS manager_name, e.probation_end_date,
+		e.probation_end_date - CURRENT_DATE AS days_left
+	FROM Tbl_Employee e
+	LEFT JOIN Tbl_Employee m ON m.id = e.manager_id
+`
+
+// GetProbationsDue returns active employees on probation ending within
+// withinDays days or already past its end, soonest first
+func (r *Repository) GetProbationsDue(withinDays int) ([]models.ProbationDue, error) {
+	due := []models.ProbationDue{}
+	err := r.DB.Select(&due, probationDueSelect+`
+		WHERE e.status = 'active' AND e.probation_status = 'ON_PROBATION'
+		  AND e.probation_end_date <= CURRENT_DATE + $1::int
+		ORDER BY e.probation_end_date, e.full_name
+	`, withinDays)
+	return due, err
+}
+
+// ClaimProbationReminders marks the probations ending within withinDays days
+// as reminded and returns them; each end date is only returned once unless
+// its claim is released with ReleaseProbationReminder
+func (r *Repository) ClaimProbationReminders(withinDays int) ([]models.ProbationDue, error) {
+	due := []models.ProbationDue{}
+	err := r.DB.Select(&due, `
+		WITH claimed AS (
+			UPDATE Tbl_Employee
+			SET probation_reminder_sent_for = probation_end_date
+			WHERE status = 'active' AND probation_status = 'ON_PROBATION'
+			  AND probation_end_date <= CURRENT_DATE + $1::int
+			  AND probation_reminder_sent_for IS DISTINCT FROM probation_end_date
+			RETURNING id
+		)
+	`+probationDueSelect+`
+		JOIN claimed c ON c.id = e.id
+		ORDER BY e.probation_end_date
+	`, withinDays)
+	return due, err
+}
+
+// ReleaseProbationReminder undoes the claim on the reminder for endDate so the
+// next run sends it again; a newer end date keeps its own state
+func (r *Repository) ReleaseProbationReminder(empID uuid.UUID, endDate time.Time) error {
+	_, err := r.DB.Exec(`
+		UPDATE Tbl_Employee SET probation_reminder_sent_for = NULL
+		WHERE id = $1 AND probation_reminder_sent_for = $2
+	`, empID, endDate)
+	return err
+}
+
+// ------------------ LEAVE TYPE RULES ------------------
+
+// LeaveEntitlement is the leave policy that applies to one employee
+type LeaveEntitlement struct {
+	Entitlement int  `db:"entitlement"`
+	Allowed     bool `db:"allowed"` // false while on probation if the rule closes the leave type
+}
+
+func (r *Repository) GetLeaveTypeRules(leaveTypeID int) ([]models.LeaveTypeRule, error) {
+	rules := []models.LeaveTypeRule{}
+	err := r.DB.Select(&rules, `
+		SELECT * FROM tbl_leave_type_rule WHERE leave_type_id = $1 ORDER BY employment_type
+	`, leaveTypeID)
+	return rules, err
+}
+
+// ReplaceLeaveTypeRules swaps the leave type's rules for the given ones
+func (r *Repository) ReplaceLeaveTypeRules(tx *sqlx.Tx, leaveTypeID int, rules []models.LeaveTypeRule) error {
+	if _, err := tx.Exec(`DELETE FROM tbl_leave_type_rule WHERE leave_type_id = $1`, leaveTypeID); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		_, err := tx.Exec(`
+			INSERT INTO tbl_leave_type_rule (leave_type_id, employment_type, entitlement, allowed_on_probation)
+			VALUES ($1, $2, $3, $4)
+		`, leaveTypeID, rule.EmploymentType, rule.Entitlement, rule.AllowedOnProbation)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLeaveEntitlement returns the yearly entitlement of the employee for the
+// leave type, from the rule of their employment type or the type's default
+func (r *Repository) GetLeaveEntitlement(q sqlx.Queryer, empID uuid.UUID, leaveTypeID int) (LeaveEntitlement, error) {
+	var e LeaveEntitlement
+	err := sqlx.Get(q, &e, `
+		SELECT COALESCE(r.entitlement, lt.default_entitlement, 0) AS entitlement,
+		       (r.allowed_on_probation IS NOT FALSE
+		        OR e.probation_status IS DISTINCT FROM 'ON_PROBATION') AS allowed
+		FROM Tbl_Leave_Type lt
+		JOIN Tbl_Employee e ON e.id = $1
+		LEFT JOIN tbl_leave_type_rule r ON r.leave_type_id = lt.id AND r.employment_type = e.employment_type
+		WHERE lt.id = $2
+	`, empID, leaveTypeID)
+	return e, err
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import "github.com/google/uuid"
+
+// ------------------ IMPERSONATION AUDIT ------------------
+// LogImpersonatedRequest writes a tbl_log entry for a request made with an
+// impersonation token, recording both the employee and the real actor
+func (r *Repository) LogImpersonatedRequest(employeeID, impersonatorID uuid.UUID, component, action string) error {
+	if len(action) > 255 {
+		action = action[:255]
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO tbl_log (from_user_id, action, component, impersonator_id)
+		VALUES ($1, $2, $3, $4)
+	`, employeeID, action, component, impersonatorID)
+	return err
+}
@@ -246,7 +246,10 @@ func (r *Repository) GetAllEmployeeLeave(userID uuid.UUID) ([]models.LeaveRespon
 	err := r.DB.Select(&result, query, userID)
 	return result, err
 }
-func (r *Repository) GetAllleavebaseonassignManager(userID uuid.UUID) ([]models.LeaveResponse, error) {
+
+// GetAllleavebaseonassignManager returns the manager's and their team's leaves,
+// optionally only of employees in a department or the departments below it
+func (r *Repository) GetAllleavebaseonassignManager(userID uuid.UUID, departmentID *uuid.UUID) ([]models.LeaveResponse, error) {
 
 	var result []models.LeaveResponse
 	query := `
@@ -268,13 +271,16 @@ func (r *Repository) GetAllleavebaseonassignManager(userID uuid.UUID) ([]models.
 		INNER JOIN Tbl_Leave_Type lt ON lt.id = l.leave_type_id
 		LEFT JOIN Tbl_Half h ON l.half_id = h.id
 		WHERE (e.manager_id = $1 OR l.employee_id = $1)
+		  AND ($2::uuid IS NULL OR e.department_id IN (` + departmentSubtree("$2") + `))
 		ORDER BY l.created_at DESC`
 
-	err := r.DB.Select(&result, query, userID)
+	err := r.DB.Select(&result, query, userID, departmentID)
 	return result, err
 }
 
-func (r *Repository) GetAllLeave() ([]models.LeaveResponse, error) {
+// GetAllLeave returns every leave, optionally only of employees in a
+// department or the departments below it
+func (r *Repository) GetAllLeave(departmentID *uuid.UUID) ([]models.LeaveResponse, error) {
 	var result []models.LeaveResponse
 	query := `
 		SELECT 
@@ -294,9 +300,10 @@ func (r *Repository) GetAllLeave() ([]models.LeaveResponse, error) {
 		INNER JOIN Tbl_Employee e ON l.employee_id = e.id
 		INNER JOIN Tbl_Leave_Type lt ON lt.id = l.leave_type_id
 		LEFT JOIN Tbl_Half h ON l.half_id = h.id
+		WHERE $1::uuid IS NULL OR e.department_id IN (` + departmentSubtree("$1") + `)
 		ORDER BY l.created_at DESC`
 
-	err := r.DB.Select(&result, query)
+	err := r.DB.Select(&result, query, departmentID)
 	return result, err
 
 }
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	ThrottleKeyEmail = "email"
+	ThrottleKeyIP    = "ip"
+)
+
+// GetLoginLockRemaining returns how long the key is still locked (0 if not locked)
+func (r *Repository) GetLoginLockRemaining(keyType, key string) (time.Duration, error) {
+	var seconds float64
+	err := r.DB.Get(&seconds, `
+		SELECT EXTRACT(EPOCH FROM (locked_until - NOW()))
+		FROM tbl_login_throttle
+		WHERE key_type = $1 AND key = $2 AND locked_until > NOW()
+	`, keyType, key)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RecordFailedLogin increments the failure counter of the key and returns the
+// new count. Counters whose last failure is older than resetAfter start over.
+// ip is the client IP the failure came from.
+func (r *Repository) RecordFailedLogin(keyType, key, ip string, resetAfter time.Duration) (int, error) {
+	var count int
+	err := r.DB.Get(&count, `
+		INSERT INTO tbl_login_throttle (key_type, key, failed_count, last_failed_at, last_failed_ip)
+		VALUES ($1, $2, 1, NOW(), $4)
+		ON CONFLICT (key_type, key) DO UPDATE SET
+			failed_count = CASE
+				WHEN tbl_login_throttle.last_failed_at < NOW() - make_interval(secs => $3) THEN 1
+				ELSE tbl_login_throttle.failed_count + 1
+			END,
+			last_failed_at = NOW(),
+			last_failed_ip = EXCLUDED.last_failed_ip
+		RETURNING failed_count
+	`, keyType, key, resetAfter.Seconds(), ip)
+	return count, err
+}
+
+// LockLogin locks the key for the given duration
+func (r *Repository) LockLogin(keyType, key string, d time.Duration) error {
+	_, err := r.DB.Exec(`
+		UPDATE tbl_login_throttle
+		SET locked_until = NOW() + make_interval(secs => $3)
+		WHERE key_type = $1 AND key = $2
+	`, keyType, key, d.Seconds())
+	return err
+}
+
+// ClearLoginThrottle resets the counter and lock of the key
+func (r *Repository) ClearLoginThrottle(keyType, key string) error {
+	_, err := r.DB.Exec(`
+		DELETE FROM tbl_login_throttle WHERE key_type = $1 AND key = $2
+	`, keyType, key)
+	return err
+}
+
+// ClearAccountLoginThrottle resets the counter and lock of the email and of
+// the client IP its latest failed login came from
+func (r *Repository) ClearAccountLoginThrottle(tx *sqlx.Tx, email string) error {
+	_, err := tx.Exec(`
+		WITH account AS (
+			DELETE FROM tbl_login_throttle
+			WHERE key_type = 'email' AND key = $1
+			RETURNING last_failed_ip
+		)
+		DELETE FROM tbl_login_throttle
+		WHERE key_type = 'ip' AND key IN (SELECT last_failed_ip FROM account)
+	`, email)
+	return err
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// EmployeeMFA is the TOTP enrollment of an employee
+type EmployeeMFA struct {
+	EmployeeID uuid.UUID `db:"employee_id"`
+	Secret     string    `db:"secret"`
+	Enabled    bool      `db:"enabled"`
+}
+
+// GetEmployeeMFA returns sql.ErrNoRows when the employee never started enrollment
+func (r *Repository) GetEmployeeMFA(employeeID uuid.UUID) (EmployeeMFA, error) {
+	var mfa EmployeeMFA
+	err := r.DB.Get(&mfa, `
+		SELECT employee_id, secret, enabled
+		FROM tbl_employee_mfa
+		WHERE employee_id = $1
+	`, employeeID)
+	return mfa, err
+}
+
+// SavePendingMFA stores a new (not yet enabled) secret, replacing any
+// unfinished enrollment. Enabled enrollments are left untouched.
+func (r *Repository) SavePendingMFA(employeeID uuid.UUID, secret string) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO tbl_employee_mfa (employee_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (employee_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = NULL, updated_at = NOW()
+		WHERE tbl_employee_mfa.enabled = FALSE
+	`, employeeID, secret)
+	return err
+}
+
+// EnableMFA marks the enrollment active
+func (r *Repository) EnableMFA(tx *sqlx.Tx, employeeID uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE tbl_employee_mfa
+		SET enabled = TRUE, enabled_at = NOW(), updated_at = NOW()
+		WHERE employee_id = $1
+	`, employeeID)
+	return err
+}
+
+// UseTOTPStep records the accepted time step. It returns false when the
+// step (or a later one) was already used, i.e. the code is being replayed.
+func (r *Repository) UseTOTPStep(employeeID uuid.UUID, step int64) (bool, error) {
+	result, err := r.DB.Exec(`
+		UPDATE tbl_employee_mfa
+		SET last_used_step = $2, updated_at = NOW()
+		WHERE employee_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)
+	`, employeeID, step)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// ReplaceRecoveryCodes deletes the old recovery codes and stores new hashes
+func (r *Repository) ReplaceRecoveryCodes(tx *sqlx.Tx, employeeID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM tbl_mfa_recovery_code WHERE employee_id = $1`, employeeID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO tbl_mfa_recovery_code (employee_id, code_hash)
+		SELECT $1, unnest($2::text[])
+	`, employeeID, pq.Array(codeHashes))
+	return err
+}
+
+// UseRecoveryCode consumes an unused recovery code. It returns false if none matched.
+func (r *Repository) UseRecoveryCode(employeeID uuid.UUID, codeHash string) (bool, error) {
+	result, err := r.DB.Exec(`
+		UPDATE tbl_mfa_recovery_code
+		SET used_at = NOW()
+		WHERE employee_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, employeeID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// DeleteEmployeeMFA removes the enrollment and all recovery codes
+func (r *Repository) DeleteEmployeeMFA(tx *sqlx.Tx, employeeID uuid.UUID) error {
+	if _, err := tx.Exec(`DELETE FROM tbl_mfa_recovery_code WHERE employee_id = $1`, employeeID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`DELETE FROM tbl_employee_mfa WHERE employee_id = $1`, employeeID)
+	return err
+}
+
+// GetMFARequiredRoles returns the roles that must use MFA
+func (r *Repository) GetMFARequiredRoles() ([]string, error) {
+	var roles pq.StringArray
+	err := r.DB.Get(&roles, `SELECT mfa_required_roles FROM Tbl_Company_Settings LIMIT 1`)
+	return roles, err
+}
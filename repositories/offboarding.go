@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ OFFBOARDING ------------------
+
+const offboardingSelect = `
+	SELECT o.*, e.full_name AS employee_name,
+		(SELECT COUNT(*) FROM tbl_offboarding_item i
+		 WHERE i.offboarding_id = o.id AND i.status = 'PENDING') AS pending_items
+	FROM tbl_employee_offboarding o
+	JOIN Tbl_Employee e ON e.id = o.employee_id
+`
+
+// openLeaveCondition matches leaves (aliased l) of an exiting employee that
+// still need a decision: anything in flight, and approved leave after the
+// ending date (aliased o)
+const openLeaveCondition = `(
+	l.status IN ('Pending', 'MANAGER_APPROVED', 'WITHDRAWAL_PENDING')
+	OR (l.status = 'APPROVED' AND l.end_date > o.ending_date)
+)`
+
+// GetCurrentOffboarding returns the employee's exit that is not cancelled and
+// locks it for the transaction
+func (r *Repository) GetCurrentOffboarding(tx *sqlx.Tx, empID uuid.UUID) (models.Offboarding, error) {
+	var o models.Offboarding
+	err := tx.Get(&o, offboardingSelect+`
+		WHERE o.employee_id = $1 AND o.status <> 'CANCELLED'
+		FOR UPDATE OF o
+	`, empID)
+	return o, err
+}
+
+func (r *Repository) CreateOffboarding(tx *sqlx.Tx, empID uuid.UUID, endingDate time.Time, reason string, createdBy uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO tbl_employee_offboarding (employee_id, ending_date, reason, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, empID, endingDate, reason, createdBy).Scan(&id)
+	return id, err
+}
+
+func (r *Repository) UpdateOffboarding(tx *sqlx.Tx, id uuid.UUID, endingDate time.Time, reason string) error {
+	_, err := tx.Exec(`
+		UPDATE tbl_employee_offboarding
+		SET ending_date = $2, reason = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, endingDate, reason)
+	return err
+}
+
+func (r *Repository) CancelOffboarding(tx *sqlx.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE tbl_employee_offboarding
+		SET status = 'CANCELLED', updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// SetEmployeeEndingDate keeps Tbl_Employee.ending_date in line with the exit
+func (r *Repository) SetEmployeeEndingDate(tx *sqlx.Tx, empID uuid.UUID, endingDate *time.Time) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Employee SET ending_date = $2, updated_at = NOW() WHERE id = $1
+	`, empID, endingDate)
+	return err
+}
+
+// SyncOffboardingItems adds checklist items for equipment still held and
+// leaves still open, closes items whose equipment was returned or whose leave
+// was resolved, and sets the exit to CLEARED or back to OPEN accordingly
+func (r *Repository) SyncOffboardingItems(tx *sqlx.Tx, id uuid.UUID) error {
+	queries := []string{
+		`INSERT INTO tbl_offboarding_item (offboarding_id, item_type, title)
+		 VALUES ($1, 'final_settlement', 'Final settlement'),
+		        ($1, 'access_revocation', 'Revoke system access')
+		 ON CONFLICT DO NOTHING`,
+
+		`INSERT INTO tbl_offboarding_item (offboarding_id, item_type, reference_id, title)
+		 SELECT o.id, 'equipment_return', ea.id,
+		        'Return ' || eq.name || CASE WHEN ea.quantity > 1 THEN ' (x' || ea.quantity || ')' ELSE '' END
+		 FROM tbl_employee_offboarding o
+		 JOIN tbl_equipment_assignment ea ON ea.employee_id = o.employee_id AND ea.returned_at IS NULL
+		 JOIN tbl_equipment eq ON eq.id = ea.equipment_id
+		 WHERE o.id = $1
+		 ON CONFLICT DO NOTHING`,
+
+		`INSERT INTO tbl_offboarding_item (offboarding_id, item_type, reference_id, title)
+		 SELECT o.id, 'leave_cancellation', l.id,
+		        'Leave ' || TO_CHAR(l.start_date, 'YYYY-MM-DD') || ' to ' || TO_CHAR(l.end_date, 'YYYY-MM-DD') || ' (' || l.status || ')'
+		 FROM tbl_employee_offboarding o
+		 JOIN Tbl_Leave l ON l.employee_id = o.employee_id
+		 WHERE o.id = $1 AND ` + openLeaveCondition + `
+		 ON CONFLICT DO NOTHING`,
+
+		`UPDATE tbl_offboarding_item i
+		 SET status = 'DONE', note = 'Equipment returned', completed_at = ea.returned_at
+		 FROM tbl_equipment_assignment ea
+		 WHERE i.offboarding_id = $1 AND i.item_type = 'equipment_return' AND i.status = 'PENDING'
+		   AND ea.id = i.reference_id AND ea.returned_at IS NOT NULL`,
+
+		`UPDATE tbl_offboarding_item i
+		 SET status = 'DONE', note = 'Leave ' || l.status, completed_at = NOW()
+		 FROM Tbl_Leave l, tbl_employee_offboarding o
+		 WHERE i.offboarding_id = $1 AND o.id = i.offboarding_id
+		   AND i.item_type = 'leave_cancellation' AND i.status = 'PENDING'
+		   AND l.id = i.reference_id AND NOT ` + openLeaveCondition,
+
+		`UPDATE tbl_employee_offboarding o
+		 SET status = CASE WHEN p.pending THEN 'OPEN' ELSE 'CLEARED' END,
+		     cleared_at = CASE WHEN p.pending THEN NULL ELSE COALESCE(o.cleared_at, NOW()) END,
+		     updated_at = NOW()
+		 FROM (SELECT EXISTS (SELECT 1 FROM tbl_offboarding_item
+		                      WHERE offboarding_id = $1 AND status = 'PENDING') AS pending) p
+		 WHERE o.id = $1 AND o.status <> 'CANCELLED'
+		   AND o.status <> CASE WHEN p.pending THEN 'OPEN' ELSE 'CLEARED' END`,
+	}
+	for _, q := range queries {
+		if _, err := tx.Exec(q, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) GetOffboardingItems(tx *sqlx.Tx, id uuid.UUID) ([]models.OffboardingItem, error) {
+	items := []models.OffboardingItem{}
+	err := tx.Select(&items, `
+		SELECT * FROM tbl_offboarding_item
+		WHERE offboarding_id = $1
+		ORDER BY CASE item_type
+			WHEN 'equipment_return' THEN 1 WHEN 'leave_cancellation' THEN 2
+			WHEN 'access_revocation' THEN 3 ELSE 4 END,
+			created_at, title
+	`, id)
+	return items, err
+}
+
+// GetOffboardingItem returns sql.ErrNoRows unless the item belongs to the exit
+func (r *Repository) GetOffboardingItem(tx *sqlx.Tx, offboardingID, itemID uuid.UUID) (models.OffboardingItem, error) {
+	var item models.OffboardingItem
+	err := tx.Get(&item, `
+		SELECT * FROM tbl_offboarding_item
+		WHERE id = $1 AND offboarding_id = $2
+		FOR UPDATE
+	`, itemID, offboardingID)
+	return item, err
+}
+
+// UpdateOffboardingItem sets the status; PENDING clears the completion
+func (r *Repository) UpdateOffboardingItem(tx *sqlx.Tx, itemID uuid.UUID, status, note string, by uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE tbl_offboarding_item
+		SET status = $2, note = $3,
+		    completed_by = CASE WHEN $2 = 'PENDING' THEN NULL ELSE $4::uuid END,
+		    completed_at = CASE WHEN $2 = 'PENDING' THEN NULL ELSE NOW() END
+		WHERE id = $1
+	`, itemID, status, note, by)
+	return err
+}
+
+// ListOffboardings returns exits, soonest ending date first, optionally of one status
+func (r *Repository) ListOffboardings(status string) ([]models.Offboarding, error) {
+	list := []models.Offboarding{}
+	err := r.DB.Select(&list, offboardingSelect+`
+		WHERE ($1 = '' OR o.status = $1)
+		ORDER BY o.ending_date, e.full_name
+	`, status)
+	return list, err
+}
+
+// GetOffboardingsEndingIn returns exits whose ending date falls in the month,
+// i.e. whose final payroll is that month's
+func (r *Repository) GetOffboardingsEndingIn(tx *sqlx.Tx, month, year int) ([]models.Offboarding, error) {
+	list := []models.Offboarding{}
+	err := tx.Select(&list, offboardingSelect+`
+		WHERE o.status <> 'CANCELLED'
+		  AND o.ending_date >= MAKE_DATE($2, $1, 1)
+		  AND o.ending_date < MAKE_DATE($2, $1, 1) + INTERVAL '1 month'
+		ORDER BY e.full_name
+	`, month, year)
+	return list, err
+}
+
+// GetDueOffboardings returns exits whose ending date has passed and whose
+// employee has not been deactivated yet
+func (r *Repository) GetDueOffboardings() ([]models.Offboarding, error) {
+	list := []models.Offboarding{}
+	err := r.DB.Select(&list, offboardingSelect+`
+		WHERE o.status <> 'CANCELLED' AND o.deactivated_at IS NULL
+		  AND o.ending_date < CURRENT_DATE
+		ORDER BY o.ending_date
+	`)
+	return list, err
+}
+
+// DeactivateOffboardedEmployee deactivates the employee, revokes their
+// sessions, refresh tokens and API keys, cancels their leave requests that
+// were not approved yet and closes the access revocation item
+func (r *Repository) DeactivateOffboardedEmployee(tx *sqlx.Tx, o models.Offboarding) error {
+	queries := []string{
+		`UPDATE Tbl_Employee
+		 SET status = 'deactive', tokens_revoked_at = NOW(), updated_at = NOW()
+		 WHERE id = $1`,
+		`UPDATE tbl_refresh_token SET revoked_at = NOW() WHERE employee_id = $1 AND revoked_at IS NULL`,
+		`UPDATE tbl_session SET revoked_at = NOW() WHERE employee_id = $1 AND revoked_at IS NULL`,
+		`UPDATE tbl_api_key SET revoked_at = NOW() WHERE created_by = $1 AND revoked_at IS NULL`,
+		`UPDATE Tbl_Leave SET status = 'CANCELLED', updated_at = NOW()
+		 WHERE employee_id = $1 AND status IN ('Pending', 'MANAGER_APPROVED')`,
+	}
+	for _, q := range queries {
+		if _, err := tx.Exec(q, o.EmployeeID); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(`
+		WITH deactivated AS (
+			UPDATE tbl_employee_offboarding SET deactivated_at = NOW(), updated_at = NOW()
+			WHERE id = $1
+		)
+		UPDATE tbl_offboarding_item
+		SET status = 'DONE', note = 'Deactivated after the ending date', completed_at = NOW()
+		WHERE offboarding_id = $1 AND item_type = 'access_revocation' AND status = 'PENDING'
+	`, o.ID)
+	return err
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import "time"
+
+// ------------------ OIDC LOGIN STATE ------------------
+// CreateOIDCLogin stores the PKCE verifier and nonce of a login that was
+// sent to the identity provider
+func (r *Repository) CreateOIDCLogin(stateHash, verifier, nonce string, ttl time.Duration) error {
+	// Drop logins that were never completed
+	_, _ = r.DB.Exec(`DELETE FROM tbl_oidc_login WHERE expires_at < NOW()`)
+
+	_, err := r.DB.Exec(`
+		INSERT INTO tbl_oidc_login (state_hash, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+	`, stateHash, verifier, nonce, ttl.Seconds())
+	return err
+}
+
+// ConsumeOIDCLogin returns the verifier and nonce for a state and deletes
+// it, so every state can be used once
+func (r *Repository) ConsumeOIDCLogin(stateHash string) (verifier, nonce string, err error) {
+	err = r.DB.QueryRow(`
+		DELETE FROM tbl_oidc_login
+		WHERE state_hash = $1 AND expires_at > NOW()
+		RETURNING code_verifier, nonce
+	`, stateHash).Scan(&verifier, &nonce)
+	return verifier, nonce, err
+}
+
+// GetEmployeeBySSOEmail is GetEmployeeByEmail ignoring case, since identity
+// providers do not keep the case an address was typed in with
+func (r *Repository) GetEmployeeBySSOEmail(email string) (EmployeeAuthData, error) {
+	var emp EmployeeAuthData
+	err := r.DB.Get(&emp, `
+		SELECT e.id, e.email, e.password, r.type AS role, e.status
+		FROM Tbl_Employee e
+		JOIN Tbl_Role r ON e.role_id = r.id
+		WHERE LOWER(e.email) = LOWER($1)
+		LIMIT 1
+	`, email)
+	return emp, err
+}
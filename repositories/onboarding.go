@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ ONBOARDING ------------------
+
+const onboardingTaskSelect = `
+	SELECT t.*, e.full_name AS employee_name, e.manager_id,
+		GREATEST(CURRENT_DATE - t.due_date, 0) AS days_overdue
+	FROM tbl_onboarding_task t
+	JOIN Tbl_Employee e ON e.id = t.employee_id
+`
+
+// ListOnboardingTemplates returns every template with its tasks
+func (r *Repository) ListOnboardingTemplates() ([]models.OnboardingTemplate, error) {
+	templates := []models.OnboardingTemplate{}
+	if err := r.DB.Select(&templates, `
+		SELECT * FROM tbl_onboarding_template ORDER BY name
+	`); err != nil {
+		return nil, err
+	}
+
+	tasks := []models.OnboardingTemplateTask{}
+	if err := r.DB.Select(&tasks, `
+		SELECT * FROM tbl_onboarding_template_task ORDER BY position
+	`); err != nil {
+		return nil, err
+	}
+
+	byTemplate := map[uuid.UUID][]models.OnboardingTemplateTask{}
+	for _, task := range tasks {
+		byTemplate[task.TemplateID] = append(byTemplate[task.TemplateID], task)
+	}
+	for i := range templates {
+		templates[i].Tasks = byTemplate[templates[i].ID]
+		if templates[i].Tasks == nil {
+			templates[i].Tasks = []models.OnboardingTemplateTask{}
+		}
+	}
+	return templates, nil
+}
+
+// GetOnboardingTemplate returns the template with its tasks, or sql.ErrNoRows
+func (r *Repository) GetOnboardingTemplate(id uuid.UUID) (models.OnboardingTemplate, error) {
+	var t models.OnboardingTemplate
+	if err := r.DB.Get(&t, `SELECT * FROM tbl_onboarding_template WHERE id = $1`, id); err != nil {
+		return t, err
+	}
+	t.Tasks = []models.OnboardingTemplateTask{}
+	err := r.DB.Select(&t.Tasks, `
+		SELECT * FROM tbl_onboarding_template_task WHERE template_id = $1 ORDER BY position
+	`, id)
+	return t, err
+}
+
+// OnboardingTemplateNameExists checks the name against every template other than excludeID
+func (r *Repository) OnboardingTemplateNameExists(name string, excludeID *uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.DB.Get(&exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM tbl_onboarding_template
+			WHERE LOWER(name) = LOWER($1) AND ($2::uuid IS NULL OR id <> $2)
+		)
+	`, name, excludeID)
+	return exists, err
+}
+
+func (r *Repository) CreateOnboardingTemplate(tx *sqlx.Tx, input models.OnboardingTemplateInput, isActive bool, createdBy uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO tbl_onboarding_template (name, description, role, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, input.Name, input.Description, input.Role, isActive, createdBy).Scan(&id)
+	return id, err
+}
+
+// UpdateOnboardingTemplate returns false when the template does not exist
+func (r *Repository) UpdateOnboardingTemplate(tx *sqlx.Tx, id uuid.UUID, input models.OnboardingTemplateInput, isActive bool) (bool, error) {
+	res, err := tx.Exec(`
+		UPDATE tbl_onboarding_template
+		SET name = $2, description = $3, role = $4, is_active = $5, updated_at = NOW()
+		WHERE id = $1
+	`, id, input.Name, input.Description, input.Role, isActive)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ReplaceOnboardingTemplateTasks swaps the template's tasks for the given ones,
+// kept in the given order. Tasks already handed out are not affected.
+func (r *Repository) ReplaceOnboardingTemplateTasks(tx *sqlx.Tx, templateID uuid.UUID, tasks []models.OnboardingTemplateTaskInput) error {
+	if _, err := tx.Exec(`DELETE FROM tbl_onboarding_template_task WHERE template_id = $1`, templateID); err != nil {
+		return err
+	}
+	for i, task := range tasks {
+		_, err := tx.Exec(`
+			INSERT INTO tbl_onboarding_template_task (template_id, title, description, owner_role, due_offset_days, position)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, templateID, task.Title, task.Description, task.OwnerRole, task.DueOffsetDays, i+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteOnboardingTemplate returns false when the template does not exist
+func (r *Repository) DeleteOnboardingTemplate(tx *sqlx.Tx, id uuid.UUID) (bool, error) {
+	res, err := tx.Exec(`DELETE FROM tbl_onboarding_template WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// InstantiateOnboarding copies template tasks to the employee, due their
+// offset after the joining date (today when it is not set). With a nil
+// templateID every active template for the employee's role is used. Returns
+// the number of tasks created.
+func (r *Repository) InstantiateOnboarding(tx *sqlx.Tx, empID uuid.UUID, templateID *uuid.UUID) (int64, error) {
+	res, err := tx.Exec(`
+		INSERT INTO tbl_onboarding_task
+			(employee_id, template_id, template_task_id, title, description, owner_role, due_offset_days, due_date, position)
+		SELECT e.id, tpl.id, tt.id, tt.title, tt.description, tt.owner_role, tt.due_offset_days,
+			COALESCE(e.joining_date, CURRENT_DATE) + tt.due_offset_days, tt.position
+		FROM Tbl_Employee e
+		JOIN Tbl_Role r ON r.id = e.role_id
+		JOIN tbl_onboarding_template tpl ON CASE
+			WHEN $2::uuid IS NULL THEN tpl.is_active AND (tpl.role IS NULL OR tpl.role = r.type)
+			ELSE tpl.id = $2
+		END
+		JOIN tbl_onboarding_template_task tt ON tt.template_id = tpl.id
+		WHERE e.id = $1
+		ON CONFLICT DO NOTHING
+	`, empID, templateID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// HasOnboardingTemplate reports whether the employee already has tasks from the template
+func (r *Repository) HasOnboardingTemplate(tx *sqlx.Tx, empID, templateID uuid.UUID) (bool, error) {
+	var exists bool
+	err := tx.Get(&exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM tbl_onboarding_task WHERE employee_id = $1 AND template_id = $2
+		)
+	`, empID, templateID)
+	return exists, err
+}
+
+// RescheduleOnboardingTasks moves the due date of pending tasks after the
+// joining date changed
+func (r *Repository) RescheduleOnboardingTasks(tx *sqlx.Tx, empID uuid.UUID, joiningDate time.Time) error {
+	_, err := tx.Exec(`
+		UPDATE tbl_onboarding_task
+		SET due_date = $2::date + due_offset_days
+		WHERE employee_id = $1 AND status = 'PENDING'
+	`, empID, joiningDate)
+	return err
+}
+
+func (r *Repository) GetOnboardingTasks(empID uuid.UUID) ([]models.OnboardingTask, error) {
+	tasks := []models.OnboardingTask{}
+	err := r.DB.Select(&tasks, onboardingTaskSelect+`
+		WHERE t.employee_id = $1
+		ORDER BY t.due_date, t.position, t.title
+	`, empID)
+	return tasks, err
+}
+
+// GetOnboardingTask returns sql.ErrNoRows unless the task belongs to the
+// employee, and locks it for the transaction
+func (r *Repository) GetOnboardingTask(tx *sqlx.Tx, empID, taskID uuid.UUID) (models.OnboardingTask, error) {
+	var task models.OnboardingTask
+	err := tx.Get(&task, onboardingTaskSelect+`
+		WHERE t.id = $1 AND t.employee_id = $2
+		FOR UPDATE OF t
+	`, taskID, empID)
+	return task, err
+}
+
+// UpdateOnboardingTask sets the status; PENDING clears the completion
+func (r *Repository) UpdateOnboardingTask(tx *sqlx.Tx, taskID uuid.UUID, status, note string, by uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE tbl_onboarding_task
+		SET status = $2, note = $3,
+		    completed_by = CASE WHEN $2 = 'PENDING' THEN NULL ELSE $4::uuid END,
+		    completed_at = CASE WHEN $2 = 'PENDING' THEN NULL ELSE NOW() END
+		WHERE id = $1
+	`, taskID, status, note, by)
+	return err
+}
+
+// GetOverdueOnboardingTasks returns pending tasks of active employees that are
+// past their due date, most overdue first. Unless all is set, only the tasks
+// userID has to complete are returned: their own EMPLOYEE tasks and the
+// MANAGER tasks of their direct reports. ownerRole and empID narrow the list
+// when set.
+func (r *Repository) GetOverdueOnboardingTasks(all bool, userID uuid.UUID, ownerRole string, empID *uuid.UUID) ([]models.OnboardingTask, error) {
+	tasks := []models.OnboardingTask{}
+	err := r.DB.Select(&tasks, onboardingTaskSelect+`
+		WHERE t.status = 'PENDING' AND t.due_date < CURRENT_DATE AND e.status = 'active'
+		  AND ($1::boolean
+		       OR (t.owner_role = 'EMPLOYEE' AND t.employee_id = $2)
+		       OR (t.owner_role = 'MANAGER' AND e.manager_id = $2))
+		  AND ($3 = '' OR t.owner_role = $3)
+		  AND ($4::uuid IS NULL OR t.employee_id = $4)
+		ORDER BY t.due_date, e.full_name, t.position
+	`, all, userID, ownerRole, empID)
+	return tasks, err
+}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ ORG CHART ------------------
+
+// GetOrgTree returns the employee (level 0) followed by their direct and
+// indirect reports down to maxDepth levels, ordered by level and name.
+// Deactivated reports and everyone below them are skipped unless
+// includeInactive is set. The result is empty when the employee does not exist.
+func (r *Repository) GetOrgTree(rootID uuid.UUID, maxDepth int, includeInactive bool) ([]models.OrgChartNode, error) {
+	nodes := []models.OrgChartNode{}
+	err := r.DB.Select(&nodes, `
+		WITH RECURSIVE tree AS (
+			SELECT e.id, 0 AS level, ARRAY[e.id] AS path
+			FROM Tbl_Employee e
+			WHERE e.id = $1
+			UNION ALL
+			SELECT e.id, t.level + 1, t.path || e.id
+			FROM tree t
+			JOIN Tbl_Employee e ON e.manager_id = t.id
+			WHERE t.level < $2
+			  AND NOT e.id = ANY(t.path) -- stop at a manager cycle
+			  AND ($3::boolean OR e.status = 'active')
+		)
+		SELECT
+			e.id, e.full_name, e.email, r.type AS role, e.status,
+			d.designation_name, e.manager_id, t.level,
+			(SELECT COUNT(*) FROM Tbl_Employee c
+			 WHERE c.manager_id = e.id AND ($3::boolean OR c.status = 'active')) AS direct_report_count
+		FROM tree t
+		JOIN Tbl_Employee e ON e.id = t.id
+		JOIN Tbl_Role r ON e.role_id = r.id
+		LEFT JOIN Tbl_Designation d ON e.designation_id = d.id
+		ORDER BY t.level, e.full_name
+	`, rootID, maxDepth, includeInactive)
+	return nodes, err
+}
+
+// GetChainOfCommand returns the employee's managers from the direct manager
+// (level 1) up to the top of the hierarchy
+func (r *Repository) GetChainOfCommand(empID uuid.UUID) ([]models.OrgChartNode, error) {
+	chain := []models.OrgChartNode{}
+	err := r.DB.Select(&chain, `
+		WITH RECURSIVE chain AS (
+			SELECT e.manager_id AS id, 1 AS level, ARRAY[e.id, e.manager_id] AS path
+			FROM Tbl_Employee e
+			WHERE e.id = $1 AND e.manager_id IS NOT NULL
+			UNION ALL
+			SELECT e.manager_id, c.level + 1, c.path || e.manager_id
+			FROM chain c
+			JOIN Tbl_Employee e ON e.id = c.id
+			WHERE e.manager_id IS NOT NULL
+			  AND NOT e.manager_id = ANY(c.path) -- stop at a manager cycle
+		)
+		SELECT
+			e.id, e.full_name, e.email, r.type AS role, e.status,
+			d.designation_name, e.manager_id, c.level,
+			(SELECT COUNT(*) FROM Tbl_Employee s
+			 WHERE s.manager_id = e.id AND s.status = 'active') AS direct_report_count
+		FROM chain c
+		JOIN Tbl_Employee e ON e.id = c.id
+		JOIN Tbl_Role r ON e.role_id = r.id
+		LEFT JOIN Tbl_Designation d ON e.designation_id = d.id
+		ORDER BY c.level
+	`, empID)
+	return chain, err
+}
+
+// LockReportingLines serialises manager changes until the transaction ends,
+// so two concurrent assignments cannot close a cycle between them
+func (r *Repository) LockReportingLines(tx *sqlx.Tx) error {
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('tbl_employee.manager_id'))`)
+	return err
+}
+
+// GetManagerStatus returns the role and status of the employee and locks the
+// row against a concurrent demotion or deactivation until the transaction ends
+func (r *Repository) GetManagerStatus(tx *sqlx.Tx, managerID uuid.UUID) (role, status string, err error) {
+	err = tx.QueryRow(`
+		SELECT r.type, e.status
+		FROM Tbl_Employee e
+		JOIN Tbl_Role r ON e.role_id = r.id
+		WHERE e.id = $1
+		FOR SHARE OF e
+	`, managerID).Scan(&role, &status)
+	return role, status, err
+}
+
+// GetReplacementManager returns the nearest active MANAGER above the employee,
+// who takes over their direct reports when they leave, or nil if there is
+// none. Their row is locked like GetManagerStatus does.
+func (r *Repository) GetReplacementManager(tx *sqlx.Tx, empID uuid.UUID) (*uuid.UUID, error) {
+	var managerID uuid.UUID
+	err := tx.Get(&managerID, `
+		WITH RECURSIVE chain AS (
+			SELECT e.manager_id AS id, 1 AS level, ARRAY[e.id, e.manager_id] AS path
+			FROM Tbl_Employee e
+			WHERE e.id = $1 AND e.manager_id IS NOT NULL
+			UNION ALL
+			SELECT e.manager_id, c.level + 1, c.path || e.manager_id
+			FROM chain c
+			JOIN Tbl_Employee e ON e.id = c.id
+			WHERE e.manager_id IS NOT NULL
+			  AND NOT e.manager_id = ANY(c.path) -- stop at a manager cycle
+		)
+		SELECT e.id
+		FROM chain c
+		JOIN Tbl_Employee e ON e.id = c.id
+		JOIN Tbl_Role r ON e.role_id = r.id
+		WHERE e.status = 'active' AND r.type = 'MANAGER'
+		ORDER BY c.level
+		LIMIT 1
+		FOR SHARE OF e
+	`, empID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &managerID, nil
+}
+
+// IsInChainOfCommand reports whether managerID is one of empID's direct or
+// indirect managers
+func (r *Repository) IsInChainOfCommand(tx *sqlx.Tx, managerID, empID uuid.UUID) (bool, error) {
+	var found bool
+	err := tx.Get(&found, `
+		WITH RECURSIVE chain AS (
+			SELECT e.manager_id AS id, ARRAY[e.id] AS path
+			FROM Tbl_Employee e
+			WHERE e.id = $2 AND e.manager_id IS NOT NULL
+			UNION ALL
+			SELECT e.manager_id, c.path || c.id
+			FROM chain c
+			JOIN Tbl_Employee e ON e.id = c.id
+			WHERE e.manager_id IS NOT NULL
+			  AND NOT c.id = ANY(c.path) -- stop at an existing cycle
+		)
+		SELECT EXISTS (SELECT 1 FROM chain WHERE id = $1)
+	`, managerID, empID)
+	return found, err
+}
+
+// ReassignDirectReports moves every direct report of fromID to toID (nil
+// leaves them without a manager) and returns the moved employees
+func (r *Repository) ReassignDirectReports(tx *sqlx.Tx, fromID uuid.UUID, toID *uuid.UUID) ([]uuid.UUID, error) {
+	moved := []uuid.UUID{}
+	err := tx.Select(&moved, `
+		UPDATE Tbl_Employee
+		SET manager_id = $2, updated_at = NOW()
+		WHERE manager_id = $1
+		RETURNING id
+	`, fromID, toID)
+	return moved, err
+}
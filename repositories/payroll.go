@@ -12,6 +12,9 @@ type EmpMonthlyData struct {
 	Salary      *float64  `db:"salary" json:"salary"`
 	Status      string    `db:"status" json:"status"`
 	JoiningDate time.Time `db:"joining_date" json:"joining_date"`
+	// Employment type and whether absent days are deducted for it
+	EmploymentType string `db:"employment_type" json:"employment_type"`
+	DeductAbsences bool   `db:"deduct_absences" json:"deduct_absences"`
 }
 
 type ExistingRun struct {
@@ -57,9 +60,15 @@ func (r *Repository) GetEmployeeByMonthAndYear(input struct {
 	var employees []EmpMonthlyData
 
 	query := `
-		SELECT id, full_name, salary, status, joining_date
-		FROM tbl_employee
-		WHERE status = 'active'
+		SELECT e.id, e.full_name, e.salary, e.status, e.joining_date,
+		       e.employment_type, t.deduct_absences
+		FROM tbl_employee e
+		JOIN tbl_employment_type t ON t.code = e.employment_type
+		-- Employment types paid outside payroll are skipped
+		WHERE t.include_in_payroll
+		-- Employees who left during the month still get their final payroll
+		AND (status = 'active' OR ending_date >= MAKE_DATE($1::int, $2::int, 1))
+		AND (ending_date IS NULL OR ending_date >= MAKE_DATE($1::int, $2::int, 1))
 		AND (
 			EXTRACT(YEAR FROM joining_date) < $1
 			OR (EXTRACT(YEAR FROM joining_date) = $1 
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ GET ROLE PERMISSION CODES ------------------
+func (r *Repository) GetRolePermissionCodes(role string) ([]string, error) {
+	codes := []string{}
+	err := r.DB.Select(&codes, `
+		SELECT p.code
+		FROM tbl_role_permission rp
+		JOIN Tbl_Role r ON r.id = rp.role_id
+		JOIN tbl_permission p ON p.id = rp.permission_id
+		WHERE r.type = $1
+	`, role)
+	return codes, err
+}
+
+// ------------------ GET ALL PERMISSIONS ------------------
+func (r *Repository) GetAllPermissions() ([]models.Permission, error) {
+	permissions := []models.Permission{}
+	err := r.DB.Select(&permissions, `SELECT id, code, description FROM tbl_permission ORDER BY code`)
+	return permissions, err
+}
+
+// ------------------ GET ALL ROLE GRANTS ------------------
+func (r *Repository) GetAllRolePermissions() ([]models.RolePermission, error) {
+	grants := []models.RolePermission{}
+	err := r.DB.Select(&grants, `
+		SELECT r.type AS role, p.code AS permission
+		FROM tbl_role_permission rp
+		JOIN Tbl_Role r ON r.id = rp.role_id
+		JOIN tbl_permission p ON p.id = rp.permission_id
+		ORDER BY r.type, p.code
+	`)
+	return grants, err
+}
+
+// ------------------ CHECK PERMISSION EXISTS ------------------
+func (r *Repository) PermissionExists(code string) (bool, error) {
+	var exists bool
+	err := r.DB.Get(&exists, `SELECT EXISTS(SELECT 1 FROM tbl_permission WHERE code = $1)`, code)
+	return exists, err
+}
+
+// ------------------ GRANT PERMISSION TO ROLE ------------------
+// Returns false if the role already had the permission
+func (r *Repository) GrantRolePermission(tx *sqlx.Tx, role, code string) (bool, error) {
+	result, err := tx.Exec(`
+		INSERT INTO tbl_role_permission (role_id, permission_id)
+		SELECT r.id, p.id
+		FROM Tbl_Role r, tbl_permission p
+		WHERE r.type = $1 AND p.code = $2
+		ON CONFLICT DO NOTHING
+	`, role, code)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// ------------------ REVOKE PERMISSION FROM ROLE ------------------
+// Returns false if the role did not have the permission
+func (r *Repository) RevokeRolePermission(tx *sqlx.Tx, role, code string) (bool, error) {
+	result, err := tx.Exec(`
+		DELETE FROM tbl_role_permission rp
+		USING Tbl_Role r, tbl_permission p
+		WHERE rp.role_id = r.id AND rp.permission_id = p.id
+		  AND r.type = $1 AND p.code = $2
+	`, role, code)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
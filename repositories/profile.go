@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ EMPLOYEE PROFILE ------------------
+
+// EmployeeProfileRow is tbl_employee_profile as stored; *_enc columns are
+// encrypted and decrypted by the caller
+type EmployeeProfileRow struct {
+	EmployeeID uuid.UUID `db:"employee_id"`
+
+	Phone         *string `db:"phone"`
+	PersonalEmail *string `db:"personal_email"`
+	AddressLine1  *string `db:"address_line1"`
+	AddressLine2  *string `db:"address_line2"`
+	City          *string `db:"city"`
+	State         *string `db:"state"`
+	PostalCode    *string `db:"postal_code"`
+	Country       *string `db:"country"`
+
+	DateOfBirth *time.Time `db:"date_of_birth"`
+	Gender      *string    `db:"gender"`
+	BloodGroup  *string    `db:"blood_group"`
+
+	BankAccountHolder    *string `db:"bank_account_holder"`
+	BankName             *string `db:"bank_name"`
+	BankIFSC             *string `db:"bank_ifsc"`
+	BankAccountNumberEnc *string `db:"bank_account_number_enc"`
+	PANNumberEnc         *string `db:"pan_number_enc"`
+	AadhaarNumberEnc     *string `db:"aadhaar_number_enc"`
+	PassportNumberEnc    *string `db:"passport_number_enc"`
+
+	UpdatedBy *uuid.UUID `db:"updated_by"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+// GetEmployeeProfile returns sql.ErrNoRows while the profile was never saved
+func (r *Repository) GetEmployeeProfile(empID uuid.UUID) (EmployeeProfileRow, error) {
+	var row EmployeeProfileRow
+	err := r.DB.Get(&row, `SELECT * FROM tbl_employee_profile WHERE employee_id = $1`, empID)
+	return row, err
+}
+
+// UpsertEmployeeProfile sets the given columns (nil clears one), creating the
+// profile row if needed. Column names must come from code, never from input.
+func (r *Repository) UpsertEmployeeProfile(tx *sqlx.Tx, empID, updatedBy uuid.UUID, columns map[string]any) error {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cols := []string{"employee_id", "updated_by"}
+	args := []any{empID, updatedBy}
+	updates := []string{"updated_by = EXCLUDED.updated_by", "updated_at = NOW()"}
+	for _, name := range names {
+		cols = append(cols, name)
+		args = append(args, columns[name])
+		updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", name, name))
+	}
+
+	placeholders := make([]string, len(cols))
+	for i := range cols {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO tbl_employee_profile (%s)
+		VALUES (%s)
+		ON CONFLICT (employee_id) DO UPDATE SET %s
+	`, strings.Join(cols, ", "), strings.Join(placeholders, ", "), strings.Join(updates, ", "))
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// ------------------ EMERGENCY CONTACTS ------------------
+
+func (r *Repository) GetEmergencyContacts(empID uuid.UUID) ([]models.EmergencyContact, error) {
+	contacts := []models.EmergencyContact{}
+	err := r.DB.Select(&contacts, `
+		SELECT id, name, relationship, phone, email
+		FROM tbl_emergency_contact
+		WHERE employee_id = $1
+		ORDER BY position, created_at
+	`, empID)
+	return contacts, err
+}
+
+// ReplaceEmergencyContacts swaps the employee's contacts for the given list
+func (r *Repository) ReplaceEmergencyContacts(tx *sqlx.Tx, empID uuid.UUID, contacts []models.EmergencyContactInput) error {
+	if _, err := tx.Exec(`DELETE FROM tbl_emergency_contact WHERE employee_id = $1`, empID); err != nil {
+		return err
+	}
+	for i, contact := range contacts {
+		_, err := tx.Exec(`
+			INSERT INTO tbl_emergency_contact (employee_id, name, relationship, phone, email, position)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, empID, contact.Name, contact.Relationship, contact.Phone, contact.Email, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
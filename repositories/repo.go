@@ -3,7 +3,10 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -47,98 +50,190 @@ func (r *Repository) GetEmployeeByEmail(email string) (EmployeeAuthData, error)
 	return emp, err
 }
 
-func (r *Repository) GetAllEmployees(roleFilter, designationFilter string) ([]models.EmployeeInput, error) {
-	// Build dynamic query with optional filters
-	query := `
-        SELECT 
-            e.id, e.full_name, e.email, e.status,
-            r.type AS role, e.password, e.manager_id, e.designation_id,
-            e.salary, e.joining_date, e.ending_date,
-            e.created_at, e.updated_at, e.deleted_at
-        FROM Tbl_Employee e
-        JOIN Tbl_Role r ON e.role_id = r.id
-        LEFT JOIN Tbl_Designation d ON e.designation_id = d.id
-        WHERE 1=1
-    `
+// ------------------ LIST EMPLOYEES ------------------
+
+// employeeSortColumns maps the sort fields of the employee list to a non-null
+// SQL expression and the type its cursor value is cast back to
+var employeeSortColumns = map[string]struct{ expr, cast string }{
+	"name":         {"e.full_name", "text"},
+	"email":        {"e.email", "text"},
+	"role":         {"r.type", "text"},
+	"status":       {"e.status", "text"},
+	"joining_date": {"COALESCE(e.joining_date, DATE '0001-01-01')", "date"},
+	"created_at":   {"COALESCE(e.created_at, TIMESTAMP 'epoch')", "timestamp"},
+}
+
+// IsEmployeeSortField reports whether the employee list can be sorted by
+// field (with or without the "-" prefix)
+func IsEmployeeSortField(field string) bool {
+	_, ok := employeeSortColumns[strings.TrimPrefix(field, "-")]
+	return ok
+}
 
+// ValidEmployeeSortValue reports whether a cursor value of the sort field can
+// be cast back to its SQL type. Values are PostgreSQL's ::text of the sort
+// expression, so anything else was not issued by ListEmployees.
+func ValidEmployeeSortValue(field, value string) bool {
+	if !utf8.ValidString(value) || strings.ContainsRune(value, 0) {
+		return false
+	}
+	var err error
+	switch employeeSortColumns[strings.TrimPrefix(field, "-")].cast {
+	case "date":
+		_, err = time.Parse("2006-01-02", value)
+	case "timestamp":
+		_, err = time.Parse("2006-01-02 15:04:05.999999", value)
+	}
+	return err == nil
+}
+
+// EmployeePage is one page of the employee list
+type EmployeePage struct {
+	Employees []models.EmployeeInput
+	Total     int  // rows matching the filter, across all pages
+	HasMore   bool // another page follows
+	// LastSortValue is the sort value of the last row, for the next cursor
+	LastSortValue string
+}
+
+// ListEmployees returns employees with their manager, designation and department names
+// in one query, filtered, sorted and paginated. The employee ID breaks ties
+// so the order is stable across pages.
+func (r *Repository) ListEmployees(f models.EmployeeListFilter) (EmployeePage, error) {
+	var page EmployeePage
+
+	sortField := strings.TrimPrefix(f.Sort, "-")
+	if sortField == "" {
+		sortField = "name"
+	}
+	sort, ok := employeeSortColumns[sortField]
+	if !ok {
+		return page, fmt.Errorf("invalid sort field %q", f.Sort)
+	}
+	desc := strings.HasPrefix(f.Sort, "-")
+
+	// Filters shared by the page and the total count
+	where := []string{"1=1"}
 	args := []interface{}{}
-	argCount := 1
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if f.Role != "" {
+		where = append(where, "r.type = "+arg(f.Role))
+	}
+	if f.Designation != "" {
+		where = append(where, "d.designation_name = "+arg(f.Designation))
+	}
+	if f.Status != "" {
+		where = append(where, "e.status = "+arg(f.Status))
+	}
+	if f.DepartmentID != nil {
+		where = append(where, "e.department_id IN ("+departmentSubtree(arg(*f.DepartmentID))+")")
+	}
+	if f.EmploymentType != "" {
+		where = append(where, "e.employment_type = "+arg(f.EmploymentType))
+	}
+	// Every word has to start a word of the name or the email
+	if query := employeeSearchQuery(f.Search); query != "" {
+		where = append(where, employeeSearchDocument+" @@ to_tsquery('simple', "+arg(query)+")")
+	}
 
-	// Add role filter if provided
-	if roleFilter != "" {
-		query += fmt.Sprintf(" AND r.type = $%d", argCount)
-		args = append(args, roleFilter)
-		argCount++
+	from := `
+		FROM Tbl_Employee e
+		JOIN Tbl_Role r ON e.role_id = r.id
+		LEFT JOIN Tbl_Employee m ON e.manager_id = m.id
+		LEFT JOIN Tbl_Designation d ON e.designation_id = d.id
+		LEFT JOIN tbl_department dep ON e.department_id = dep.id
+		WHERE ` + strings.Join(where, " AND ")
+
+	if err := r.DB.Get(&page.Total, "SELECT COUNT(*) "+from, args...); err != nil {
+		return page, err
 	}
 
-	// Add designation filter if provided
-	if designationFilter != "" {
-		query += fmt.Sprintf(" AND d.designation_name = $%d", argCount)
-		args = append(args, designationFilter)
-		argCount++
+	// Keyset condition goes after the count
+	query := `
+		SELECT
+			e.id, e.full_name, e.email, e.status, r.type AS role,
+			e.manager_id, e.designation_id, e.salary, e.joining_date, e.ending_date,
+			e.created_at, e.updated_at, e.deleted_at,
+			m.full_name AS manager_name, d.designation_name,
+			e.department_id, dep.name AS department_name,
+			e.employment_type, e.probation_end_date, e.probation_status,
+			(` + sort.expr + `)::text AS sort_value
+	` + from
+
+	cmp, dir := ">", "ASC"
+	if desc {
+		cmp, dir = "<", "DESC"
+	}
+	if f.AfterValue != nil && f.AfterID != nil {
+		query += fmt.Sprintf(" AND (%s, e.id) %s (%s::%s, %s)",
+			sort.expr, cmp, arg(*f.AfterValue), sort.cast, arg(*f.AfterID))
 	}
+	query += fmt.Sprintf(" ORDER BY %s %s, e.id %s", sort.expr, dir, dir)
 
-	query += " ORDER BY e.full_name"
+	// One extra row tells whether another page follows
+	if f.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", f.Limit+1, f.Offset)
+	}
 
 	rows, err := r.DB.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return page, err
 	}
 	defer rows.Close()
 
-	var employees []models.EmployeeInput
-
+	page.Employees = []models.EmployeeInput{}
+	var sortValues []string
 	for rows.Next() {
 		var emp models.EmployeeInput
-
+		var sortValue string
 		err := rows.Scan(
-			&emp.ID,
-			&emp.FullName,
-			&emp.Email,
-			&emp.Status,
-			&emp.Role,
-			&emp.Password,
-			&emp.ManagerID,
-			&emp.DesignationID,
-			&emp.Salary,
-			&emp.JoiningDate,
-			&emp.EndingDate,
-			&emp.CreatedAt,
-			&emp.UpdatedAt,
-			&emp.DeletedAt,
+			&emp.ID, &emp.FullName, &emp.Email, &emp.Status, &emp.Role,
+			&emp.ManagerID, &emp.DesignationID, &emp.Salary, &emp.JoiningDate, &emp.EndingDate,
+			&emp.CreatedAt, &emp.UpdatedAt, &emp.DeletedAt,
+			&emp.ManagerName, &emp.DesignationName,
+			&emp.DepartmentID, &emp.DepartmentName,
+			&emp.EmploymentType, &emp.ProbationEndDate, &emp.ProbationStatus,
+			&sortValue,
 		)
 		if err != nil {
-			return nil, err
-		}
-
-		// ------- Fetch manager name if exists -------
-		if emp.ManagerID != nil {
-			var mName string
-			err := r.DB.QueryRow(`
-                SELECT full_name FROM Tbl_Employee WHERE id = $1
-            `, emp.ManagerID).Scan(&mName)
-
-			if err == nil {
-				emp.ManagerName = &mName
-			}
-		}
-
-		// ------- Fetch designation name if exists -------
-		if emp.DesignationID != nil {
-			var dName string
-			err := r.DB.QueryRow(`
-                SELECT designation_name FROM Tbl_Designation WHERE id = $1
-            `, emp.DesignationID).Scan(&dName)
-
-			if err == nil {
-				emp.DesignationName = &dName
-			}
+			return page, err
 		}
+		page.Employees = append(page.Employees, emp)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
 
-		employees = append(employees, emp)
+	if f.Limit > 0 && len(page.Employees) > f.Limit {
+		page.Employees = page.Employees[:f.Limit]
+		page.HasMore = true
 	}
+	if n := len(page.Employees); n > 0 {
+		page.LastSortValue = sortValues[n-1]
+	}
+	return page, nil
+}
 
-	return employees, nil
+// employeeSearchDocument is the full-text document of an employee: the words
+// of the name and of the email split at "@" and ".". It must stay identical
+// to the expression of idx_employee_search.
+const employeeSearchDocument = `to_tsvector('simple', COALESCE(e.full_name, '') || ' ' || translate(COALESCE(e.email, ''), '@.', '  '))`
+
+// employeeSearchQuery turns ?q= into a tsquery matching every word as a
+// prefix, e.g. "jo smi" becomes 'jo':* & 'smi':*
+func employeeSearchQuery(search string) string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = "'" + word + "':*"
+	}
+	return strings.Join(terms, " & ")
 }
 
 // ------------------ GET ADMIN AND EMPLOYEE EMAIL ------------------
@@ -154,9 +249,11 @@ func (r *Repository) GetAdminAndEmployeeEmail(id uuid.UUID) ([]string, error) {
 		`, id)
 	if err == nil && managerEmail != "" {
 		recipients = append(recipients, managerEmail)
+	} else if err != nil && err != sql.ErrNoRows {
+		return recipients, err
 	}
 	var adminEmails []string
-	r.DB.Select(&adminEmails, `
+	err = r.DB.Select(&adminEmails, `
 			SELECT e.email 
 			FROM Tbl_Employee e
 			JOIN Tbl_Role r ON e.role_id = r.id
@@ -164,7 +261,8 @@ func (r *Repository) GetAdminAndEmployeeEmail(id uuid.UUID) ([]string, error) {
 		`)
 	recipients = append(recipients, adminEmails...)
 
-	return recipients, nil
+	// Recipients found so far are returned with the error
+	return recipients, err
 }
 
 func (r *Repository) GetEmployeeDetailsForNotification(id uuid.UUID) (empDetails struct {
@@ -176,12 +274,12 @@ func (r *Repository) GetEmployeeDetailsForNotification(id uuid.UUID) (empDetails
 
 }
 
-func (r *Repository) DeleteEmployeeStatus(id uuid.UUID) (string, error) {
+func (r *Repository) DeleteEmployeeStatus(tx *sqlx.Tx, id uuid.UUID) (string, error) {
 
 	// Get current status
 	var currentStatus string
-	err := r.DB.QueryRow(`
-        SELECT status FROM Tbl_Employee WHERE id = $1
+	err := tx.QueryRow(`
+        SELECT status FROM Tbl_Employee WHERE id = $1 FOR UPDATE
     `, id).Scan(&currentStatus)
 	if err != nil {
 		return "", err
@@ -194,7 +292,7 @@ func (r *Repository) DeleteEmployeeStatus(id uuid.UUID) (string, error) {
 	}
 
 	// Update
-	_, err = r.DB.Exec(`
+	_, err = tx.Exec(`
         UPDATE Tbl_Employee 
         SET status = $1, updated_at = NOW()
         WHERE id = $2
@@ -227,12 +325,26 @@ func (r *Repository) GetRoleID(role string) (string, error) {
 }
 
 // ------------------ CREATE EMPLOYEE ------------------
-func (r *Repository) InsertEmployee(fullName, email, roleID, password string, salary *float64, joining *time.Time) error {
-	_, err := r.DB.Exec(`
+func (r *Repository) InsertEmployee(tx *sqlx.Tx, fullName, email, roleID, password string, salary *float64, joining *time.Time) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
 		INSERT INTO Tbl_Employee (full_name, email, role_id, password, salary, joining_date)
 		VALUES ($1, $2, $3, $4, $5, $6)
-	`, fullName, email, roleID, password, salary, joining)
-	return err
+		RETURNING id
+	`, fullName, email, roleID, password, salary, joining).Scan(&id)
+	return id, err
+}
+
+// ------------------ IMPORT EMPLOYEE ------------------
+// InsertImportedEmployee creates an employee inside the import transaction
+func (r *Repository) InsertImportedEmployee(tx *sqlx.Tx, emp models.EmployeeImportRow, password string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO Tbl_Employee (full_name, email, role_id, password, manager_id, designation_id, salary, joining_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, emp.FullName, emp.Email, emp.RoleID, password, emp.ManagerID, emp.DesignationID, emp.Salary, emp.JoiningDate).Scan(&id)
+	return id, err
 }
 
 // ------------------ GET CURRENT ROLE NAME ------------------
@@ -272,8 +384,8 @@ func (r *Repository) ManagerExists(id uuid.UUID) (bool, error) {
 }
 
 // ------------------ UPDATE MANAGER ------------------
-func (r *Repository) UpdateManager(empID, managerID uuid.UUID) error {
-	_, err := r.DB.Exec(`
+func (r *Repository) UpdateManager(tx *sqlx.Tx, empID, managerID uuid.UUID) error {
+	_, err := tx.Exec(`
         UPDATE TBL_EMPLOYEE
         SET MANAGER_ID=$1, UPDATED_AT=NOW()
         WHERE ID=$2
@@ -298,7 +410,9 @@ func (r *Repository) GetEmployeeCurrentRoleAndManagerStatus(empID uuid.UUID) (st
 	return role, count > 0, nil
 }
 
-func (r *Repository) GetAllFinalizedPayslips() (*sql.Rows, error) {
+// GetAllFinalizedPayslips returns every finalized payslip, optionally only of
+// employees in a department or the departments below it
+func (r *Repository) GetAllFinalizedPayslips(departmentID *uuid.UUID) (*sql.Rows, error) {
 	query := `
 	SELECT 
 	    p.id AS payslip_id,
@@ -311,17 +425,21 @@ func (r *Repository) GetAllFinalizedPayslips() (*sql.Rows, error) {
 	    p.working_days,
 	    p.absent_days,
 	    p.deduction_amount,
+	    p.arrears_amount,
 	    p.net_salary,
 	    COALESCE(p.pdf_path, '') AS pdf_path,
-	    CONCAT('₹', p.basic_salary, ' - ₹', p.deduction_amount, ' = ₹', p.net_salary) AS calculation,
+	    CONCAT('₹', p.basic_salary, ' - ₹', p.deduction_amount,
+	           CASE WHEN p.arrears_amount <> 0 THEN CONCAT(' + ₹', p.arrears_amount, ' arrears') ELSE '' END,
+	           ' = ₹', p.net_salary) AS calculation,
 	    p.created_at
 	FROM Tbl_Payslip p
 	JOIN Tbl_Employee e ON p.employee_id = e.id
 	JOIN Tbl_Payroll_Run pr ON pr.id = p.payroll_run_id
 	WHERE pr.status = 'FINALIZED'
+	  AND ($1::uuid IS NULL OR e.department_id IN (` + departmentSubtree("$1") + `))
 	ORDER BY pr.year DESC, pr.month DESC, e.full_name ASC;
 	`
-	return r.DB.Query(query)
+	return r.DB.Query(query, departmentID)
 }
 
 func (r *Repository) GetFinalizedPayslipsByEmployee(id uuid.UUID) (*sql.Rows, error) {
@@ -337,9 +455,12 @@ func (r *Repository) GetFinalizedPayslipsByEmployee(id uuid.UUID) (*sql.Rows, er
 	    p.working_days,
 	    p.absent_days,
 	    p.deduction_amount,
+	    p.arrears_amount,
 	    p.net_salary,
 	    COALESCE(p.pdf_path, '') AS pdf_path,
-	    CONCAT('₹', p.basic_salary, ' - ₹', p.deduction_amount, ' = ₹', p.net_salary) AS calculation,
+	    CONCAT('₹', p.basic_salary, ' - ₹', p.deduction_amount,
+	           CASE WHEN p.arrears_amount <> 0 THEN CONCAT(' + ₹', p.arrears_amount, ' arrears') ELSE '' END,
+	           ' = ₹', p.net_salary) AS calculation,
 	    p.created_at
 	FROM Tbl_Payslip p
 	JOIN Tbl_Employee e ON p.employee_id = e.id
@@ -358,9 +479,12 @@ func (r *Repository) GetEmployeeByID(empID uuid.UUID) (*models.EmployeeInput, er
             e.id, e.full_name, e.email, e.status,
             r.type AS role, e.manager_id, e.designation_id,
             e.joining_date, e.ending_date,
-            e.created_at, e.updated_at, e.deleted_at
+            e.created_at, e.updated_at, e.deleted_at,
+            e.department_id, dep.name,
+            e.employment_type, e.probation_end_date, e.probation_status
         FROM Tbl_Employee e
         JOIN Tbl_Role r ON e.role_id = r.id
+        LEFT JOIN tbl_department dep ON e.department_id = dep.id
         WHERE e.id = $1
     `
 
@@ -377,6 +501,11 @@ func (r *Repository) GetEmployeeByID(empID uuid.UUID) (*models.EmployeeInput, er
 		&emp.CreatedAt,
 		&emp.UpdatedAt,
 		&emp.DeletedAt,
+		&emp.DepartmentID,
+		&emp.DepartmentName,
+		&emp.EmploymentType,
+		&emp.ProbationEndDate,
+		&emp.ProbationStatus,
 	)
 
 	if err != nil {
@@ -411,10 +540,11 @@ func (r *Repository) GetEmployeeByID(empID uuid.UUID) (*models.EmployeeInput, er
 }
 
 // ------------------ UPDATE EMPLOYEE INFO ------------------
-func (r *Repository) UpdateEmployeeInfo(empID uuid.UUID, fullName, email string, salary *float64, joiningDate, endingDate *time.Time) error {
-	_, err := r.DB.Exec(`
+// A nil salary keeps the current one
+func (r *Repository) UpdateEmployeeInfo(tx *sqlx.Tx, empID uuid.UUID, fullName, email string, salary *float64, joiningDate, endingDate *time.Time) error {
+	_, err := tx.Exec(`
         UPDATE Tbl_Employee
-        SET full_name = $1, email = $2, salary = $3, joining_date = $4, ending_date = $5, updated_at = NOW()
+        SET full_name = $1, email = $2, salary = COALESCE($3, salary), joining_date = $4, ending_date = $5, updated_at = NOW()
         WHERE id = $6
     `, fullName, email, salary, joiningDate, endingDate, empID)
 	return err
@@ -503,3 +633,10 @@ func (r *Repository) GetEmployeesByManagerID(managerID uuid.UUID) ([]models.Empl
 
 	return employees, nil
 }
+
+// ------------------ GET EMPLOYEE PASSWORD HASH ------------------
+func (r *Repository) GetEmployeePasswordHash(empID uuid.UUID) (string, error) {
+	var hash string
+	err := r.DB.Get(&hash, `SELECT password FROM Tbl_Employee WHERE id = $1`, empID)
+	return hash, err
+}
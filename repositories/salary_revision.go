@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ SALARY REVISION ------------------
+
+// PaidPayslip is a finalized payslip a backdated revision may owe arrears on
+type PaidPayslip struct {
+	ID              uuid.UUID `db:"id"`
+	Month           int       `db:"month"`
+	Year            int       `db:"year"`
+	BasicSalary     float64   `db:"basic_salary"`
+	WorkingDays     int       `db:"working_days"`
+	AbsentDays      float64   `db:"absent_days"`
+	PaidNet         float64   `db:"paid_net"`         // net salary without the arrears it settled
+	ArrearsRecorded float64   `db:"arrears_recorded"` // arrears already owed on this payslip
+	Deducted        bool      `db:"deducted"`         // absent days were deducted (per employment type)
+}
+
+// LockSalaryHistory serialises revisions and payslips of the employee until
+// the transaction ends, so arrears are computed against what was paid
+func (r *Repository) LockSalaryHistory(tx *sqlx.Tx, empID uuid.UUID) error {
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('tbl_salary_revision'), hashtext($1::text))`, empID)
+	return err
+}
+
+// SaveSalaryRevision records the salary from effectiveFrom, replacing a
+// revision on the same day
+func (r *Repository) SaveSalaryRevision(tx *sqlx.Tx, empID uuid.UUID, salary float64, effectiveFrom time.Time, reason string, approvedBy *uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO tbl_salary_revision (employee_id, salary, effective_from, reason, approved_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (employee_id, effective_from) DO UPDATE
+		SET salary = EXCLUDED.salary, reason = EXCLUDED.reason,
+		    approved_by = EXCLUDED.approved_by, created_at = NOW()
+		RETURNING id
+	`, empID, salary, effectiveFrom, reason, approvedBy).Scan(&id)
+	return id, err
+}
+
+func (r *Repository) SalaryRevisionExists(tx *sqlx.Tx, empID uuid.UUID, effectiveFrom time.Time) (bool, error) {
+	var exists bool
+	err := tx.Get(&exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM tbl_salary_revision WHERE employee_id = $1 AND effective_from = $2
+		)
+	`, empID, effectiveFrom)
+	return exists, err
+}
+
+// GetSalaryRevisions returns the employee's salary history, latest first
+func (r *Repository) GetSalaryRevisions(q sqlx.Queryer, empID uuid.UUID) ([]models.SalaryRevision, error) {
+	revisions := []models.SalaryRevision{}
+	err := sqlx.Select(q, &revisions, `
+		SELECT s.*, a.full_name AS approved_by_name
+		FROM tbl_salary_revision s
+		LEFT JOIN Tbl_Employee a ON a.id = s.approved_by
+		WHERE s.employee_id = $1
+		ORDER BY s.effective_from DESC
+	`, empID)
+	return revisions, err
+}
+
+// GetSalaryRevision returns sql.ErrNoRows unless the revision belongs to the
+// employee, and locks it for the transaction
+func (r *Repository) GetSalaryRevision(tx *sqlx.Tx, empID, revisionID uuid.UUID) (models.SalaryRevision, error) {
+	var rev models.SalaryRevision
+	err := tx.Get(&rev, `
+		SELECT s.*, NULL::text AS approved_by_name
+		FROM tbl_salary_revision s
+		WHERE s.id = $1 AND s.employee_id = $2
+		FOR UPDATE
+	`, revisionID, empID)
+	return rev, err
+}
+
+func (r *Repository) DeleteSalaryRevision(tx *sqlx.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(`DELETE FROM tbl_salary_revision WHERE id = $1`, id)
+	return err
+}
+
+// GetMonthlySalary returns the employee's basic salary for the month: each
+// revision in force during the month counts for the calendar days it covers.
+// The first revision also covers the days before it, so the joining month is
+// paid in full. Employees without revisions earn 0.
+func (r *Repository) GetMonthlySalary(q sqlx.Queryer, empID uuid.UUID, month, year int) (float64, error) {
+	var salary float64
+	err := sqlx.Get(q, &salary, `
+		WITH bounds AS (
+			SELECT MAKE_DATE($3, $2, 1) AS month_start,
+			       (MAKE_DATE($3, $2, 1) + INTERVAL '1 month')::date AS month_end
+		),
+		periods AS (
+			SELECT salary,
+			       CASE WHEN LAG(effective_from) OVER w IS NULL THEN '-infinity'::date
+			            ELSE effective_from END AS starts,
+			       COALESCE(LEAD(effective_from) OVER w, 'infinity'::date) AS ends
+			FROM tbl_salary_revision
+			WHERE employee_id = $1
+			WINDOW w AS (ORDER BY effective_from)
+		)
+		SELECT COALESCE(
+			SUM(p.salary * (LEAST(p.ends, b.month_end) - GREATEST(p.starts, b.month_start)))
+				/ (b.month_end - b.month_start),
+			0)::float8
+		FROM bounds b
+		LEFT JOIN periods p ON p.starts < b.month_end AND p.ends > b.month_start
+		GROUP BY b.month_start, b.month_end
+	`, empID, month, year)
+	return salary, err
+}
+
+// SyncCurrentSalaries sets Tbl_Employee.salary to the revision in force today,
+// for one employee or everyone when empID is nil, and returns who changed
+func (r *Repository) SyncCurrentSalaries(q sqlx.Queryer, empID *uuid.UUID) ([]uuid.UUID, error) {
+	changed := []uuid.UUID{}
+	err := sqlx.Select(q, &changed, `
+		UPDATE Tbl_Employee e
+		SET salary = s.salary, updated_at = NOW()
+		FROM (
+			SELECT DISTINCT ON (employee_id) employee_id, salary
+			FROM tbl_salary_revision
+			WHERE effective_from <= CURRENT_DATE AND ($1::uuid IS NULL OR employee_id = $1)
+			ORDER BY employee_id, effective_from DESC
+		) s
+		WHERE e.id = s.employee_id AND e.salary IS DISTINCT FROM s.salary
+		RETURNING e.id
+	`, empID)
+	return changed, err
+}
+
+// GetPaidPayslipsFrom returns the employee's finalized payslips for the month
+// of from and later
+func (r *Repository) GetPaidPayslipsFrom(tx *sqlx.Tx, empID uuid.UUID, from time.Time) ([]PaidPayslip, error) {
+	payslips := []PaidPayslip{}
+	err := tx.Select(&payslips, `
+		SELECT p.id, pr.month, pr.year,
+		       COALESCE(p.basic_salary, 0)::float8 AS basic_salary,
+		       COALESCE(p.working_days, 0) AS working_days,
+		       COALESCE(p.absent_days, 0)::float8 AS absent_days,
+		       COALESCE(p.deduction_amount, 0) > 0 AS deducted,
+		       (COALESCE(p.net_salary, 0) - p.arrears_amount)::float8 AS paid_net,
+		       COALESCE((SELECT SUM(a.amount) FROM tbl_salary_arrear a WHERE a.payslip_id = p.id), 0)::float8 AS arrears_recorded
+		FROM Tbl_Payslip p
+		JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+		WHERE p.employee_id = $1 AND pr.status = 'FINALIZED'
+		  AND MAKE_DATE(pr.year, pr.month, 1) + INTERVAL '1 month' > $2
+		ORDER BY pr.year, pr.month
+	`, empID, from)
+	return payslips, err
+}
+
+func (r *Repository) CreateSalaryArrear(tx *sqlx.Tx, a *models.SalaryArrear) error {
+	return tx.QueryRow(`
+		INSERT INTO tbl_salary_arrear
+			(employee_id, revision_id, payslip_id, month, year, paid_salary, revised_salary, amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at
+	`, a.EmployeeID, a.RevisionID, a.PayslipID, a.Month, a.Year, a.PaidSalary, a.RevisedSalary, a.Amount,
+	).Scan(&a.ID, &a.Status, &a.CreatedAt)
+}
+
+// GetPendingArrears returns the total the employee's next payslip owes them
+func (r *Repository) GetPendingArrears(q sqlx.Queryer, empID uuid.UUID) (float64, error) {
+	var total float64
+	err := sqlx.Get(q, &total, `
+		SELECT COALESCE(SUM(amount), 0)::float8
+		FROM tbl_salary_arrear
+		WHERE employee_id = $1 AND status = 'PENDING'
+	`, empID)
+	return total, err
+}
+
+// SettleArrears pays the employee's pending arrears with the payslip, adding
+// them to its net salary, and returns the amount
+func (r *Repository) SettleArrears(tx *sqlx.Tx, empID, payslipID uuid.UUID) (float64, error) {
+	var total float64
+	err := tx.Get(&total, `
+		WITH settled AS (
+			UPDATE tbl_salary_arrear
+			SET status = 'PAID', paid_payslip_id = $2, paid_at = NOW()
+			WHERE employee_id = $1 AND status = 'PENDING'
+			RETURNING amount
+		), totals AS (
+			SELECT COALESCE(SUM(amount), 0) AS total FROM settled
+		), updated AS (
+			UPDATE Tbl_Payslip p
+			SET arrears_amount = s.total, net_salary = p.net_salary + s.total
+			FROM totals s
+			WHERE p.id = $2
+		)
+		SELECT total::float8 FROM totals
+	`, empID, payslipID)
+	return total, err
+}
+
+// ListSalaryArrears returns arrears, latest first, optionally of one status or employee
+func (r *Repository) ListSalaryArrears(status string, empID *uuid.UUID) ([]models.SalaryArrear, error) {
+	arrears := []models.SalaryArrear{}
+	err := r.DB.Select(&arrears, `
+		SELECT a.*, e.full_name AS employee_name
+		FROM tbl_salary_arrear a
+		JOIN Tbl_Employee e ON e.id = a.employee_id
+		WHERE ($1 = '' OR a.status = $1)
+		  AND ($2::uuid IS NULL OR a.employee_id = $2)
+		ORDER BY a.created_at DESC, a.year, a.month
+	`, status, empID)
+	return arrears, err
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ------------------ CREATE SESSION ------------------
+func (r *Repository) CreateSession(tx *sqlx.Tx, employeeID uuid.UUID, device, ip, userAgent string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.Get(&id, `
+		INSERT INTO tbl_session (employee_id, device, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, employeeID, device, ip, userAgent)
+	return id, err
+}
+
+// ------------------ GET EMPLOYEE SESSIONS ------------------
+// GetEmployeeSessions returns the sessions that can still be refreshed,
+// most recently used first
+func (r *Repository) GetEmployeeSessions(employeeID uuid.UUID) ([]models.Session, error) {
+	sessions := []models.Session{}
+	err := r.DB.Select(&sessions, `
+		SELECT s.id, s.device, s.ip_address, s.user_agent, s.created_at, s.last_seen_at
+		FROM tbl_session s
+		WHERE s.employee_id = $1
+		  AND s.revoked_at IS NULL
+		  AND EXISTS (
+		      SELECT 1 FROM tbl_refresh_token t
+		      WHERE t.session_id = s.id AND t.revoked_at IS NULL AND t.expires_at > NOW()
+		  )
+		ORDER BY s.last_seen_at DESC
+	`, employeeID)
+	return sessions, err
+}
+
+// ------------------ TOUCH SESSION ------------------
+// TouchSession records activity, writing at most once a minute per session
+func (r *Repository) TouchSession(id uuid.UUID, ip string) error {
+	_, err := r.DB.Exec(`
+		UPDATE tbl_session
+		SET last_seen_at = NOW(), ip_address = $2
+		WHERE id = $1 AND last_seen_at < NOW() - INTERVAL '1 minute'
+	`, id, ip)
+	return err
+}
+
+// ------------------ REVOKE SESSION ------------------
+// RevokeSession signs out one session of the employee together with its
+// refresh tokens. It reports false when there was no such active session.
+func (r *Repository) RevokeSession(employeeID, sessionID uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		WITH tokens AS (
+			UPDATE tbl_refresh_token
+			SET revoked_at = NOW()
+			WHERE session_id = $2 AND employee_id = $1 AND revoked_at IS NULL
+		)
+		UPDATE tbl_session
+		SET revoked_at = NOW()
+		WHERE id = $2 AND employee_id = $1 AND revoked_at IS NULL
+	`, employeeID, sessionID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
 )
 
@@ -15,13 +16,30 @@ func (r *Repository) GetCompanySettings(settings *models.CompanySettings) error
 }
 
 func (r *Repository) UpdateCompanySettings(tx *sqlx.Tx, input models.CompanyField) error {
+	var mfaRoles, emailDomains interface{}
+	if input.MFARequiredRoles != nil {
+		mfaRoles = pq.Array(*input.MFARequiredRoles)
+	}
+	if input.AllowedEmailDomains != nil {
+		emailDomains = pq.Array(*input.AllowedEmailDomains)
+	}
+
 	_, err := tx.Exec(`
         UPDATE Tbl_Company_Settings
-        SET working_days_per_month=$1, allow_manager_add_leave=$2, updated_at=NOW()
-    `, input.WorkingDaysPerMonth, input.AllowManagerAddLeave)
+        SET working_days_per_month=$1, allow_manager_add_leave=$2,
+            mfa_required_roles=COALESCE($3::text[], mfa_required_roles),
+            allowed_email_domains=COALESCE($4::text[], allowed_email_domains), updated_at=NOW()
+    `, input.WorkingDaysPerMonth, input.AllowManagerAddLeave, mfaRoles, emailDomains)
 
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// GetAllowedEmailDomains returns the domains new employee emails may use
+func (r *Repository) GetAllowedEmailDomains() ([]string, error) {
+	var domains pq.StringArray
+	err := r.DB.Get(&domains, `SELECT allowed_email_domains FROM Tbl_Company_Settings LIMIT 1`)
+	return domains, err
+}
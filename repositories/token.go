@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// RefreshToken represents a persisted refresh token row
+type RefreshToken struct {
+	ID         uuid.UUID  `db:"id"`
+	EmployeeID uuid.UUID  `db:"employee_id"`
+	SessionID  *uuid.UUID `db:"session_id"` // nil for tokens issued before sessions existed
+	RevokedAt  *time.Time `db:"revoked_at"`
+	Expired    bool       `db:"expired"`
+	// SessionRevoked is set when the session was signed out remotely
+	SessionRevoked bool `db:"session_revoked"`
+}
+
+// ------------------ CREATE REFRESH TOKEN ------------------
+func (r *Repository) CreateRefreshToken(tx *sqlx.Tx, employeeID, sessionID uuid.UUID, tokenHash string, ttl time.Duration) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO tbl_refresh_token (employee_id, session_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		RETURNING id
+	`, employeeID, sessionID, tokenHash, ttl.Seconds()).Scan(&id)
+	return id, err
+}
+
+// ------------------ GET REFRESH TOKEN BY HASH ------------------
+func (r *Repository) GetRefreshTokenByHash(tokenHash string) (RefreshToken, error) {
+	var token RefreshToken
+	err := r.DB.Get(&token, `
+		SELECT t.id, t.employee_id, t.session_id, t.revoked_at,
+		       t.expires_at <= NOW() AS expired,
+		       s.revoked_at IS NOT NULL AS session_revoked
+		FROM tbl_refresh_token t
+		LEFT JOIN tbl_session s ON s.id = t.session_id
+		WHERE t.token_hash = $1
+	`, tokenHash)
+	return token, err
+}
+
+// ------------------ ROTATE REFRESH TOKEN ------------------
+// RotateRefreshToken revokes the old token and links it to its replacement.
+// The revoked_at IS NULL guard makes concurrent rotations of the same token fail.
+func (r *Repository) RotateRefreshToken(tx *sqlx.Tx, oldID, newID uuid.UUID) error {
+	result, err := tx.Exec(`
+		UPDATE tbl_refresh_token
+		SET revoked_at = NOW(), replaced_by = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, oldID, newID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("refresh token already used")
+	}
+	return nil
+}
+
+// ------------------ REVOKE REFRESH TOKENS ------------------
+// RevokeRefreshToken revokes a single refresh token owned by the employee
+func (r *Repository) RevokeRefreshToken(employeeID uuid.UUID, tokenHash string) error {
+	_, err := r.DB.Exec(`
+		UPDATE tbl_refresh_token
+		SET revoked_at = NOW()
+		WHERE token_hash = $1 AND employee_id = $2 AND revoked_at IS NULL
+	`, tokenHash, employeeID)
+	return err
+}
+
+// RevokeEmployeeRefreshTokens revokes every active refresh token of the employee
+func (r *Repository) RevokeEmployeeRefreshTokens(employeeID uuid.UUID) error {
+	_, err := r.DB.Exec(`
+		UPDATE tbl_refresh_token
+		SET revoked_at = NOW()
+		WHERE employee_id = $1 AND revoked_at IS NULL
+	`, employeeID)
+	return err
+}
+
+// RevokeAllEmployeeTokens kills every session of the employee: sessions and
+// refresh tokens are revoked and all access tokens issued until now are rejected.
+func (r *Repository) RevokeAllEmployeeTokens(employeeID uuid.UUID) error {
+	_, err := r.DB.Exec(`
+		WITH revoked AS (
+			UPDATE tbl_refresh_token
+			SET revoked_at = NOW()
+			WHERE employee_id = $1 AND revoked_at IS NULL
+		), sessions AS (
+			UPDATE tbl_session
+			SET revoked_at = NOW()
+			WHERE employee_id = $1 AND revoked_at IS NULL
+		)
+		UPDATE tbl_employee
+		SET tokens_revoked_at = NOW()
+		WHERE id = $1
+	`, employeeID)
+	return err
+}
+
+// ------------------ ACCESS TOKEN REVOCATION ------------------
+// RevokeAccessToken blacklists a single access token until it expires
+func (r *Repository) RevokeAccessToken(jti string, employeeID uuid.UUID, expiresAt time.Time) error {
+	// Drop entries whose tokens have expired anyway
+	_, _ = r.DB.Exec(`DELETE FROM tbl_revoked_token WHERE expires_at < NOW()`)
+
+	_, err := r.DB.Exec(`
+		INSERT INTO tbl_revoked_token (jti, employee_id, expires_at)
+		VALUES ($1, $2, to_timestamp($3))
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, employeeID, expiresAt.Unix())
+	return err
+}
+
+// IsAccessTokenRevoked reports whether the token was revoked by jti, belongs
+// to a signed out session or was issued before the employee's last "revoke all" event.
+func (r *Repository) IsAccessTokenRevoked(jti, sessionID string, employeeID uuid.UUID, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	err := r.DB.Get(&revoked, `
+		SELECT EXISTS(SELECT 1 FROM tbl_revoked_token WHERE jti = $1)
+		    OR EXISTS(
+		        SELECT 1 FROM tbl_session
+		        WHERE id = NULLIF($4, '')::uuid AND revoked_at IS NOT NULL
+		    )
+		    OR EXISTS(
+		        SELECT 1 FROM tbl_employee
+		        WHERE id = $2
+		          AND tokens_revoked_at IS NOT NULL
+		          AND to_timestamp($3) < date_trunc('second', tokens_revoked_at)
+		    )
+	`, jti, employeeID, issuedAt.Unix(), sessionID)
+	return revoked, err
+}
+
+// ------------------ PASSWORD RESET TOKENS ------------------
+// CreatePasswordResetToken stores a new reset token and invalidates any
+// token previously issued to the employee
+func (r *Repository) CreatePasswordResetToken(tx *sqlx.Tx, employeeID uuid.UUID, tokenHash string, ttl time.Duration) error {
+	_, err := tx.Exec(`
+		UPDATE tbl_password_reset_token
+		SET used_at = NOW()
+		WHERE employee_id = $1 AND used_at IS NULL
+	`, employeeID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO tbl_password_reset_token (employee_id, token_hash, expires_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3))
+	`, employeeID, tokenHash, ttl.Seconds())
+	return err
+}
+
+// ResetPasswordWithToken consumes a valid reset token and sets the new
+// password of its (active) owner in one statement. It returns the employee ID.
+func (r *Repository) ResetPasswordWithToken(tokenHash, hashedPassword string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.DB.QueryRow(`
+		WITH consumed AS (
+			UPDATE tbl_password_reset_token
+			SET used_at = NOW()
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+			RETURNING employee_id
+		)
+		UPDATE tbl_employee e
+		SET password = $2, updated_at = NOW()
+		FROM consumed
+		WHERE e.id = consumed.employee_id AND e.status = 'active'
+		RETURNING e.id
+	`, tokenHash, hashedPassword).Scan(&id)
+	return id, err
+}
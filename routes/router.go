@@ -7,62 +7,141 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/controllers"
 	middleware "github.com/sanjayk-eng/UserMenagmentSystem_Backend/middlewere"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
 )
 
 func SetupRoutes(r *gin.Engine, h *controllers.HandlerFunc) {
 
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Authorization", "token"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	corsConfig := cors.Config{
+		AllowMethods:  []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:  []string{"Origin", "Content-Type", "Authorization", "X-API-Key"},
+		ExposeHeaders: []string{"Authorization", "token", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-Impersonated-By"},
+		MaxAge:        12 * time.Hour,
+	}
+	// Browsers refuse credentials with a wildcard origin, so only named origins get them
+	if len(h.Env.CORS_ALLOWED_ORIGINS) == 1 && h.Env.CORS_ALLOWED_ORIGINS[0] == "*" {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = h.Env.CORS_ALLOWED_ORIGINS
+		corsConfig.AllowCredentials = true
+	}
+	r.Use(cors.New(corsConfig))
+	r.Use(middleware.RateLimit(h, middleware.RateLimitDefault))
 
 	// ----------------- Auth -----------------
+	// Public keys for verifying our JWTs
+	r.GET("/.well-known/jwks.json", h.JWKS)
+
 	auth := r.Group("/api/auth")
+	authLimit := middleware.RateLimit(h, middleware.RateLimitAuth) // per IP, for unauthenticated credential checks
 	{
-		auth.POST("/login", h.Login)
-		auth.GET("/verify", h.VerifyToken)                           // Verify token validity
-		auth.GET("/status", h.CheckAuthStatus)                       // Check auth status without requiring auth
-		auth.POST("/logout", middleware.AuthMiddleware(h), h.Logout) // Logout (requires valid token)
+		auth.POST("/login", authLimit, h.Login)
+		auth.POST("/refresh", authLimit, h.RefreshToken)                                   // Rotate refresh token and issue new access token
+		auth.GET("/verify", h.VerifyToken)                                                 // Verify token validity
+		auth.GET("/status", h.CheckAuthStatus)                                             // Check auth status without requiring auth
+		auth.POST("/logout", middleware.TokenAuthMiddleware(h), h.Logout)                  // Logout (requires valid token)
+		auth.POST("/forgot-password", authLimit, h.ForgotPassword)                         // Email a single-use reset link
+		auth.POST("/reset-password", authLimit, h.ResetPassword)                           // Set new password using reset token
+		auth.POST("/change-password", middleware.TokenAuthMiddleware(h), h.ChangePassword) // Change own password (requires current password)
+
+		// Two-factor authentication (TOTP)
+		auth.POST("/mfa/verify", authLimit, h.VerifyMFA)                                               // Second login step: exchange MFA token + code for tokens
+		auth.POST("/mfa/setup", middleware.MFAEnrollmentMiddleware(h), h.SetupMFA)                     // Generate secret and otpauth URI
+		auth.POST("/mfa/enable", middleware.MFAEnrollmentMiddleware(h), h.EnableMFA)                   // Confirm code, enable MFA and get recovery codes
+		auth.POST("/mfa/disable", middleware.TokenAuthMiddleware(h), h.DisableMFA)                     // Disable MFA (not allowed for enforced roles)
+		auth.POST("/mfa/recovery-codes", middleware.TokenAuthMiddleware(h), h.RegenerateRecoveryCodes) // Replace recovery codes
+
+		// Single sign-on (OpenID Connect)
+		auth.GET("/oidc/login", authLimit, h.StartOIDCLogin)   // Get identity provider URL (state + PKCE)
+		auth.POST("/oidc/callback", authLimit, h.OIDCCallback) // Exchange code for tokens
+
+		// Impersonation (support): act as another employee, read-only by default
+		auth.POST("/impersonate", middleware.TokenAuthMiddleware(h), middleware.RequirePermission(h, constant.PermEmployeeImpersonate), h.StartImpersonation) // Get a token acting as an employee
+		auth.POST("/impersonate/stop", middleware.ImpersonationMiddleware(h), h.StopImpersonation)                                                            // Revoke the impersonation token
+
+		// Sessions (one per sign-in)
+		auth.GET("/sessions", middleware.TokenAuthMiddleware(h), h.GetMySessions)          // List own active sessions
+		auth.DELETE("/sessions/:id", middleware.TokenAuthMiddleware(h), h.RevokeMySession) // Sign out one of own sessions
 	}
 
 	// ----------------- Employees -----------------
 	employees := r.Group("/api/employee")
 	employees.Use(middleware.AuthMiddleware(h)) // Protect employee routes
+	offboarding := middleware.RequirePermission(h, constant.PermOffboardingManage)
+	onboarding := middleware.RequirePermission(h, constant.PermOnboardingManage)
+	salaryRevise := middleware.RequirePermission(h, constant.PermSalaryRevise)
+	department := middleware.RequirePermission(h, constant.PermDepartmentManage)
+	employment := middleware.RequirePermission(h, constant.PermEmploymentManage)
 	{
-		employees.GET("/", h.GetEmployee)                                // List all employees (SUPER_ADMIN, ADMIN/HR)
-		employees.GET("/my-team", h.GetMyTeam)                           // Get manager's team members (MANAGER only)
-		employees.GET("/:id", h.GetEmployeeById)                         // Get employee details (Self/Manager/Admin)
-		employees.POST("/", h.CreateEmployee)                            // Create employee (SUPER_ADMIN, ADMIN/HR)
-		employees.PATCH("/:id", h.UpdateEmployeeInfo)                    // Update employee info (SUPER_ADMIN, ADMIN/HR)
-		employees.PATCH("/:id/password", h.UpdateEmployeePassword)       // Update employee password (SUPER_ADMIN, ADMIN, HR)
-		employees.PATCH("/:id/role", h.UpdateEmployeeRole)               // Change employee role (SUPER_ADMIN, ADMIN/HR)
-		employees.PATCH("/:id/manager", h.UpdateEmployeeManager)         // Set/change manager (SUPER_ADMIN, ADMIN/HR)
-		employees.PATCH("/:id/designation", h.UpdateEmployeeDesignation) // Assign/update designation (SUPER_ADMIN, ADMIN, HR)
-		employees.PUT("/deactivate/:id", h.DeleteEmployeeStatus)         // Deactivate/Activate employee (SUPER_ADMIN, ADMIN/HR)
-		employees.GET("/:id/reports", h.GetEmployeeReports)              // Get direct reports (Self/Manager/Admin)
+		employees.GET("/", middleware.RequirePermission(h, constant.PermEmployeeViewAll), h.GetEmployee)                                    // List all employees
+		employees.GET("/export", middleware.RequirePermission(h, constant.PermEmployeeViewAll), h.ExportEmployees)                          // Download employee list as CSV/XLSX
+		employees.GET("/my-team", middleware.RequirePermission(h, constant.PermEmployeeViewTeam), h.GetMyTeam)                              // Get manager's team members
+		employees.GET("/offboarding", offboarding, h.ListOffboardings)                                                                      // List exits (?status=OPEN|CLEARED|CANCELLED)
+		employees.GET("/onboarding/templates", onboarding, h.ListOnboardingTemplates)                                                       // List onboarding templates with their tasks
+		employees.POST("/onboarding/templates", onboarding, h.CreateOnboardingTemplate)                                                     // Create a template (tasks with owner role and due offset)
+		employees.GET("/onboarding/templates/:templateId", onboarding, h.GetOnboardingTemplate)                                             // Get a template
+		employees.PUT("/onboarding/templates/:templateId", onboarding, h.UpdateOnboardingTemplate)                                          // Replace a template and its tasks
+		employees.DELETE("/onboarding/templates/:templateId", onboarding, h.DeleteOnboardingTemplate)                                       // Delete a template
+		employees.GET("/onboarding/overdue", h.GetOverdueOnboardingTasks)                                                                   // Overdue tasks (all with onboarding.manage, else own)
+		employees.GET("/probation/due", employment, h.GetProbationsDue)                                                                     // Probations ending within ?within_days= (default 30) or overdue
+		employees.GET("/:id", h.GetEmployeeById)                                                                                            // Get employee details (Self/Manager/Admin)
+		employees.POST("/", middleware.RequirePermission(h, constant.PermEmployeeCreate), h.CreateEmployee)                                 // Create employee
+		employees.POST("/import", middleware.RequirePermission(h, constant.PermEmployeeCreate), h.ImportEmployees)                          // Bulk create from CSV/XLSX (?dry_run=true to validate only)
+		employees.PATCH("/:id", h.UpdateEmployeeInfo)                                                                                       // Update own name, or any field with employee.update
+		employees.PATCH("/:id/password", middleware.RequirePermission(h, constant.PermEmployeePassword), h.UpdateEmployeePassword)          // Update employee password
+		employees.PATCH("/:id/role", middleware.RequirePermission(h, constant.PermEmployeeRole), h.UpdateEmployeeRole)                      // Change employee role
+		employees.PATCH("/:id/manager", middleware.RequirePermission(h, constant.PermEmployeeManager), h.UpdateEmployeeManager)             // Set/change manager
+		employees.PATCH("/:id/designation", middleware.RequirePermission(h, constant.PermEmployeeDesignation), h.UpdateEmployeeDesignation) // Assign/update designation
+		employees.PATCH("/:id/department", department, h.UpdateEmployeeDepartment)                                                          // Move to a department or remove from it
+		employees.PUT("/deactivate/:id", middleware.RequirePermission(h, constant.PermEmployeeStatus), h.DeleteEmployeeStatus)              // Deactivate/Activate employee
+		employees.PATCH("/:id/unlock", middleware.RequirePermission(h, constant.PermEmployeeUnlock), h.UnlockEmployeeLogin)                 // Clear login lockout
+		employees.DELETE("/:id/mfa", middleware.RequirePermission(h, constant.PermEmployeeMFAReset), h.ResetEmployeeMFA)                    // Reset two-factor authentication
+		employees.GET("/:id/sessions", middleware.RequirePermission(h, constant.PermEmployeeSessionRevoke), h.GetEmployeeSessions)          // List employee sessions
+		employees.DELETE("/:id/sessions", middleware.RequirePermission(h, constant.PermEmployeeSessionRevoke), h.RevokeEmployeeSessions)    // Force sign-out on all devices
+		employees.GET("/:id/reports", h.GetEmployeeReports)                                                                                 // Direct and indirect reports, flat (Self/Managers above/employee.view_all)
+		employees.GET("/:id/org-chart", h.GetOrgChart)                                                                                      // Nested reporting tree rooted at the employee (?depth=)
+		employees.GET("/:id/chain-of-command", h.GetChainOfCommand)                                                                         // Managers up to the top of the hierarchy
+		employees.GET("/:id/profile", h.GetEmployeeProfile)                                                                                 // Contact, personal and masked bank/ID details (Self/profile.view_all)
+		employees.PATCH("/:id/profile", h.UpdateEmployeeProfile)                                                                            // Edit profile (field-level permissions)
+		employees.GET("/:id/documents", h.GetEmployeeDocuments)                                                                             // List documents (Self/document.view_all)
+		employees.POST("/:id/documents", h.UploadEmployeeDocument)                                                                          // Upload a document (Self/document.manage)
+		employees.GET("/:id/documents/:docId", h.DownloadEmployeeDocument)                                                                  // Download a document (Self/document.view_all)
+		employees.DELETE("/:id/documents/:docId", h.DeleteEmployeeDocument)                                                                 // Delete a document (document.manage)
+		employees.GET("/:id/offboarding", h.GetOffboarding)                                                                                 // Exit checklist and clearance (Self/offboarding.manage)
+		employees.POST("/:id/offboarding", offboarding, h.StartOffboarding)                                                                 // Start exit or move the ending date
+		employees.PATCH("/:id/offboarding/items/:itemId", offboarding, h.UpdateOffboardingItem)                                             // Mark a checklist item DONE/WAIVED or reopen it
+		employees.DELETE("/:id/offboarding", offboarding, h.CancelOffboarding)                                                              // Cancel exit before deactivation
+		employees.GET("/:id/onboarding", h.GetOnboarding)                                                                                   // Onboarding checklist (Self/Manager/onboarding.manage)
+		employees.POST("/:id/onboarding", onboarding, h.ApplyOnboardingTemplate)                                                            // Add a template's tasks to the checklist
+		employees.PATCH("/:id/onboarding/tasks/:taskId", h.UpdateOnboardingTask)                                                            // Complete or reopen a task (by its owner)
+		employees.GET("/:id/salary-revisions", h.GetSalaryRevisions)                                                                        // Salary history and scheduled revisions (Self/salary.revise/payroll.view_all)
+		employees.POST("/:id/salary-revisions", salaryRevise, h.CreateSalaryRevision)                                                       // Schedule a revision, backdated ones record arrears
+		employees.DELETE("/:id/salary-revisions/:revisionId", salaryRevise, h.CancelSalaryRevision)                                         // Cancel a revision not in force yet
+		employees.PATCH("/:id/employment", employment, h.UpdateEmployeeEmployment)                                                          // Change employment type, start or move probation
+		employees.GET("/:id/probation", h.GetProbation)                                                                                     // Employment type, probation and decisions (Self/Manager/employment.manage)
+		employees.POST("/:id/probation/decision", employment, h.DecideProbation)                                                            // Confirm, extend or terminate the probation
 	}
 
 	// ----------------- Leaves -----------------
 	leaves := r.Group("/api/leaves")
 	leaves.Use(middleware.AuthMiddleware(h))
+	policyUpdate := middleware.RequirePermission(h, constant.PermLeavePolicyUpdate)
 	{
-		leaves.POST("/apply", h.ApplyLeave)                        // Employee applies for leave
-		leaves.POST("/admin-add/policy", h.AdminAddLeavePolicy)    // Admin creates leave policy
-		leaves.PUT("/admin-update/policy/:id", h.UpdateLeavePolicy) // Admin, SuperAdmin, HR update leave policy
-		leaves.DELETE("/admin-delete/policy/:id", h.DeleteLeavePolicy) // Admin, SuperAdmin, HR delete leave policy
-		leaves.GET("/Get-All-Leave-Policy", h.GetAllLeavePolicies) // Get all leave policies
-		leaves.GET("/manager/history", h.GetManagerLeaveHistory)   // Manager gets team leave history
-		leaves.POST("/:id/action", h.ActionLeave)                  // Approve/Reject leave
-		leaves.DELETE("/:id/cancel", h.CancelLeave)                // Cancel pending leave (Employee/Admin)
-		leaves.POST("/:id/withdraw", h.WithdrawLeave)              // Withdraw approved leave (Admin/Manager)
-		leaves.GET("/all", h.GetAllLeaves)                         // Get all leaves (filtered by role)
-		leaves.GET("/:id", h.GetLeaveByID)                         // Get leave by ID (role-based access)
-		leaves.GET("/timming", h.GetLeaveTiming)                   // Get all Leave Timing
-		leaves.PUT("/timming", h.UpdateLeaveTiming)                // Update leave timing by super admin and admin
+		leaves.POST("/apply", h.ApplyLeave)                                                                                                        // Employee applies for leave
+		leaves.POST("/admin-add/policy", middleware.RequirePermission(h, constant.PermLeavePolicyCreate), h.AdminAddLeavePolicy)                   // Create leave policy
+		leaves.PUT("/admin-update/policy/:id", middleware.RequirePermission(h, constant.PermLeavePolicyUpdate), h.UpdateLeavePolicy)               // Update leave policy
+		leaves.DELETE("/admin-delete/policy/:id", middleware.RequirePermission(h, constant.PermLeavePolicyDelete), h.DeleteLeavePolicy)            // Delete leave policy
+		leaves.GET("/Get-All-Leave-Policy", h.GetAllLeavePolicies)                                                                                 // Get all leave policies
+		leaves.GET("/policy/:id/rules", h.GetLeaveTypeRules)                                                                                       // Entitlement per employment type
+		leaves.PUT("/admin-update/policy/:id/rules", policyUpdate, h.UpdateLeaveTypeRules)                                                         // Replace entitlements per employment type
+		leaves.GET("/manager/history", middleware.RequirePermission(h, constant.PermLeaveViewTeam), h.GetManagerLeaveHistory)                      // Manager gets team leave history
+		leaves.POST("/:id/action", middleware.RequirePermission(h, constant.PermLeaveApprove, constant.PermLeaveApproveTeam), h.ActionLeave)       // Approve/Reject leave
+		leaves.DELETE("/:id/cancel", h.CancelLeave)                                                                                                // Cancel own pending leave, or any with leave.cancel_any
+		leaves.POST("/:id/withdraw", middleware.RequirePermission(h, constant.PermLeaveWithdraw, constant.PermLeaveWithdrawTeam), h.WithdrawLeave) // Withdraw approved leave
+		leaves.GET("/all", h.GetAllLeaves)                                                                                                         // Get all leaves (filtered by permission)
+		leaves.GET("/:id", h.GetLeaveByID)                                                                                                         // Get leave by ID (permission-based access)
+		leaves.GET("/timming", h.GetLeaveTiming)                                                                                                   // Get all Leave Timing
+		leaves.PUT("/timming", middleware.RequirePermission(h, constant.PermLeaveTimingManage), h.UpdateLeaveTiming)                               // Update leave timing
 	}
 
 	// ----------------- Leave Balances -----------------
@@ -70,8 +149,8 @@ func SetupRoutes(r *gin.Engine, h *controllers.HandlerFunc) {
 	leaveBalances.Use(middleware.AuthMiddleware(h))
 	{
 
-		leaveBalances.GET("/employee/:id", h.GetLeaveBalances)  // GET /api/employees/:id/leave-balances
-		leaveBalances.POST("/:id/adjust", h.AdjustLeaveBalance) // POST /api/leave-balances/:id/adjust
+		leaveBalances.GET("/employee/:id", h.GetLeaveBalances)                                                                    // GET /api/employees/:id/leave-balances
+		leaveBalances.POST("/:id/adjust", middleware.RequirePermission(h, constant.PermLeaveBalanceAdjust), h.AdjustLeaveBalance) // POST /api/leave-balances/:id/adjust
 	}
 
 	// ----------------- Payroll -----------------
@@ -79,51 +158,100 @@ func SetupRoutes(r *gin.Engine, h *controllers.HandlerFunc) {
 	payroll.Use(middleware.AuthMiddleware(h))
 	{
 		// Run payroll for a given month & year
-		payroll.POST("/run", h.RunPayroll)
+		payroll.POST("/run", middleware.RequirePermission(h, constant.PermPayrollRun), middleware.RateLimit(h, middleware.RateLimitPayroll), h.RunPayroll)
 		// POST /api/payroll/run
 
 		// Finalize payroll for a specific payroll run ID
-		payroll.POST("/:id/finalize", h.FinalizePayroll)
+		payroll.POST("/:id/finalize", middleware.RequirePermission(h, constant.PermPayrollFinalize), h.FinalizePayroll)
 		// POST /api/payroll/{id}/finalize
 
 		payroll.GET("/payslip", h.GetFinalizedPayslips)
 
+		// Arrears of backdated salary revisions (?status=PENDING|PAID&employee_id=)
+		payroll.GET("/arrears", middleware.RequirePermission(h, constant.PermPayrollViewAll), h.ListSalaryArrears)
+
 		// Download payslip PDF for a specific employee payslip ID
-		payroll.GET("/payslips/:id/pdf", h.GetPayslipPDF)
+		payroll.GET("/payslips/:id/pdf", middleware.RateLimit(h, middleware.RateLimitPDF), h.GetPayslipPDF)
 		// GET /api/payroll/payslips/{id}/pdf
 
 	}
 
 	// ----------------- Settings -----------------
 	settings := r.Group("/api/settings")
-	settings.Use(middleware.AuthMiddleware(h)) // Only admin/superadmin
+	settings.Use(middleware.AuthMiddleware(h))
 	{
-		settings.GET("/", h.GetCompanySettings)    // Get current settings
-		settings.PUT("/", h.UpdateCompanySettings) // Update settings
+		settings.GET("/", middleware.RequirePermission(h, constant.PermSettingsView), h.GetCompanySettings)      // Get current settings
+		settings.PUT("/", middleware.RequirePermission(h, constant.PermSettingsUpdate), h.UpdateCompanySettings) // Update settings
+
+		// Documented paths, "/" is kept for existing clients
+		settings.GET("/company", middleware.RequirePermission(h, constant.PermSettingsView), h.GetCompanySettings)
+		settings.PUT("/company", middleware.RequirePermission(h, constant.PermSettingsUpdate), h.UpdateCompanySettings)
 	}
 	holidays := r.Group("/api/settings/holidays")
 	holidays.Use(middleware.AuthMiddleware(h))
 	{
-		holidays.POST("/", h.AddHoliday)         // SUPERADMIN adds holiday
-		holidays.GET("/", h.GetHolidays)         // List all holidays
-		holidays.DELETE("/:id", h.DeleteHoliday) // Remove holiday
+		holidays.POST("/", middleware.RequirePermission(h, constant.PermHolidayManage), h.AddHoliday)         // Add holiday
+		holidays.GET("/", h.GetHolidays)                                                                      // List all holidays
+		holidays.DELETE("/:id", middleware.RequirePermission(h, constant.PermHolidayManage), h.DeleteHoliday) // Remove holiday
 	}
 
 	// ----------------- Designations -----------------
 	designations := r.Group("/api/designations")
 	designations.Use(middleware.AuthMiddleware(h))
 	{
-		designations.POST("/", h.CreateDesignation)      // Create designation (ADMIN, SUPERADMIN, HR)
-		designations.GET("/", h.GetAllDesignations)      // Get all designations (All authenticated users)
-		designations.GET("/:id", h.GetDesignationByID)   // Get designation by ID (All authenticated users)
-		designations.PATCH("/:id", h.UpdateDesignation)  // Update designation (ADMIN, SUPERADMIN, HR)
-		designations.DELETE("/:id", h.DeleteDesignation) // Delete designation (ADMIN, SUPERADMIN, HR)
+		designations.POST("/", middleware.RequirePermission(h, constant.PermDesignationManage), h.CreateDesignation)      // Create designation
+		designations.GET("/", h.GetAllDesignations)                                                                       // Get all designations (All authenticated users)
+		designations.GET("/:id", h.GetDesignationByID)                                                                    // Get designation by ID (All authenticated users)
+		designations.PATCH("/:id", middleware.RequirePermission(h, constant.PermDesignationManage), h.UpdateDesignation)  // Update designation
+		designations.DELETE("/:id", middleware.RequirePermission(h, constant.PermDesignationManage), h.DeleteDesignation) // Delete designation
+	}
+
+	// ----------------- Departments -----------------
+	departments := r.Group("/api/departments")
+	departments.Use(middleware.AuthMiddleware(h))
+	members := middleware.RequirePermission(h, constant.PermDepartmentManage, constant.PermEmployeeViewAll)
+	{
+		departments.GET("/", h.ListDepartments)                          // List departments with parent, head and member count
+		departments.GET("/report", department, h.GetDepartmentReport)    // Headcount, leave and payroll per department (?year=&month=)
+		departments.GET("/:id", h.GetDepartment)                         // Get department with its sub-departments
+		departments.GET("/:id/members", members, h.GetDepartmentMembers) // Members, sub-departments included
+		departments.POST("/", department, h.CreateDepartment)            // Create department
+		departments.PUT("/:id", department, h.UpdateDepartment)          // Update name, parent or head
+		departments.DELETE("/:id", department, h.DeleteDepartment)       // Delete, sub-departments move up
+	}
+
+	// ----------------- Employment Types -----------------
+	employmentTypes := r.Group("/api/employment-types")
+	employmentTypes.Use(middleware.AuthMiddleware(h))
+	{
+		employmentTypes.GET("/", h.ListEmploymentTypes)                      // List types with payroll switches and default probation
+		employmentTypes.POST("/", employment, h.CreateEmploymentType)        // Create type
+		employmentTypes.PUT("/:code", employment, h.UpdateEmploymentType)    // Update name, payroll switches or probation months
+		employmentTypes.DELETE("/:code", employment, h.DeleteEmploymentType) // Delete a type no employee has
 	}
 	logs := r.Group("/api/logs")
 	logs.Use((middleware.AuthMiddleware(h)))
 	{
-		logs.GET("/", h.GetLogs) // Get logs filtered by days (SUPERADMIN only)
+		logs.GET("/", middleware.RequirePermission(h, constant.PermLogView), h.GetLogs) // Get logs filtered by days
+	}
+	// ----------------- Permissions -----------------
+	permissions := r.Group("/api/permissions")
+	permissions.Use(middleware.AuthMiddleware(h), middleware.RequirePermission(h, constant.PermPermissionManage))
+	{
+		permissions.GET("/", h.GetPermissions)                                 // Permission catalog and role grants
+		permissions.POST("/roles/:role", h.GrantRolePermission)                // Grant a permission to a role
+		permissions.DELETE("/roles/:role/:permission", h.RevokeRolePermission) // Revoke a permission from a role
 	}
+
+	// ----------------- API Keys -----------------
+	apiKeys := r.Group("/api/api-keys")
+	apiKeys.Use(middleware.AuthMiddleware(h), middleware.RequirePermission(h, constant.PermAPIKeyManage))
+	{
+		apiKeys.POST("/", h.CreateAPIKey)      // Issue a scoped API key (returned once)
+		apiKeys.GET("/", h.GetAPIKeys)         // List API keys with last-used info
+		apiKeys.DELETE("/:id", h.RevokeAPIKey) // Revoke an API key
+	}
+
 	// Category routes
 	catagory := r.Group("/api/catagory")
 	catagory.Use(middleware.AuthMiddleware(h))
@@ -131,30 +259,30 @@ func SetupRoutes(r *gin.Engine, h *controllers.HandlerFunc) {
 		// ======================
 		// Category CRUD
 		// ======================
-		catagory.POST("/", h.CreateCategory)      // Create category (ADMIN, SUPERADMIN, HR)
-		catagory.GET("/", h.GetAllCategory)       // Get all categories (ADMIN, SUPERADMIN, HR)
-		catagory.DELETE("/:id", h.DeleteCategory) // Delete category (ADMIN, SUPERADMIN, HR)
-		catagory.PUT("/:id", h.UpdateCategory)    // Update category (ADMIN, SUPERADMIN, HR)
+		catagory.POST("/", middleware.RequirePermission(h, constant.PermEquipmentCategoryManage), h.CreateCategory)      // Create category
+		catagory.GET("/", middleware.RequirePermission(h, constant.PermEquipmentCategoryManage), h.GetAllCategory)       // Get all categories
+		catagory.DELETE("/:id", middleware.RequirePermission(h, constant.PermEquipmentCategoryManage), h.DeleteCategory) // Delete category
+		catagory.PUT("/:id", middleware.RequirePermission(h, constant.PermEquipmentCategoryManage), h.UpdateCategory)    // Update category
 
 		// ======================
 		// Equipment under category
 		// ======================
 		equipment := catagory.Group("/equipment")
 		{
-			equipment.POST("/", h.CreateEquipment)                  // Create equipment (ADMIN, SUPERADMIN, HR)
-			equipment.GET("/", h.GetAllEquipment)                   // Get all equipment (ADMIN, SUPERADMIN, HR)
-			equipment.GET("/by-category", h.GetEquipmentByCategory) // Get equipment by category ID (query param)
-			equipment.PUT("/:id", h.UpdateEquipment)                // Update equipment (ADMIN, SUPERADMIN, HR)
-			equipment.DELETE("/:id", h.DeleteEquipment)             // Delete equipment (ADMIN, SUPERADMIN, HR)
+			equipment.POST("/", middleware.RequirePermission(h, constant.PermEquipmentManage), h.CreateEquipment)                // Create equipment
+			equipment.GET("/", middleware.RequirePermission(h, constant.PermEquipmentView), h.GetAllEquipment)                   // Get all equipment
+			equipment.GET("/by-category", middleware.RequirePermission(h, constant.PermEquipmentView), h.GetEquipmentByCategory) // Get equipment by category ID (query param)
+			equipment.PUT("/:id", middleware.RequirePermission(h, constant.PermEquipmentManage), h.UpdateEquipment)              // Update equipment
+			equipment.DELETE("/:id", middleware.RequirePermission(h, constant.PermEquipmentManage), h.DeleteEquipment)           // Delete equipment
 		}
 		// Equipment assignment routes
 		assign := equipment.Group("/assign")
 		{
-			assign.POST("/", h.AssignEquipment)                           // Assign equipment
-			assign.GET("/", h.GetAllAssignedEquipment)                    // Get all assignments
-			assign.GET("/employee/:id", h.GetAssignedEquipmentByEmployee) // Get by employee id
-			assign.DELETE("/remove", h.RemoveEquipment)                   // Remove/return equipment
-			assign.PUT("/update", h.UpdateAssignment)                     // Update assignment (quantity or reassign)
+			assign.POST("/", middleware.RequirePermission(h, constant.PermEquipmentAssign), h.AssignEquipment)         // Assign equipment
+			assign.GET("/", middleware.RequirePermission(h, constant.PermEquipmentAssign), h.GetAllAssignedEquipment)  // Get all assignments
+			assign.GET("/employee/:id", h.GetAssignedEquipmentByEmployee)                                              // Get by employee id
+			assign.DELETE("/remove", middleware.RequirePermission(h, constant.PermEquipmentAssign), h.RemoveEquipment) // Remove/return equipment
+			assign.PUT("/update", middleware.RequirePermission(h, constant.PermEquipmentAssign), h.UpdateAssignment)   // Update assignment (quantity or reassign)
 		}
 
 	}
@@ -2,21 +2,42 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/keyring"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type CustomClaims struct {
 	UserID   string `json:"user_id"`
 	UserRole string `json:"user_role"`
+	// Purpose is empty for access tokens. Restricted tokens (MFA challenge,
+	// MFA enrollment) set it and are rejected by AuthMiddleware.
+	Purpose string `json:"purpose,omitempty"`
+	// SessionID links access tokens to their row in tbl_session
+	SessionID string `json:"sid,omitempty"`
+	// ImpersonatorID is the SUPERADMIN acting as UserID (impersonation tokens only)
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
+	// ReadOnly impersonation tokens may only be used for GET requests
+	ReadOnly bool `json:"read_only,omitempty"`
 	jwt.RegisteredClaims
 }
 
+const (
+	TokenPurposeMFA       = "mfa"        // second login step: TOTP or recovery code expected
+	TokenPurposeMFAEnroll = "mfa_enroll" // role requires MFA but the user has not enrolled yet
+
+	TokenPurposeImpersonation = "impersonation" // SUPERADMIN acting as another employee
+)
+
 // -------------------------
 // 1️ Bcrypt functions
 // -------------------------
@@ -98,31 +119,44 @@ func generateRandomString(charset string, length int) (string, error) {
 // 2️ JWT functions
 // -------------------------
 
-// GenerateToken generates a JWT token with userID payload
-func GenerateToken(userID string, userRole string, jwtKey string) (string, error) {
-	claims := CustomClaims{
-		UserID:   userID,
-		UserRole: userRole,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// GenerateToken generates a short-lived access token with a unique jti for the given session
+func GenerateToken(userID, userRole, sessionID string, keys *keyring.Keyring, ttl time.Duration) (string, error) {
+	return signToken(CustomClaims{UserID: userID, UserRole: userRole, SessionID: sessionID}, keys, ttl)
+}
+
+// GeneratePurposeToken generates a token restricted to a single purpose
+func GeneratePurposeToken(userID, userRole, purpose string, keys *keyring.Keyring, ttl time.Duration) (string, error) {
+	return signToken(CustomClaims{UserID: userID, UserRole: userRole, Purpose: purpose}, keys, ttl)
+}
+
+// GenerateImpersonationToken generates a token that lets impersonatorID act as userID
+func GenerateImpersonationToken(userID, userRole, impersonatorID string, readOnly bool, keys *keyring.Keyring, ttl time.Duration) (string, error) {
+	return signToken(CustomClaims{
+		UserID:         userID,
+		UserRole:       userRole,
+		Purpose:        TokenPurposeImpersonation,
+		ImpersonatorID: impersonatorID,
+		ReadOnly:       readOnly,
+	}, keys, ttl)
+}
+
+// signToken signs with the keyring's active key, adding the kid header
+func signToken(claims CustomClaims, keys *keyring.Keyring, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtKey))
+	return keys.Sign(claims)
 }
 
-// ValidateToken validates a JWT token and returns userID
-func ValidateToken(tokenString string, jwtKey string) (*CustomClaims, error) {
+// ValidateToken validates a JWT token against the keyring (by kid) and returns its claims
+func ValidateToken(tokenString string, keys *keyring.Keyring) (*CustomClaims, error) {
 	claims := &CustomClaims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return []byte(jwtKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc)
 	if err != nil {
 		return nil, err
 	}
@@ -131,19 +165,46 @@ func ValidateToken(tokenString string, jwtKey string) (*CustomClaims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	// Tokens issued before revocation support have no jti and cannot be revoked
+	if claims.ID == "" || claims.IssuedAt == nil {
+		return nil, errors.New("token is missing jti")
+	}
+
 	return claims, nil
 }
 
-func GenerateExpiredToken(userID string, userRole string, jwtKey string) (string, error) {
-	claims := CustomClaims{
-		UserID:   userID,
-		UserRole: userRole,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-1 * time.Minute)), // already expired
-			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-2 * time.Minute)),
-		},
+// -------------------------
+// 3️ Opaque token functions
+// -------------------------
+
+// GenerateOpaqueToken returns a random opaque token (refresh or password
+// reset) together with the hash that is persisted in the database
+func GenerateOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
 	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtKey))
+// HashToken returns the hex encoded SHA-256 of an opaque token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix marks our API keys so they are easy to recognise (and to
+// scan for if one is leaked)
+const APIKeyPrefix = "zk_"
+
+// GenerateAPIKey returns a new API key, the short prefix shown in listings
+// and the hash that is persisted in the database
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	token, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	key = APIKeyPrefix + token
+	return key, key[:len(APIKeyPrefix)+8], HashToken(key), nil
 }
@@ -6,6 +6,7 @@ type Common struct {
 	Component  string
 	Action     string
 	FromUserID uuid.UUID
+	TargetID   *uuid.UUID // employee the action was taken on, if recorded
 }
 
 func NewCommon(component, action string, fromUserID uuid.UUID) *Common {
@@ -15,3 +16,9 @@ func NewCommon(component, action string, fromUserID uuid.UUID) *Common {
 		FromUserID: fromUserID,
 	}
 }
+
+// WithTarget records the employee the action was taken on
+func (c *Common) WithTarget(targetID uuid.UUID) *Common {
+	c.TargetID = &targetID
+	return c
+}
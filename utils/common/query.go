@@ -8,13 +8,52 @@ import (
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
 )
 
+// Request context keys describing who is really behind a request
+const (
+	ContextAPIKeyID       = "api_key_id"      // API key used to authenticate, if any
+	ContextImpersonatorID = "impersonator_id" // SUPERADMIN impersonating the user, if any
+)
+
+// AddLog writes an activity log entry. When the transaction was started for a
+// request authenticated with an API key or made while impersonating, the key
+// or the real actor is recorded as well.
 func AddLog(data *utils.Common, q *sqlx.Tx) error {
-	_, err := q.Exec("INSERT INTO tbl_log (from_user_id, action, component) VALUES ($1, $2, $3)", data.FromUserID, data.Action, data.Component)
+	_, err := q.Exec(`
+		INSERT INTO tbl_log (from_user_id, action, component, target_id, api_key_id, impersonator_id)
+		VALUES ($1, $2, $3, $4,
+			NULLIF(current_setting('app.api_key_id', true), '')::uuid,
+			NULLIF(current_setting('app.impersonator_id', true), '')::uuid)
+	`, data.FromUserID, data.Action, data.Component, data.TargetID)
 	return err
 }
 
-func ExecuteTransaction(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+// BeginTx starts a transaction and makes the request's API key and
+// impersonator (if any) visible to AddLog for the lifetime of the transaction
+func BeginTx(ctx context.Context, db *sqlx.DB) (*sqlx.Tx, error) {
 	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := map[string]string{
+		ContextAPIKeyID:       "app.api_key_id",
+		ContextImpersonatorID: "app.impersonator_id",
+	}
+	for key, setting := range settings {
+		value, ok := ctx.Value(key).(string)
+		if !ok || value == "" {
+			continue
+		}
+		if _, err := tx.Exec(`SELECT set_config($1, $2, true)`, setting, value); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+func ExecuteTransaction(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := BeginTx(ctx, db)
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %w", err)
 	}
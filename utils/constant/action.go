@@ -1,13 +1,22 @@
 package constant
 
 const (
-	ActionCreate     = "create"
-	ActionUpdate     = "update"
-	ActionDelete     = "delete"
-	ActionApproval   = "approval"
-	ActionRejection  = "rejection"
-	ActionRun        = "run"
-	ActionFinalize   = "finalize"
-	ActionCancel     = "cancel"
-	ActionWithdrawal = "withdrawal"
+	ActionCreate           = "create"
+	ActionUpdate           = "update"
+	ActionDelete           = "delete"
+	ActionApproval         = "approval"
+	ActionRejection        = "rejection"
+	ActionRun              = "run"
+	ActionFinalize         = "finalize"
+	ActionCancel           = "cancel"
+	ActionWithdrawal       = "withdrawal"
+	ActionLockout          = "lockout"
+	ActionUnlock           = "unlock"
+	ActionMFAEnable        = "mfa-enable"
+	ActionMFADisable       = "mfa-disable"
+	ActionSignOut          = "sign-out"
+	ActionImpersonateStart = "impersonate-start"
+	ActionImpersonateStop  = "impersonate-stop"
+	ActionImport           = "import"
+	ActionReveal           = "reveal"
 )
@@ -1,15 +1,26 @@
 package constant
 
 const (
-	ComponentLeave        = "leave"
-	ComponentPayroll      = "payroll"
-	ComponentEmployee     = "employee"
-	ComponentHoliday      = "holiday"
-	ComponentLeaveType    = "leave-type"
-	ComponentDesignation  = "designation"
-	ComponentLeaveBalance = "leave-balance"
-	CompanySettings       = "company-setting"
-	EquipmentCategory     = "equipment-Category"
-	Equipment             = "equipment"
-	EquipmentAssign       = "equipment-assign"
+	ComponentLeave         = "leave"
+	ComponentPayroll       = "payroll"
+	ComponentEmployee      = "employee"
+	ComponentHoliday       = "holiday"
+	ComponentLeaveType     = "leave-type"
+	ComponentDesignation   = "designation"
+	ComponentLeaveBalance  = "leave-balance"
+	CompanySettings        = "company-setting"
+	EquipmentCategory      = "equipment-Category"
+	Equipment              = "equipment"
+	EquipmentAssign        = "equipment-assign"
+	ComponentAuth          = "auth"
+	ComponentPermission    = "permission"
+	ComponentAPIKey        = "api-key"
+	ComponentImpersonation = "impersonation"
+	ComponentProfile       = "profile"
+	ComponentDocument      = "document"
+	ComponentOffboarding   = "offboarding"
+	ComponentOnboarding    = "onboarding"
+	ComponentSalary        = "salary-revision"
+	ComponentDepartment    = "department"
+	ComponentEmployment    = "employment"
 )
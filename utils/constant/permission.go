@@ -0,0 +1,65 @@
+package constant
+
+// Permission codes stored in tbl_permission and granted to roles through
+// tbl_role_permission. Routes require them via middleware.RequirePermission.
+const (
+	PermEmployeeViewAll          = "employee.view_all"
+	PermEmployeeViewTeam         = "employee.view_team"
+	PermEmployeeCreate           = "employee.create"
+	PermEmployeeUpdate           = "employee.update"
+	PermEmployeePassword         = "employee.password"
+	PermEmployeeRole             = "employee.role"
+	PermEmployeeManager          = "employee.manager"
+	PermEmployeeDesignation      = "employee.designation"
+	PermEmployeeStatus           = "employee.status"
+	PermEmployeeUnlock           = "employee.unlock"
+	PermEmployeeMFAReset         = "employee.mfa_reset"
+	PermEmployeeSessionRevoke    = "employee.session_revoke"
+	PermEmployeeImpersonate      = "employee.impersonate"
+	PermEmployeeManageSuperadmin = "employee.manage_superadmin"
+
+	PermProfileViewAll   = "profile.view_all"
+	PermProfileUpdate    = "profile.update"
+	PermProfileSensitive = "profile.sensitive"
+
+	PermDocumentViewAll = "document.view_all"
+	PermDocumentManage  = "document.manage"
+
+	PermOffboardingManage = "offboarding.manage"
+	PermOnboardingManage  = "onboarding.manage"
+	PermEmploymentManage  = "employment.manage"
+
+	PermLeaveViewAll       = "leave.view_all"
+	PermLeaveViewTeam      = "leave.view_team"
+	PermLeaveApprove       = "leave.approve"
+	PermLeaveApproveTeam   = "leave.approve_team"
+	PermLeaveWithdraw      = "leave.withdraw"
+	PermLeaveWithdrawTeam  = "leave.withdraw_team"
+	PermLeaveCancelAny     = "leave.cancel_any"
+	PermLeavePolicyCreate  = "leave.policy.create"
+	PermLeavePolicyUpdate  = "leave.policy.update"
+	PermLeavePolicyDelete  = "leave.policy.delete"
+	PermLeaveTimingManage  = "leave.timing.manage"
+	PermLeaveBalanceView   = "leave_balance.view_all"
+	PermLeaveBalanceAdjust = "leave_balance.adjust"
+
+	PermPayrollRun      = "payroll.run"
+	PermPayrollFinalize = "payroll.finalize"
+	PermPayrollViewAll  = "payroll.view_all"
+	PermSalaryRevise    = "salary.revise"
+
+	PermSettingsView      = "settings.view"
+	PermSettingsUpdate    = "settings.update"
+	PermHolidayManage     = "holiday.manage"
+	PermDesignationManage = "designation.manage"
+	PermDepartmentManage  = "department.manage"
+	PermLogView           = "log.view"
+
+	PermEquipmentCategoryManage = "equipment.category.manage"
+	PermEquipmentView           = "equipment.view"
+	PermEquipmentManage         = "equipment.manage"
+	PermEquipmentAssign         = "equipment.assign"
+
+	PermPermissionManage = "permission.manage"
+	PermAPIKeyManage     = "apikey.manage"
+)
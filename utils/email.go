@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// EmailDomain returns the lower-cased domain of an email address
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(email[at+1:]))
+}
+
+// NormalizeDomain lower-cases a domain and strips a leading "@", returning
+// "" when the result is not a valid domain name
+func NormalizeDomain(domain string) string {
+	domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+	if !domainPattern.MatchString(domain) {
+		return ""
+	}
+	return domain
+}
+
+// IsValidEmail reports whether email is a bare address like "jane@example.com"
+func IsValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email && NormalizeDomain(EmailDomain(email)) != ""
+}
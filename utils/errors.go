@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,3 +28,14 @@ func CustomErr(c *gin.Context, code int, message string) error {
 	return &AppError{Code: code, Message: message}
 
 }
+
+// RespondWithTxError answers with the status and message of an AppError
+// returned from a transaction (see CustomErr), or 500 for any other error
+func RespondWithTxError(c *gin.Context, err error) {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		RespondWithError(c, appErr.Code, appErr.Message)
+		return
+	}
+	RespondWithError(c, 500, err.Error())
+}
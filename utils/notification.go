@@ -3,6 +3,7 @@ package utils
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -427,6 +428,27 @@ Zenithive HR Team
 	return SendEmail(employeeEmail, subject, body)
 }
 
+// SendPasswordResetEmail sends a single-use password reset link to the employee
+func SendPasswordResetEmail(employeeEmail, employeeName, resetLink string, validFor time.Duration) error {
+	subject := "Reset Your Password"
+	body := fmt.Sprintf(`
+Dear %s,
+
+We received a request to reset the password for your account.
+
+Use the link below to choose a new password. The link can be used once and expires in %d minutes.
+
+Reset Link: [%s]
+
+If you did not request a password reset, you can safely ignore this email. Your password will not change.
+
+Best regards,
+Zenithive HR Team
+`, employeeName, int(validFor.Minutes()), resetLink)
+
+	return SendEmail(employeeEmail, subject, body)
+}
+
 // SendLeaveCancellationEmail sends notification when leave is cancelled
 func SendLeaveCancellationEmail(employeeEmail, employeeName, leaveType, startDate, endDate string, days float64) error {
 	subject := "Leave Request Cancelled"
@@ -592,3 +614,49 @@ Zenithive Payroll Management System
 
 	return SendEmail(employeeEmail, subject, body)
 }
+
+// SendProbationReminderEmail reminds the manager and admins that an
+// employee's probation ends soon and needs a decision
+func SendProbationReminderEmail(recipients []string, employeeName, employmentType, endDate string, daysLeft int) error {
+	subject := fmt.Sprintf("Probation Ending - %s", employeeName)
+
+	when := fmt.Sprintf("ends in %d days", daysLeft)
+	switch {
+	case daysLeft == 0:
+		when = "ends today"
+	case daysLeft < 0:
+		when = fmt.Sprintf("ended %d days ago", -daysLeft)
+	}
+
+	body := fmt.Sprintf(`
+Dear Manager/Admin,
+
+The probation of the following employee %s and needs a decision.
+
+Employee: %s
+Employment Type: %s
+Probation End Date: %s
+
+Please login to the system to confirm the employee, extend the probation or end the employment.
+
+Best regards,
+Zenithive HR Team
+`, when, employeeName, employmentType, endDate)
+
+	// The reminder counts as delivered when anyone received it
+	var errs []error
+	for _, recipient := range recipients {
+		if err := SendEmail(recipient, subject, body); err != nil {
+			// Log error but continue sending to other recipients
+			fmt.Printf("Failed to send email to %s: %v\n", recipient, err)
+			errs = append(errs, fmt.Errorf("%s: %w", recipient, err))
+		}
+	}
+	if len(recipients) == 0 {
+		return errors.New("no recipients for the probation reminder")
+	}
+	if len(errs) == len(recipients) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
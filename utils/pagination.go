@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Cursor marks the last row of a page for keyset pagination. It is handed
+// to clients as an opaque string.
+type Cursor struct {
+	Sort  string    `json:"s"` // the sort the cursor was created for
+	Value string    `json:"v"` // sort value of the last row
+	ID    uuid.UUID `json:"id"`
+}
+
+// EncodeCursor returns the opaque form of c
+func EncodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor returned by EncodeCursor
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(raw, &c) != nil || c.ID == uuid.Nil {
+		return c, errors.New("invalid cursor")
+	}
+	return c, nil
+}
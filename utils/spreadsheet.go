@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ReadSpreadsheet reads all rows of a .csv file or of the first sheet of an
+// .xlsx file. The format is picked from the file name.
+func ReadSpreadsheet(filename string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1 // trailing empty cells are often left out
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		// Excel saves CSV files with a UTF-8 byte order mark
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+		}
+		return rows, nil
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XLSX: %w", err)
+		}
+		defer f.Close()
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("XLSX file has no sheets")
+		}
+		return f.GetRows(sheets[0])
+	default:
+		return nil, errors.New("file must be .csv or .xlsx")
+	}
+}
+
+// SpreadsheetWriter writes rows one at a time so large exports are streamed
+type SpreadsheetWriter interface {
+	WriteRow(values ...any) error
+	// Close flushes everything to the underlying writer
+	Close() error
+}
+
+// SpreadsheetContentType returns the MIME type of an export format
+func SpreadsheetContentType(format string) string {
+	if format == "xlsx" {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewSpreadsheetWriter writes "csv" or "xlsx" to w
+func NewSpreadsheetWriter(w io.Writer, format, sheet string) (SpreadsheetWriter, error) {
+	switch format {
+	case "csv":
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case "xlsx":
+		f := excelize.NewFile()
+		if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+			return nil, err
+		}
+		sw, err := f.NewStreamWriter(sheet)
+		if err != nil {
+			return nil, err
+		}
+		return &xlsxWriter{out: w, file: f, stream: sw}, nil
+	default:
+		return nil, errors.New("format must be csv or xlsx")
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatCell(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func (x *xlsxWriter) WriteRow(values ...any) error {
+	x.row++
+	cells := make([]any, len(values))
+	for i, v := range values {
+		switch v := cellValue(v).(type) {
+		case float64, int, nil:
+			cells[i] = v // keep numbers numeric
+		default:
+			cells[i] = formatCell(v)
+		}
+	}
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.out)
+}
+
+// cellValue dereferences optional values, nil pointers become empty cells
+func cellValue(v any) any {
+	switch v := v.(type) {
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case *float64:
+		if v != nil {
+			return *v
+		}
+	case *time.Time:
+		if v != nil {
+			return *v
+		}
+	default:
+		return v
+	}
+	return nil
+}
+
+// formatCell turns a value into cell text. Dates are written as YYYY-MM-DD
+// and text starting with a formula character is escaped so spreadsheet
+// programs do not evaluate it.
+func formatCell(v any) string {
+	switch v := cellValue(v).(type) {
+	case nil:
+		return ""
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format("2006-01-02")
+	default:
+		return fmt.Sprint(v)
+	}
+}